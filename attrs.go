@@ -0,0 +1,94 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// AssertHasAttr reports an error to t unless path selects elements of the
+// normalized document doc and each has the attribute name, using a
+// Normalizer configured by opts. Path is as described for ExpectText, and
+// name is in Clark notation.
+func AssertHasAttr(t *testing.T, doc, path, name string, opts ...Option) {
+	t.Helper()
+	AssertHasAttrs(t, doc, path, []string{name}, opts...)
+}
+
+// AssertNoAttr reports an error to t if an element of the normalized
+// document doc that path selects has the attribute name. It is otherwise
+// like AssertHasAttr.
+func AssertNoAttr(t *testing.T, doc, path, name string, opts ...Option) {
+	t.Helper()
+	AssertNoAttrs(t, doc, path, []string{name}, opts...)
+}
+
+// AssertHasAttrs is like AssertHasAttr for each of names.
+func AssertHasAttrs(t *testing.T, doc, path string, names []string, opts ...Option) {
+	t.Helper()
+	for _, err := range New(opts...).attrErrors(doc, path, names, true) {
+		t.Error(err)
+	}
+}
+
+// AssertNoAttrs is like AssertNoAttr for each of names.
+func AssertNoAttrs(t *testing.T, doc, path string, names []string, opts ...Option) {
+	t.Helper()
+	for _, err := range New(opts...).attrErrors(doc, path, names, false) {
+		t.Error(err)
+	}
+}
+
+// attrErrors returns the errors of elements of doc selected by path that
+// lack one of names if want is set, or have one of them otherwise.
+func (n *Normalizer) attrErrors(doc, path string, names []string, want bool) []error {
+	p, err := parsePath(path)
+	if err != nil {
+		return []error{err}
+	}
+	if p.hasAttr {
+		return []error{fmt.Errorf("xmltest: path %s selects an attribute", path)}
+	}
+	toks, err := n.readTokens(strings.NewReader(doc))
+	if err != nil {
+		return []error{err}
+	}
+	var (
+		errs     []error
+		elems    []xml.Name
+		selected int
+	)
+	for _, t := range toks {
+		switch t := t.(type) {
+		case xml.StartElement:
+			elems = append(elems, t.Name)
+			if !p.matchElement(elems) {
+				continue
+			}
+			selected++
+			for _, name := range names {
+				m, has := []xml.Name{parseName(name)}, false
+				for _, a := range t.Attr {
+					has = has || matchName(m, a.Name)
+				}
+				switch {
+				case want && !has:
+					errs = append(errs, fmt.Errorf("xmltest: %s: missing attribute %s", formatPath(elems), name))
+				case !want && has:
+					errs = append(errs, fmt.Errorf("xmltest: %s: unexpected attribute %s", formatPath(elems), name))
+				}
+			}
+		case xml.EndElement:
+			elems = elems[:len(elems)-1]
+		}
+	}
+	if want && selected == 0 {
+		errs = append(errs, fmt.Errorf("xmltest: path %s selects no element", path))
+	}
+	return errs
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestAttrErrors(t *testing.T) {
+	const doc = `<r xmlns:p="urn:p"><item id="1" p:flag="x"/><item id="2"/></r>`
+	testCases := []struct {
+		desc     string
+		n        Normalizer
+		path     string
+		names    []string
+		want     bool
+		wantErrs []string
+	}{{
+		desc:  "has",
+		path:  "item",
+		names: []string{"id"},
+		want:  true,
+	}, {
+		desc:     "missing on some elements",
+		path:     "/r/item",
+		names:    []string{"id", "{urn:p}flag"},
+		want:     true,
+		wantErrs: []string{"xmltest: /r/item: missing attribute {urn:p}flag"},
+	}, {
+		desc:     "no element selected",
+		path:     "entry",
+		names:    []string{"id"},
+		want:     true,
+		wantErrs: []string{"xmltest: path entry selects no element"},
+	}, {
+		desc:     "unexpected",
+		path:     "item",
+		names:    []string{"flag", "other"},
+		wantErrs: []string{"xmltest: /r/item: unexpected attribute flag"},
+	}, {
+		desc:  "ignored attributes are absent",
+		n:     Normalizer{IgnoreAttrs: []xml.Name{{Local: "flag"}}},
+		path:  "item",
+		names: []string{"flag"},
+	}, {
+		desc:     "attribute path",
+		path:     "item/@id",
+		names:    []string{"id"},
+		wantErrs: []string{"xmltest: path item/@id selects an attribute"},
+	}}
+
+	for _, tc := range testCases {
+		var got []string
+		for _, err := range tc.n.attrErrors(doc, tc.path, tc.names, tc.want) {
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tc.wantErrs, "\n") {
+			t.Errorf("%s: got errors %q, want %q", tc.desc, got, tc.wantErrs)
+		}
+	}
+}
+
+func TestAssertAttrs(t *testing.T) {
+	const doc = "<r>\n  <a enabled=\"true\"/>\n</r>"
+	AssertHasAttr(t, doc, "a", "enabled")
+	AssertNoAttr(t, doc, "a", "disabled")
+	AssertHasAttrs(t, doc, "/r/a", []string{"enabled"}, OmitWhitespace())
+	AssertNoAttrs(t, doc, "r", []string{"enabled", "disabled"})
+}
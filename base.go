@@ -0,0 +1,52 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"net/url"
+)
+
+// isBaseAttr reports whether name is xml:base. The prefix of token
+// streams that do not resolve namespaces is accepted, too.
+func isBaseAttr(name xml.Name) bool {
+	return name.Local == "base" && (name.Space == xmlURL || name.Space == "xml")
+}
+
+// pushBase pushes the base URI in scope of the input element start.
+func (tn *TokenNormalizer) pushBase(start xml.StartElement) {
+	base := tn.base()
+	for _, a := range start.Attr {
+		if isBaseAttr(a.Name) {
+			base = resolveURI(base, a.Value)
+		}
+	}
+	tn.bases = append(tn.bases, base)
+}
+
+// base returns the base URI in scope, or "" if there is none.
+func (tn *TokenNormalizer) base() string {
+	if len(tn.bases) == 0 {
+		return ""
+	}
+	return tn.bases[len(tn.bases)-1]
+}
+
+// resolveURI resolves the URI reference ref against base. It returns ref
+// if either is not a valid URI reference.
+func resolveURI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
@@ -0,0 +1,37 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// canonicalBase64 returns the canonical form of s if it is padded base64
+// other than whitespace: its encoding without whitespace, or the digest of
+// its bytes if n.Base64Digest is set.
+func (n *Normalizer) canonicalBase64(s string) (string, bool) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "", false
+	}
+	b, err := base64.StdEncoding.Strict().DecodeString(s)
+	if err != nil {
+		return "", false
+	}
+	if n.Base64Digest {
+		sum := sha256.Sum256(b)
+		return "sha256:" + hex.EncodeToString(sum[:]), true
+	}
+	return base64.StdEncoding.EncodeToString(b), true
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalBase64(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		n      Normalizer
+		in     string
+		want   string
+		wantOK bool
+	}{{
+		desc:   "wrapped",
+		in:     "aGVs\n  bG8g\r\nd29y bGQ=\n",
+		want:   "aGVsbG8gd29ybGQ=",
+		wantOK: true,
+	}, {
+		desc: "unpadded",
+		in:   "aGVsbG8gd29ybGQ",
+	}, {
+		desc: "incomplete",
+		in:   "abc",
+	}, {
+		desc: "nonzero padding bits",
+		in:   "aGl=",
+	}, {
+		desc: "empty",
+	}, {
+		desc: "whitespace",
+		in:   " \n\t",
+	}, {
+		desc:   "digest",
+		n:      Normalizer{Base64Digest: true},
+		in:     "aGVsbG8gd29ybGQ=",
+		want:   "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		wantOK: true,
+	}, {
+		desc: "invalid",
+		in:   "not base64!",
+	}}
+	for _, tc := range testCases {
+		got, ok := tc.n.canonicalBase64(tc.in)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("%s: got %q, %v, want %q, %v", tc.desc, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestEqualXMLBase64(t *testing.T) {
+	a := `<msg><att type="png">iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg==</att></msg>`
+	b := "<msg><att type=\"png\">\n  iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJ\n  AAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg==\n</att></msg>"
+	for _, opts := range [][]Option{{Base64("att")}, {Base64("att"), Base64Digest()}} {
+		if equal, err := New(opts...).EqualXML(strings.NewReader(a), strings.NewReader(b)); err != nil || !equal {
+			t.Errorf("got %v, %v, want true, nil", equal, err)
+		}
+	}
+	if equal, _ := New().EqualXML(strings.NewReader(a), strings.NewReader(b)); equal {
+		t.Errorf("got equal without Base64Paths, want unequal")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestResolveURIAttrs(t *testing.T) {
+	n := Normalizer{ResolveURIAttrs: []xml.Name{{Local: "href"}, {Local: "src"}}}
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "no base",
+		in:   `<a href="x.html"/>`,
+		want: `<a href="x.html"></a>`,
+	}, {
+		desc: "base on the same element",
+		in:   `<a xml:base="http://h/d/" href="x.html"/>`,
+		want: `<a href="http://h/d/x.html"></a>`,
+	}, {
+		desc: "inherited and nested relative base",
+		in:   `<r xml:base="http://h/d/"><s xml:base="sub/"><a src="../y.png"/></s><a href="/z"/></r>`,
+		want: `<r><s><a src="http://h/d/y.png"></a></s><a href="http://h/z"></a></r>`,
+	}, {
+		desc: "absolute reference",
+		in:   `<r xml:base="http://h/"><a href="https://o/p"/></r>`,
+		want: `<r><a href="https://o/p"></a></r>`,
+	}, {
+		desc: "base out of scope",
+		in:   `<r><s xml:base="http://h/"/><a href="x"/></r>`,
+		want: `<r><s></s><a href="x"></a></r>`,
+	}, {
+		desc: "other attributes are kept",
+		in:   `<r xml:base="http://h/"><a title="x"/></r>`,
+		want: `<r><a title="x"></a></r>`,
+	}, {
+		desc: "invalid reference",
+		in:   `<r xml:base="http://h/"><a href="%zz"/></r>`,
+		want: `<r><a href="%zz"></a></r>`,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		if err := n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.want)
+		}
+	}
+
+	a := `<r xml:base="http://h/d/"><a href="x.html"/></r>`
+	b := `<r><a href="http://h/d/x.html"/></r>`
+	if equal, err := n.EqualXML(strings.NewReader(a), strings.NewReader(b)); err != nil || !equal {
+		t.Errorf("EqualXML: got %v, %v, want true, nil", equal, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// BenchmarkDoc is a named document of BenchmarkCorpus.
+type BenchmarkDoc struct {
+	Name string
+	Data []byte
+}
+
+// BenchmarkCorpus returns large documents that represent common shapes of
+// XML content:
+//
+//   - flat: many small sibling elements,
+//   - deep: deeply nested elements,
+//   - attrs: elements with many unsorted attributes,
+//   - namespaces: elements and attributes in several namespaces,
+//   - text: long mixed content with character references, and
+//   - random: a document of the Generator.
+//
+// The documents are the same on each call.
+func BenchmarkCorpus() []BenchmarkDoc {
+	var flat, deep, attrs, ns, text strings.Builder
+
+	flat.WriteString("<items>\n")
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&flat, "  <item id=\"%d\" name=\"item %d\">value %d</item>\n", i, i, i)
+	}
+	flat.WriteString("</items>\n")
+
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&deep, "<level n=\"%d\">", i)
+	}
+	deep.WriteString("bottom")
+	for i := 0; i < 1000; i++ {
+		deep.WriteString("</level>")
+	}
+
+	attrs.WriteString("<rows>")
+	for i := 0; i < 2000; i++ {
+		attrs.WriteString("<row")
+		for j := 20; j > 0; j-- {
+			fmt.Fprintf(&attrs, " a%02d=\"%d\"", j, i*j)
+		}
+		attrs.WriteString("/>")
+	}
+	attrs.WriteString("</rows>")
+
+	ns.WriteString(`<r:root xmlns:r="urn:root"`)
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&ns, ` xmlns:n%d="urn:ns:%d"`, i, i)
+	}
+	ns.WriteString(">")
+	for i := 0; i < 5000; i++ {
+		p := i % 5
+		fmt.Fprintf(&ns, `<n%d:e n%d:a="%d" xmlns:x="urn:x%d"><x:v>%d</x:v></n%d:e>`, p, (p+1)%5, i, i%3, i, p)
+	}
+	ns.WriteString("</r:root>")
+
+	text.WriteString("<doc>")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&text, "<p>Paragraph %d with &amp; and &lt;tags&gt; and &#x263A; in <b>bold</b> and <i>italic</i> text, ", i)
+		text.WriteString(strings.Repeat("lorem ipsum dolor sit amet ", 8))
+		text.WriteString("<![CDATA[raw <data>]]></p>\n")
+	}
+	text.WriteString("</doc>")
+
+	g := Generator{Seed: 1, MaxDepth: 7, MaxChildren: 7, MaxAttrs: 5, Namespaces: []string{"urn:a", "urn:b"}}
+	return []BenchmarkDoc{
+		{"flat", []byte(flat.String())},
+		{"deep", []byte(deep.String())},
+		{"attrs", []byte(attrs.String())},
+		{"namespaces", []byte(ns.String())},
+		{"text", []byte(text.String())},
+		{"random", g.Generate()},
+	}
+}
+
+// BenchmarkNormalizer benchmarks normalizing doc with n. It reports
+// allocations and the throughput in bytes of doc.
+func BenchmarkNormalizer(b *testing.B, n Normalizer, doc []byte) {
+	b.Helper()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := n.Normalize(io.Discard, bytes.NewReader(doc)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEqualXML benchmarks comparing the documents x and y with n. It
+// reports allocations and the throughput in bytes of both documents.
+func BenchmarkEqualXML(b *testing.B, n Normalizer, x, y []byte) {
+	b.Helper()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(x) + len(y)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := n.EqualXML(bytes.NewReader(x), bytes.NewReader(y)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
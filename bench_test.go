@@ -0,0 +1,46 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBenchmarkCorpus(t *testing.T) {
+	a, b := BenchmarkCorpus(), BenchmarkCorpus()
+	for i, doc := range a {
+		if err := checkWellFormed(doc.Data); err != nil {
+			t.Errorf("%s: %v", doc.Name, err)
+		}
+		if !bytes.Equal(doc.Data, b[i].Data) {
+			t.Errorf("%s: not the same on each call", doc.Name)
+		}
+	}
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	for _, doc := range BenchmarkCorpus() {
+		b.Run(doc.Name, func(b *testing.B) {
+			BenchmarkNormalizer(b, Normalizer{}, doc.Data)
+		})
+	}
+}
+
+func BenchmarkNormalizeSorted(b *testing.B) {
+	for _, doc := range BenchmarkCorpus() {
+		b.Run(doc.Name, func(b *testing.B) {
+			BenchmarkNormalizer(b, Normalizer{OmitWhitespace: true, SortElements: true}, doc.Data)
+		})
+	}
+}
+
+func BenchmarkEqual(b *testing.B) {
+	for _, doc := range BenchmarkCorpus() {
+		b.Run(doc.Name, func(b *testing.B) {
+			BenchmarkEqualXML(b, Normalizer{}, doc.Data, doc.Data)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"sync"
+)
+
+// Cache stores the canonical hashes of documents, as returned by Hash,
+// keyed by the SHA-256 digest of their raw content. A Cache allows
+// EqualXML to skip normalizing documents it has seen before.
+//
+// The canonical hash depends on the options of the Normalizer, so a Cache
+// must only be shared between Normalizers with the same options.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the canonical hash stored for the raw digest key.
+	Get(key [32]byte) (sum [32]byte, ok bool)
+	// Put stores the canonical hash sum for the raw digest key.
+	Put(key, sum [32]byte)
+}
+
+// NewMemoryCache returns a Cache that stores hashes in memory.
+func NewMemoryCache() Cache {
+	return &memoryCache{m: map[[32]byte][32]byte{}}
+}
+
+type memoryCache struct {
+	mu sync.Mutex
+	m  map[[32]byte][32]byte
+}
+
+func (c *memoryCache) Get(key [32]byte) ([32]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sum, ok := c.m[key]
+	return sum, ok
+}
+
+func (c *memoryCache) Put(key, sum [32]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = sum
+}
+
+// equalCached implements EqualXML for Normalizers with a Cache.
+func (n *Normalizer) equalCached(a, b io.Reader) (bool, error) {
+	bufa, bufb := getBuffer(), getBuffer()
+	defer putBuffer(bufa)
+	defer putBuffer(bufb)
+	if _, err := bufa.ReadFrom(a); err != nil {
+		return false, err
+	}
+	if _, err := bufb.ReadFrom(b); err != nil {
+		return false, err
+	}
+	ba, bb := bufa.Bytes(), bufb.Bytes()
+	ha, err := n.cachedHash(ba)
+	if err != nil {
+		return false, err
+	}
+	hb, err := n.cachedHash(bb)
+	if err != nil {
+		return false, err
+	}
+	if ha == hb {
+		return true, nil
+	}
+	if !n.approximate() {
+		return false, nil
+	}
+	// Documents with different hashes may still be equal within the
+	// configured tolerances.
+	return n.equalUncached(bytes.NewReader(ba), bytes.NewReader(bb))
+}
+
+// cachedHash returns the canonical hash of the raw document b.
+func (n *Normalizer) cachedHash(b []byte) ([32]byte, error) {
+	key := sha256.Sum256(b)
+	if sum, ok := n.Cache.Get(key); ok {
+		return sum, nil
+	}
+	// EqualXML ignores XML declarations.
+	m := *n
+	m.XMLDeclaration = false
+	sum, err := m.Hash(bytes.NewReader(b))
+	if err != nil {
+		return sum, err
+	}
+	n.Cache.Put(key, sum)
+	return sum, nil
+}
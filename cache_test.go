@@ -0,0 +1,89 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+type countingCache struct {
+	Cache
+	hits, misses int
+}
+
+func (c *countingCache) Get(key [32]byte) ([32]byte, bool) {
+	sum, ok := c.Cache.Get(key)
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return sum, ok
+}
+
+func TestEqualXMLCache(t *testing.T) {
+	c := &countingCache{Cache: NewMemoryCache()}
+	n := Normalizer{Cache: c}
+	testCases := []struct {
+		desc       string
+		a, b       string
+		wantEqual  bool
+		wantHits   int
+		wantMisses int
+	}{
+		{"first comparison", `<root a="1" b="2"/>`, `<root b="2" a="1"/>`, true, 0, 2},
+		{"repeated comparison", `<root a="1" b="2"/>`, `<root b="2" a="1"/>`, true, 2, 2},
+		{"new document", `<root a="1" b="2"/>`, `<root/>`, false, 3, 3},
+	}
+	for _, tc := range testCases {
+		got, err := n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Fatalf("%s: got err %v, want nil", tc.desc, err)
+		}
+		if got != tc.wantEqual {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.wantEqual)
+		}
+		if c.hits != tc.wantHits || c.misses != tc.wantMisses {
+			t.Errorf("%s: got %d hits, %d misses, want %d, %d", tc.desc, c.hits, c.misses, tc.wantHits, tc.wantMisses)
+		}
+	}
+
+	// Documents with different hashes are still compared within tolerance.
+	n = Normalizer{Cache: NewMemoryCache(), NumberTolerance: &Tolerance{}}
+	got, err := n.EqualXML(strings.NewReader(`<x>1.0</x>`), strings.NewReader(`<x>1</x>`))
+	if err != nil || !got {
+		t.Errorf("tolerance: got %v, %v, want true, nil", got, err)
+	}
+}
+
+func TestEqualXMLCacheAgrees(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		a, b string
+	}{{
+		desc: "XML declarations",
+		n:    Normalizer{XMLDeclaration: true},
+		a:    `<?xml version="1.0" standalone="yes"?><a/>`,
+		b:    `<a/>`,
+	}, {
+		desc: "different documents",
+		n:    Normalizer{XMLDeclaration: true},
+		a:    `<?xml version="1.0"?><a/>`,
+		b:    `<b/>`,
+	}}
+	for _, tc := range testCases {
+		want, err := tc.n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.desc, err)
+		}
+		n := tc.n
+		n.Cache = NewMemoryCache()
+		if got, err := n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b)); err != nil || got != want {
+			t.Errorf("%s: got %v, %v with cache, want %v, nil as without", tc.desc, got, err, want)
+		}
+	}
+}
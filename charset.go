@@ -0,0 +1,142 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var errOddUTF16 = errors.New("xmltest: UTF-16 input ends in the middle of a character")
+
+// newTranscodingDecoder returns a decoder of the document read from br. It
+// strips a UTF-8 byte order mark, and transcodes UTF-16 input to UTF-8.
+// UTF-16 is recognized by its byte order mark, or else by the NUL byte
+// next to the leading '<'.
+func newTranscodingDecoder(br *bufio.Reader) *xml.Decoder {
+	r, isUTF16 := transcode(br)
+	d := xml.NewDecoder(r)
+	if isUTF16 {
+		d.CharsetReader = utf16Charset
+	}
+	return d
+}
+
+// transcode returns a reader of the UTF-8 content of the document read
+// from br, as newTranscodingDecoder, and whether the document is UTF-16.
+func transcode(br *bufio.Reader) (io.Reader, bool) {
+	head, _ := br.Peek(3)
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(head, []byte("\xef\xbb\xbf")):
+		br.Discard(3)
+		return br, false
+	case bytes.HasPrefix(head, []byte("\xfe\xff")):
+		br.Discard(2)
+		bigEndian = true
+	case bytes.HasPrefix(head, []byte("\xff\xfe")):
+		br.Discard(2)
+	case bytes.HasPrefix(head, []byte("\x00<")):
+		bigEndian = true
+	case bytes.HasPrefix(head, []byte("<\x00")):
+	default:
+		return br, false
+	}
+	return &utf16Reader{r: br, bigEndian: bigEndian}, true
+}
+
+// utf16Charset is the CharsetReader of decoders of transcoded UTF-16
+// input, which is already transcoded when the declaration is read.
+func utf16Charset(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "utf-16", "utf-16le", "utf-16be":
+		return input, nil
+	}
+	return nil, fmt.Errorf("xmltest: UTF-16 input declares encoding %q", label)
+}
+
+// utf16Reader transcodes UTF-16 to UTF-8. Invalid surrogates are replaced
+// by U+FFFD.
+type utf16Reader struct {
+	r         io.Reader
+	bigEndian bool
+	// next holds a code unit that was read ahead, if hasNext.
+	next    uint16
+	hasNext bool
+	// pending holds encoded bytes that did not fit into the last Read.
+	pending []byte
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	n := copy(p, u.pending)
+	u.pending = u.pending[n:]
+	for n < len(p) {
+		r, err := u.readRune()
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		if len(p)-n >= utf8.UTFMax {
+			n += utf8.EncodeRune(p[n:], r)
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		m := utf8.EncodeRune(buf[:], r)
+		k := copy(p[n:], buf[:m])
+		n += k
+		u.pending = append(u.pending[:0], buf[k:m]...)
+	}
+	return n, nil
+}
+
+func (u *utf16Reader) readRune() (rune, error) {
+	c, err := u.readUnit()
+	if err != nil {
+		return 0, err
+	}
+	if !utf16.IsSurrogate(rune(c)) {
+		return rune(c), nil
+	}
+	c2, err := u.readUnit()
+	if err == io.EOF {
+		return utf8.RuneError, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if r := utf16.DecodeRune(rune(c), rune(c2)); r != utf8.RuneError {
+		return r, nil
+	}
+	// Read c2 again as it might start a valid pair.
+	u.next, u.hasNext = c2, true
+	return utf8.RuneError, nil
+}
+
+func (u *utf16Reader) readUnit() (uint16, error) {
+	if u.hasNext {
+		u.hasNext = false
+		return u.next, nil
+	}
+	var b [2]byte
+	if _, err := io.ReadFull(u.r, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errOddUTF16
+		}
+		return 0, err
+	}
+	if u.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1]), nil
+	}
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
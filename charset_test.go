@@ -0,0 +1,119 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// encodeUTF16 returns s encoded in UTF-16 of the given byte order.
+func encodeUTF16(s string, bigEndian bool) string {
+	var b bytes.Buffer
+	for _, c := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b.WriteByte(byte(c >> 8))
+			b.WriteByte(byte(c))
+		} else {
+			b.WriteByte(byte(c))
+			b.WriteByte(byte(c >> 8))
+		}
+	}
+	return b.String()
+}
+
+func TestCharset(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		n       Normalizer
+		in      string
+		want    string
+		wantErr string
+	}{{
+		desc: "UTF-8 byte order mark",
+		in:   "\xef\xbb\xbf<a>x</a>",
+		want: `<a>x</a>`,
+	}, {
+		desc: "UTF-8 byte order mark of fragment",
+		n:    Normalizer{Fragment: true},
+		in:   "\xef\xbb\xbftext<a/>",
+		want: `text<a></a>`,
+	}, {
+		desc: "UTF-16LE with byte order mark",
+		in:   encodeUTF16("\ufeff<a b=\"ä\">€</a>", false),
+		want: `<a b="ä">€</a>`,
+	}, {
+		desc: "UTF-16BE with byte order mark",
+		in:   encodeUTF16("\ufeff<a b=\"ä\">€</a>", true),
+		want: `<a b="ä">€</a>`,
+	}, {
+		desc: "UTF-16LE without byte order mark",
+		in:   encodeUTF16(`<?xml version="1.0" encoding="UTF-16"?><a/>`, false),
+		want: `<a></a>`,
+	}, {
+		desc: "UTF-16BE without byte order mark",
+		in:   encodeUTF16(`<?xml version="1.0" encoding="utf-16"?><a/>`, true),
+		want: `<a></a>`,
+	}, {
+		desc: "surrogate pair",
+		in:   encodeUTF16("\ufeff<a>\U0001F600</a>", false),
+		want: "<a>\U0001F600</a>",
+	}, {
+		desc: "unpaired surrogate",
+		in:   "\xff\xfe<\x00a\x00>\x00\x00\xd8<\x00/\x00a\x00>\x00",
+		want: "<a>�</a>",
+	}, {
+		desc:    "odd length",
+		in:      encodeUTF16("\ufeff<a/>", false) + "\x00",
+		wantErr: errOddUTF16.Error(),
+	}, {
+		desc:    "other declared encoding",
+		in:      encodeUTF16(`<?xml version="1.0" encoding="ISO-8859-1"?><a/>`, false),
+		wantErr: `xmltest: UTF-16 input declares encoding "ISO-8859-1"`,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := tc.n.Normalize(&b, strings.NewReader(tc.in))
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: got err %v, want %s", tc.desc, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestUTF16ReaderShortReads(t *testing.T) {
+	want := "a€\U0001F600b"
+	u := &utf16Reader{r: strings.NewReader(encodeUTF16(want, false))}
+	got, err := io.ReadAll(iotest.OneByteReader(u))
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCharsetEqualXML(t *testing.T) {
+	var n Normalizer
+	a := encodeUTF16("\ufeff<a>ä</a>", false)
+	equal, err := n.EqualXML(strings.NewReader(a), strings.NewReader(`<a>ä</a>`))
+	if err != nil || !equal {
+		t.Errorf("got %v, %v, want true, nil", equal, err)
+	}
+}
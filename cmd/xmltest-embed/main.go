@@ -0,0 +1,237 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command xmltest-embed generates a Go source file that provides XML
+// fixtures, normalized by xmltest, as string constants. Run it with go
+// generate:
+//
+//	//go:generate go run github.com/rsto/xmltest/cmd/xmltest-embed -o fixtures.go testdata/*.xml
+//
+// Each file foo-bar.xml becomes the constant fooBarXML. Malformed fixtures
+// fail the generation, so the constants are always well-formed and
+// normalized.
+//
+// With -embed, the generated file instead embeds the files with
+// go:embed and normalizes them into variables at init time, so that
+// fixtures may change without regenerating. The files must then be in the
+// package directory or below.
+//
+// Usage:
+//
+//	xmltest-embed [flags] file...
+//
+// The flags are:
+//
+//	-o file
+//		write the output to file instead of standard output
+//	-pkg name
+//		package name of the output; defaults to $GOPACKAGE
+//	-embed
+//		embed the files and normalize them at init time
+//	-export
+//		generate exported names
+//	-omit-whitespace
+//		normalize with xmltest.OmitWhitespace
+//	-omit-comments
+//		normalize with xmltest.OmitComments
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rsto/xmltest"
+)
+
+// config is the configuration set by the flags.
+type config struct {
+	pkg            string
+	embed          bool
+	export         bool
+	omitWhitespace bool
+	omitComments   bool
+}
+
+func main() {
+	var cfg config
+	out := flag.String("o", "", "write the output to `file` instead of standard output")
+	flag.StringVar(&cfg.pkg, "pkg", os.Getenv("GOPACKAGE"), "package `name` of the output")
+	flag.BoolVar(&cfg.embed, "embed", false, "embed the files and normalize them at init time")
+	flag.BoolVar(&cfg.export, "export", false, "generate exported names")
+	flag.BoolVar(&cfg.omitWhitespace, "omit-whitespace", false, "normalize with xmltest.OmitWhitespace")
+	flag.BoolVar(&cfg.omitComments, "omit-comments", false, "normalize with xmltest.OmitComments")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: xmltest-embed [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || cfg.pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	files, err := expand(flag.Args())
+	if err == nil {
+		var src []byte
+		if src, err = generate(cfg, files); err == nil {
+			if *out == "" {
+				_, err = os.Stdout.Write(src)
+			} else {
+				err = os.WriteFile(*out, src, 0o666)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "xmltest-embed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// expand expands the glob patterns in args, since go generate does not.
+func expand(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// options returns the xmltest options of cfg, and their source code.
+func (cfg config) options() ([]xmltest.Option, []string) {
+	var (
+		opts []xmltest.Option
+		srcs []string
+	)
+	if cfg.omitWhitespace {
+		opts, srcs = append(opts, xmltest.OmitWhitespace()), append(srcs, "xmltest.OmitWhitespace()")
+	}
+	if cfg.omitComments {
+		opts, srcs = append(opts, xmltest.OmitComments()), append(srcs, "xmltest.OmitComments()")
+	}
+	return opts, srcs
+}
+
+// generate returns the formatted source code for files. Unless embedding,
+// it normalizes the files and fails for malformed ones.
+func generate(cfg config, files []string) ([]byte, error) {
+	names := map[string]string{}
+	idents := make([]string, len(files))
+	for i, f := range files {
+		id := identifier(f, cfg.export)
+		if prev, dup := names[id]; dup {
+			return nil, fmt.Errorf("%s and %s both map to %s", prev, f, id)
+		}
+		names[id], idents[i] = f, id
+	}
+	opts, optSrcs := cfg.options()
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by xmltest-embed; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", cfg.pkg)
+	if !cfg.embed {
+		b.WriteString("const (\n")
+		for i, f := range files {
+			content, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			var norm strings.Builder
+			if err := xmltest.Normalize(&norm, bytes.NewReader(content), opts...); err != nil {
+				return nil, fmt.Errorf("%s: %v", f, err)
+			}
+			fmt.Fprintf(&b, "\t// %s is the normalized content of %s.\n", idents[i], filepath.ToSlash(f))
+			fmt.Fprintf(&b, "\t%s = %s\n", idents[i], quote(norm.String()))
+		}
+		b.WriteString(")\n")
+		return format.Source(b.Bytes())
+	}
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		p := path.Clean(filepath.ToSlash(f))
+		if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("cannot embed %s outside of the package directory", f)
+		}
+		paths[i] = p
+	}
+	b.WriteString("import (\n\t\"embed\"\n\t\"strings\"\n\n\t\"github.com/rsto/xmltest\"\n)\n\n")
+	fmt.Fprintf(&b, "//go:embed %s\nvar xmltestFiles embed.FS\n\n", strings.Join(paths, " "))
+	b.WriteString("var (\n")
+	for i, p := range paths {
+		fmt.Fprintf(&b, "\t// %s is the normalized content of %s.\n", idents[i], p)
+		fmt.Fprintf(&b, "\t%s = xmltestNormalize(%q)\n", idents[i], p)
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, `// xmltestNormalize returns the normalized content of the embedded file
+// name, and panics if it is malformed.
+func xmltestNormalize(name string) string {
+	b, err := xmltestFiles.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	var sb strings.Builder
+	if err := xmltest.Normalize(&sb, strings.NewReader(string(b))%s); err != nil {
+		panic(name + ": " + err.Error())
+	}
+	return sb.String()
+}
+`, strings.Join(append([]string{""}, optSrcs...), ", "))
+	return format.Source(b.Bytes())
+}
+
+// identifier returns the name of the fixture file f, such as fooBarXML for
+// foo-bar.xml.
+func identifier(f string, export bool) string {
+	base := filepath.Base(f)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	var b strings.Builder
+	upper := false
+	for _, r := range base {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			upper = true
+			continue
+		case b.Len() == 0 && export, b.Len() > 0 && upper:
+			r = unicode.ToUpper(r)
+		case b.Len() == 0:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+		upper = false
+	}
+	id := b.String()
+	if id == "" || unicode.IsDigit([]rune(id)[0]) {
+		if export {
+			id = "X" + id
+		} else {
+			id = "x" + id
+		}
+	}
+	return id + "XML"
+}
+
+// quote returns s as a raw string literal if possible, and as an
+// interpreted one otherwise.
+func quote(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
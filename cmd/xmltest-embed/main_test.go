@@ -0,0 +1,129 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIdentifier(t *testing.T) {
+	testCases := []struct {
+		f      string
+		export bool
+		want   string
+	}{
+		{"feed.xml", false, "feedXML"},
+		{"testdata/foo-bar_baz.xml", false, "fooBarBazXML"},
+		{"Feed.xml", false, "feedXML"},
+		{"-feed.xml", false, "feedXML"},
+		{"feed.xml", true, "FeedXML"},
+		{"404.xml", false, "x404XML"},
+		{"404.xml", true, "X404XML"},
+		{".xml", false, "xXML"},
+	}
+	for _, tc := range testCases {
+		if got := identifier(tc.f, tc.export); got != tc.want {
+			t.Errorf("identifier(%q, %v): got %s, want %s", tc.f, tc.export, got, tc.want)
+		}
+	}
+}
+
+// writeFiles writes files to a temporary directory and changes into it.
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestGenerate(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"testdata/a.xml":     "<a y='2' x='1'>\n  <!-- c -->\n  <b/>\n</a>",
+		"testdata/b-`.xml":   "<b>`</b>",
+		"testdata/bad.xml":   "<a>",
+		"testdata/dup_a.xml": "<a/>",
+		"testdata/dup-a.xml": "<a/>",
+	})
+	src, err := generate(config{pkg: "fixtures", omitWhitespace: true, omitComments: true}, []string{"testdata/a.xml", "testdata/b-`.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "fixtures.go", src, 0)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	got := map[string]string{}
+	for _, d := range f.Decls {
+		for _, spec := range d.(*ast.GenDecl).Specs {
+			vs := spec.(*ast.ValueSpec)
+			s, err := strconv.Unquote(vs.Values[0].(*ast.BasicLit).Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[vs.Names[0].Name] = s
+		}
+	}
+	want := map[string]string{
+		"aXML": `<a x="1" y="2"><b></b></a>`,
+		"bXML": "<b>`</b>",
+	}
+	for id, s := range want {
+		if got[id] != s {
+			t.Errorf("%s: got %q, want %q", id, got[id], s)
+		}
+	}
+
+	if _, err := generate(config{pkg: "fixtures"}, []string{"testdata/bad.xml"}); err == nil || !strings.Contains(err.Error(), "bad.xml") {
+		t.Errorf("malformed: got error %v, want error for bad.xml", err)
+	}
+	if _, err := generate(config{pkg: "fixtures"}, []string{"testdata/dup-a.xml", "testdata/dup_a.xml"}); err == nil {
+		t.Errorf("duplicate: got nil error, want error")
+	}
+}
+
+func TestGenerateEmbed(t *testing.T) {
+	writeFiles(t, map[string]string{"testdata/a.xml": "<a/>"})
+	src, err := generate(config{pkg: "fixtures", embed: true, omitWhitespace: true}, []string{"testdata/a.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "fixtures.go", src, 0); err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"//go:embed testdata/a.xml\n",
+		`aXML = xmltestNormalize("testdata/a.xml")`,
+		"strings.NewReader(string(b)), xmltest.OmitWhitespace())",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("output does not contain %q:\n%s", want, src)
+		}
+	}
+	if _, err := generate(config{pkg: "fixtures", embed: true}, []string{"../a.xml"}); err == nil {
+		t.Errorf("outside: got nil error, want error")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tolerance configures the approximate comparison of numbers. Two numbers
+// x and y are equal if |x-y| <= Abs or |x-y| <= Rel * max(|x|, |y|). The
+// zero Tolerance compares numbers by exact numeric value, so that 1.0
+// equals 1.00.
+type Tolerance struct {
+	Abs float64
+	Rel float64
+}
+
+// equal reports whether x and y are equal within the tolerance.
+func (tol *Tolerance) equal(x, y float64) bool {
+	d := math.Abs(x - y)
+	return d <= tol.Abs || d <= tol.Rel*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// parseNumber parses s as a finite decimal number, ignoring surrounding
+// whitespace.
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// timeLayouts lists the RFC 3339 and ISO 8601 layouts recognized when
+// comparing timestamps. Fractional seconds are accepted by time.Parse
+// without being part of the layout.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"20060102T150405Z0700",
+	"2006-01-02Z07:00",
+	"2006-01-02",
+}
+
+// parseTimestamp parses s as an RFC 3339 or ISO 8601 timestamp, ignoring
+// surrounding whitespace. Timestamps without a zone are taken as UTC.
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < len("2006-01-02") {
+		return time.Time{}, false
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// readTokens reads all normalized tokens of r.
+func (n *Normalizer) readTokens(r io.Reader) ([]xml.Token, error) {
+	r, err := n.decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	d, release := n.newDecoder(r)
+	defer release()
+	tn := n.getTokenNormalizer(d)
+	defer putTokenNormalizer(tn)
+	var toks []xml.Token
+	for {
+		t, err := tn.Token()
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, t)
+	}
+}
+
+// equalTokens reports whether the normalized token streams a and b are
+// equal.
+func (n *Normalizer) equalTokens(a, b []xml.Token) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !n.equalToken(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *Normalizer) equalToken(a, b xml.Token) bool {
+	switch a := a.(type) {
+	case xml.StartElement:
+		b, ok := b.(xml.StartElement)
+		if !ok || a.Name != b.Name || len(a.Attr) != len(b.Attr) {
+			return false
+		}
+		for i := range a.Attr {
+			if a.Attr[i].Name != b.Attr[i].Name || !n.equalAttr(a.Attr[i].Name, a.Attr[i].Value, b.Attr[i].Value) {
+				return false
+			}
+		}
+		return true
+	case xml.EndElement:
+		b, ok := b.(xml.EndElement)
+		return ok && a.Name == b.Name
+	case xml.CharData:
+		b, ok := b.(xml.CharData)
+		return ok && n.equalValue(string(a), string(b))
+	case xml.Comment:
+		b, ok := b.(xml.Comment)
+		return ok && string(a) == string(b)
+	}
+	return false
+}
+
+// equalValue reports whether the text or attribute values a and b are
+// equal.
+func (n *Normalizer) equalValue(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if n.NumberTolerance != nil {
+		x, okx := parseNumber(a)
+		y, oky := parseNumber(b)
+		if okx && oky {
+			return n.NumberTolerance.equal(x, y)
+		}
+	}
+	if n.CompareTimestamps {
+		x, okx := parseTimestamp(a)
+		y, oky := parseTimestamp(b)
+		if okx && oky {
+			d := x.Sub(y)
+			return -n.TimestampTolerance <= d && d <= n.TimestampTolerance
+		}
+	}
+	return false
+}
+
+// equalAttr reports whether the values a and b of attribute name are
+// equal.
+func (n *Normalizer) equalAttr(name xml.Name, a, b string) bool {
+	if a != b && matchName(n.NumericAttrs, name) {
+		return n.equalNumbers(a, b)
+	}
+	return n.equalValue(a, b)
+}
+
+var numberPattern = regexp.MustCompile(`[-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`)
+
+// equalNumbers reports whether a and b contain the same sequence of
+// numbers within the number tolerance, separated by the same text apart
+// from whitespace and commas.
+func (n *Normalizer) equalNumbers(a, b string) bool {
+	ia := numberPattern.FindAllStringIndex(a, -1)
+	ib := numberPattern.FindAllStringIndex(b, -1)
+	if len(ia) != len(ib) {
+		return false
+	}
+	tol := n.NumberTolerance
+	if tol == nil {
+		tol = &Tolerance{}
+	}
+	pa, pb := 0, 0
+	for i := range ia {
+		if separator(a[pa:ia[i][0]]) != separator(b[pb:ib[i][0]]) {
+			return false
+		}
+		x, errx := strconv.ParseFloat(a[ia[i][0]:ia[i][1]], 64)
+		y, erry := strconv.ParseFloat(b[ib[i][0]:ib[i][1]], 64)
+		if errx != nil || erry != nil || !tol.equal(x, y) {
+			return false
+		}
+		pa, pb = ia[i][1], ib[i][1]
+	}
+	return separator(a[pa:]) == separator(b[pb:])
+}
+
+// separator returns s without whitespace and commas.
+func separator(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,94 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// decompressor is a compression format registered with
+// RegisterDecompressor.
+type decompressor struct {
+	name, magic string
+	decompress  func(io.Reader) (io.Reader, error)
+}
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   []decompressor
+)
+
+// zstdMagic starts Zstandard frames, which the standard library cannot
+// decompress.
+const zstdMagic = "\x28\xb5\x2f\xfd"
+
+var errZstd = errors.New("xmltest: zstd input needs a decompressor registered with RegisterDecompressor")
+
+func init() {
+	RegisterDecompressor("gzip", "\x1f\x8b", func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	})
+}
+
+// RegisterDecompressor registers a compression format for input
+// decompression, see Normalizer.Decompress. Input that starts with magic
+// is decompressed by decompress. It panics if a format is registered twice
+// under the same name. Gzip is registered by default. Zstandard input
+// fails with an error unless a decompressor is registered for it, for
+// example by
+//
+//	xmltest.RegisterDecompressor("zstd", "\x28\xb5\x2f\xfd", func(r io.Reader) (io.Reader, error) {
+//		return zstd.NewReader(r)
+//	})
+func RegisterDecompressor(name, magic string, decompress func(io.Reader) (io.Reader, error)) {
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	for _, d := range decompressors {
+		if d.name == name {
+			panic("xmltest: RegisterDecompressor called twice for format " + name)
+		}
+	}
+	decompressors = append(decompressors, decompressor{name, magic, decompress})
+}
+
+// decompress returns a reader of the decompressed content of r if
+// n.Decompress is set and r starts with the magic bytes of a registered
+// format, or else a reader of the content of r.
+func (n *Normalizer) decompress(r io.Reader) (io.Reader, error) {
+	if !n.Decompress {
+		return r, nil
+	}
+	decompressorsMu.RLock()
+	ds := decompressors
+	decompressorsMu.RUnlock()
+	size := len(zstdMagic)
+	for _, d := range ds {
+		if len(d.magic) > size {
+			size = len(d.magic)
+		}
+	}
+	br := bufio.NewReader(r)
+	// A short input is not compressed; Peek reports it with an error.
+	head, _ := br.Peek(size)
+	for _, d := range ds {
+		if strings.HasPrefix(string(head), d.magic) {
+			dr, err := d.decompress(br)
+			if err != nil {
+				return nil, fmt.Errorf("xmltest: %s input: %v", d.name, err)
+			}
+			return dr, nil
+		}
+	}
+	if strings.HasPrefix(string(head), zstdMagic) {
+		return nil, errZstd
+	}
+	return br, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// A trivial format for testing: the content follows a header.
+	RegisterDecompressor("test", "TEST:", func(r io.Reader) (io.Reader, error) {
+		_, err := io.ReadFull(r, make([]byte, len("TEST:")))
+		return r, err
+	})
+}
+
+func gzipped(s string) string {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return b.String()
+}
+
+func TestDecompress(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		in      string
+		want    string
+		wantErr string
+	}{{
+		desc: "plain",
+		in:   `<a y="2" x="1"/>`,
+		want: `<a x="1" y="2"></a>`,
+	}, {
+		desc: "gzip",
+		in:   gzipped(`<a y="2" x="1"/>`),
+		want: `<a x="1" y="2"></a>`,
+	}, {
+		desc: "registered format",
+		in:   `TEST:<a/>`,
+		want: `<a></a>`,
+	}, {
+		desc:    "zstd without decompressor",
+		in:      "\x28\xb5\x2f\xfd\x00\x00",
+		wantErr: "xmltest: zstd input needs a decompressor registered with RegisterDecompressor",
+	}, {
+		desc:    "truncated gzip header",
+		in:      "\x1f\x8b",
+		wantErr: "xmltest: gzip input: unexpected EOF",
+	}}
+
+	n := Normalizer{Decompress: true}
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := n.Normalize(&b, strings.NewReader(tc.in))
+		if tc.wantErr != "" {
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("%s: got err %v, want %s", tc.desc, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestDecompressEqualXML(t *testing.T) {
+	a, b := gzipped(`<a><b>1</b></a>`), `<a><b>1</b></a>`
+	for _, n := range []Normalizer{
+		{Decompress: true},
+		{Decompress: true, Precheck: true},
+		{Decompress: true, Cache: NewMemoryCache()},
+	} {
+		equal, err := n.EqualXML(strings.NewReader(a), strings.NewReader(b))
+		if err != nil || !equal {
+			t.Errorf("%+v: got %v, %v, want true, nil", n, equal, err)
+		}
+	}
+	var n Normalizer
+	if _, err := n.EqualXML(strings.NewReader(a), strings.NewReader(b)); err == nil {
+		t.Errorf("without Decompress: got err nil, want error")
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ConformanceTest is a test of the W3C XML conformance test suite, as
+// listed by a TEST element of a test catalog such as xmlconf/xmltest/xmltest.xml.
+type ConformanceTest struct {
+	// ID identifies the test, and Type is its category: valid, invalid,
+	// not-wf or error.
+	ID, Type string
+	// Entities lists the kinds of external entities the test reads, and
+	// Sections the sections of the recommendation that it covers.
+	Entities, Sections string
+	// Path is the file of the test document, and Output the file of its
+	// canonical form, if any.
+	Path, Output string
+	// Description describes the test.
+	Description string
+}
+
+// Input returns the content of the test document.
+func (ct ConformanceTest) Input() ([]byte, error) {
+	return os.ReadFile(ct.Path)
+}
+
+// ReadConformanceCatalog reads the tests listed by the catalog file of the
+// W3C XML conformance test suite at name. The files of the tests are
+// resolved against the xml:base attributes of the enclosing TESTCASES
+// elements and the directory of the catalog. Catalogs included through
+// external entities, such as by xmlconf/xmlconf.xml, are not read, so the
+// catalogs of the test collections must be read one by one.
+func ReadConformanceCatalog(name string) ([]ConformanceTest, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d := xml.NewDecoder(f)
+	// Catalogs declare the entities of their test collections.
+	d.Strict = false
+	var (
+		tests []ConformanceTest
+		bases = []string{filepath.ToSlash(filepath.Dir(name)) + "/"}
+		cur   *ConformanceTest
+		desc  strings.Builder
+	)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return tests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			base := bases[len(bases)-1]
+			for _, a := range t.Attr {
+				if a.Name == (xml.Name{Space: xmlURL, Local: "base"}) {
+					base = resolveSlash(base, a.Value)
+				}
+			}
+			bases = append(bases, base)
+			if t.Name.Local != "TEST" {
+				continue
+			}
+			cur = &ConformanceTest{
+				ID:       catalogAttr(t, "ID"),
+				Type:     catalogAttr(t, "TYPE"),
+				Entities: catalogAttr(t, "ENTITIES"),
+				Sections: catalogAttr(t, "SECTIONS"),
+				Path:     filepath.FromSlash(resolveSlash(base, catalogAttr(t, "URI"))),
+			}
+			if out := catalogAttr(t, "OUTPUT"); out != "" {
+				cur.Output = filepath.FromSlash(resolveSlash(base, out))
+			}
+			desc.Reset()
+		case xml.CharData:
+			if cur != nil {
+				desc.Write(t)
+			}
+		case xml.EndElement:
+			bases = bases[:len(bases)-1]
+			if t.Name.Local == "TEST" && cur != nil {
+				cur.Description = strings.Join(strings.Fields(desc.String()), " ")
+				tests = append(tests, *cur)
+				cur = nil
+			}
+		}
+	}
+}
+
+// catalogAttr returns the value of the unqualified attribute local of
+// start, if any.
+func catalogAttr(start xml.StartElement, local string) string {
+	v, _ := attrValue(start, local)
+	return v
+}
+
+// resolveSlash resolves the slash-separated relative path ref against the
+// directory base, which ends with a slash.
+func resolveSlash(base, ref string) string {
+	if path.IsAbs(ref) {
+		return ref
+	}
+	p := path.Join(base, ref)
+	if strings.HasSuffix(ref, "/") {
+		p += "/"
+	}
+	return p
+}
+
+// RunConformance runs check for each of tests as a subtest of t named by
+// the test ID, and reports the error it returns, if any. Parser authors
+// can pass their own check; CheckConformance checks the Normalizer.
+func RunConformance(t *testing.T, tests []ConformanceTest, check func(ct ConformanceTest) error) {
+	t.Helper()
+	for _, ct := range tests {
+		ct := ct
+		t.Run(ct.ID, func(t *testing.T) {
+			t.Helper()
+			if err := check(ct); err != nil {
+				t.Errorf("%s: %v", ct.Description, err)
+			}
+		})
+	}
+}
+
+// CheckConformance checks that n behaves as ct expects of a
+// non-validating processor: Normalize must fail for not-wf tests, and
+// succeed for valid and invalid tests, whose output must then equal that
+// of the canonical form, if any. Any behavior passes tests of type error.
+// Note that encoding/xml reads neither external entities nor entity
+// declarations, so tests that rely on them fail.
+func (n *Normalizer) CheckConformance(ct ConformanceTest) error {
+	in, err := ct.Input()
+	if err != nil {
+		return err
+	}
+	err = n.Normalize(io.Discard, bytes.NewReader(in))
+	switch ct.Type {
+	case "not-wf":
+		if err == nil {
+			return errors.New("xmltest: not well-formed document accepted")
+		}
+		return nil
+	case "valid", "invalid":
+		if err != nil {
+			return err
+		}
+	case "error":
+		return nil
+	default:
+		return fmt.Errorf("xmltest: unknown test type %q", ct.Type)
+	}
+	if ct.Output == "" {
+		return nil
+	}
+	want, err := os.ReadFile(ct.Output)
+	if err != nil {
+		return err
+	}
+	return n.differenceError("document differs from canonical form (a: document, b: canonical form)", in, want)
+}
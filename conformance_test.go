@@ -0,0 +1,87 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConformanceSuite writes a small suite in the layout of the W3C XML
+// conformance test suite and returns the file of its catalog.
+func writeConformanceSuite(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tests/tests.xml": `<!DOCTYPE TESTCASES SYSTEM "testcases.dtd">
+<TESTCASES PROFILE="sample" xml:base="xml/">
+<TEST TYPE="valid" ENTITIES="none" ID="v-001" URI="valid/001.xml" OUTPUT="valid/out/001.xml" SECTIONS="2.1">
+  A valid   document.
+</TEST>
+<TEST TYPE="not-wf" ENTITIES="none" ID="nwf-001" URI="not-wf/001.xml" SECTIONS="2.1">Mismatched tags.</TEST>
+<TEST TYPE="error" ENTITIES="none" ID="e-001" URI="not-wf/001.xml" SECTIONS="2.1">Either.</TEST>
+</TESTCASES>`,
+		"tests/xml/valid/001.xml":     "<doc  b='2' a='1'/>",
+		"tests/xml/valid/out/001.xml": `<doc a="1" b="2"></doc>`,
+		"tests/xml/not-wf/001.xml":    "<doc></dock>",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "tests", "tests.xml")
+}
+
+func TestReadConformanceCatalog(t *testing.T) {
+	catalog := writeConformanceSuite(t)
+	tests, err := ReadConformanceCatalog(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 3 {
+		t.Fatalf("got %d tests, want 3", len(tests))
+	}
+	dir := filepath.Dir(catalog)
+	want := ConformanceTest{
+		ID:          "v-001",
+		Type:        "valid",
+		Entities:    "none",
+		Sections:    "2.1",
+		Path:        filepath.Join(dir, "xml", "valid", "001.xml"),
+		Output:      filepath.Join(dir, "xml", "valid", "out", "001.xml"),
+		Description: "A valid document.",
+	}
+	if tests[0] != want {
+		t.Errorf("got %+v, want %+v", tests[0], want)
+	}
+	if tests[1].Type != "not-wf" || tests[1].Output != "" {
+		t.Errorf("got %+v, want a not-wf test without output", tests[1])
+	}
+}
+
+func TestCheckConformance(t *testing.T) {
+	tests, err := ReadConformanceCatalog(writeConformanceSuite(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RunConformance(t, tests, New().CheckConformance)
+
+	// A processor that accepts everything fails the not-wf test.
+	lax := tests[1]
+	lax.Type = "valid"
+	if err := New().CheckConformance(lax); err == nil {
+		t.Errorf("got nil error for a malformed valid document, want some")
+	}
+	wrong := tests[0]
+	wrong.Output = tests[1].Path
+	if err := New().CheckConformance(wrong); err == nil {
+		t.Errorf("got nil error for a wrong canonical form, want some")
+	}
+}
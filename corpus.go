@@ -0,0 +1,229 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Corpus holds pairs of documents to compare, such as the expected and
+// actual outputs of a parser or converter for a conformance corpus.
+type Corpus struct {
+	Pairs []CorpusPair
+}
+
+// CorpusPair is a named pair of documents of a Corpus. A nil document is
+// missing.
+type CorpusPair struct {
+	Name string
+	A, B []byte
+}
+
+// Add adds the pair of documents a and b named name.
+func (c *Corpus) Add(name string, a, b []byte) {
+	c.Pairs = append(c.Pairs, CorpusPair{Name: name, A: a, B: b})
+}
+
+// AddDirs adds the regular files of the directory trees rooted at a and b
+// as pairs matched by relative path, which names them. Files present in
+// only one tree are added with the other document missing.
+func (c *Corpus) AddDirs(a, b string) error {
+	fa, err := listFiles(a)
+	if err != nil {
+		return err
+	}
+	fb, err := listFiles(b)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(fa))
+	for p := range fa {
+		paths = append(paths, p)
+	}
+	for p := range fb {
+		if !fa[p] {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		var pair CorpusPair
+		pair.Name = p
+		if fa[p] {
+			if pair.A, err = os.ReadFile(filepath.Join(a, filepath.FromSlash(p))); err != nil {
+				return err
+			}
+		}
+		if fb[p] {
+			if pair.B, err = os.ReadFile(filepath.Join(b, filepath.FromSlash(p))); err != nil {
+				return err
+			}
+		}
+		c.Pairs = append(c.Pairs, pair)
+	}
+	return nil
+}
+
+// CorpusResult is the result of comparing a pair of a Corpus.
+type CorpusResult struct {
+	Name string
+	// Differences holds the differences between the documents, and Err
+	// the error comparing them, such as a missing or malformed document.
+	Differences []Difference
+	Err         error
+}
+
+// Passed reports whether the documents are equal.
+func (r CorpusResult) Passed() bool {
+	return r.Err == nil && len(r.Differences) == 0
+}
+
+// CorpusReport summarizes the comparison of a Corpus.
+type CorpusReport struct {
+	// Results holds the result of each pair, in the order of the pairs.
+	Results []CorpusResult
+	// Passed counts the equal pairs, Failed those that differ and Errors
+	// those that could not be compared.
+	Passed, Failed, Errors int
+	// Causes counts the differences of all pairs by cause, and Paths by
+	// path with positions and predicates removed, such as /root/item/@id,
+	// so that common differences stand out.
+	Causes map[Cause]int
+	Paths  map[string]int
+}
+
+var errMissing = errors.New("xmltest: document missing")
+
+// RunCorpus compares the normalized XML contents of the pairs of c, up to
+// Parallelism pairs at once, and summarizes the results.
+func (n *Normalizer) RunCorpus(c *Corpus) *CorpusReport {
+	results := make([]CorpusResult, len(c.Pairs))
+	run := func(i int) {
+		p := c.Pairs[i]
+		results[i].Name = p.Name
+		if p.A == nil || p.B == nil {
+			results[i].Err = errMissing
+			return
+		}
+		results[i].Differences, results[i].Err = n.Diff(bytes.NewReader(p.A), bytes.NewReader(p.B))
+	}
+	if n.Parallelism <= 1 || n.Trace != nil {
+		for i := range c.Pairs {
+			run(i)
+		}
+	} else {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, n.Parallelism)
+		for i := range c.Pairs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer func() { <-sem; wg.Done() }()
+				run(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+	rep := &CorpusReport{Results: results, Causes: map[Cause]int{}, Paths: map[string]int{}}
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			rep.Errors++
+		case len(r.Differences) > 0:
+			rep.Failed++
+		default:
+			rep.Passed++
+		}
+		for _, d := range r.Differences {
+			rep.Causes[d.Cause]++
+			rep.Paths[stripPredicates(d.Path)]++
+		}
+	}
+	return rep
+}
+
+// String returns the counts of the report, its most common differences and
+// the pairs that failed.
+func (r *CorpusReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d pairs: %d passed, %d failed, %d errors\n", len(r.Results), r.Passed, r.Failed, r.Errors)
+	if len(r.Causes) > 0 {
+		causes := make([]Cause, 0, len(r.Causes))
+		for c := range r.Causes {
+			causes = append(causes, c)
+		}
+		sort.Slice(causes, func(i, j int) bool {
+			ci, cj := causes[i], causes[j]
+			return r.Causes[ci] > r.Causes[cj] || r.Causes[ci] == r.Causes[cj] && ci < cj
+		})
+		b.WriteString("causes:")
+		for _, c := range causes {
+			fmt.Fprintf(&b, " %s %d", c, r.Causes[c])
+		}
+		b.WriteByte('\n')
+		paths := make([]string, 0, len(r.Paths))
+		for p := range r.Paths {
+			paths = append(paths, p)
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			pi, pj := paths[i], paths[j]
+			return r.Paths[pi] > r.Paths[pj] || r.Paths[pi] == r.Paths[pj] && pi < pj
+		})
+		if len(paths) > 10 {
+			paths = paths[:10]
+		}
+		b.WriteString("common paths:\n")
+		for _, p := range paths {
+			fmt.Fprintf(&b, "\t%d %s\n", r.Paths[p], p)
+		}
+	}
+	for _, res := range r.Results {
+		switch {
+		case res.Err != nil:
+			fmt.Fprintf(&b, "error: %s: %v\n", res.Name, res.Err)
+		case len(res.Differences) > 0:
+			fmt.Fprintf(&b, "failed: %s: %d differences\n", res.Name, len(res.Differences))
+		}
+	}
+	return b.String()
+}
+
+// stripPredicates removes the bracketed positions and predicates of the
+// path p.
+func stripPredicates(p string) string {
+	var b strings.Builder
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case depth > 0 && (c == '\'' || c == '"'):
+			quote = c
+			continue
+		case c == '[':
+			depth++
+			continue
+		case c == ']' && depth > 0:
+			depth--
+			continue
+		}
+		if depth == 0 {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
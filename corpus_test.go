@@ -0,0 +1,83 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCorpus(t *testing.T) {
+	for _, k := range []int{1, 4} {
+		var c Corpus
+		c.Add("equal", []byte(`<a x="1"/>`), []byte(`<a  x="1"></a>`))
+		c.Add("text", []byte(`<a><b>1</b><b>2</b></a>`), []byte(`<a><b>1</b><b>3</b></a>`))
+		c.Add("attr", []byte(`<a><b x="1"/></a>`), []byte(`<a><b x="2"/></a>`))
+		c.Add("malformed", []byte(`<a>`), []byte(`<a/>`))
+		c.Add("missing", []byte(`<a/>`), nil)
+		rep := New(Parallelism(k)).RunCorpus(&c)
+		if rep.Passed != 1 || rep.Failed != 2 || rep.Errors != 2 {
+			t.Errorf("parallelism %d: got %d passed, %d failed, %d errors, want 1, 2, 2", k, rep.Passed, rep.Failed, rep.Errors)
+		}
+		if got := rep.Causes[CauseText]; got != 2 {
+			t.Errorf("parallelism %d: got %d text differences, want 2", k, got)
+		}
+		if got := rep.Paths["/a/b/text()"]; got != 1 {
+			t.Errorf("parallelism %d: got %d differences at /a/b/text(), want 1", k, got)
+		}
+		for i, name := range []string{"equal", "text", "attr", "malformed", "missing"} {
+			if rep.Results[i].Name != name {
+				t.Errorf("parallelism %d: result %d: got %s, want %s", k, i, rep.Results[i].Name, name)
+			}
+		}
+		s := rep.String()
+		for _, want := range []string{"5 pairs: 1 passed, 2 failed, 2 errors", "causes: text 2", "failed: attr: 1 differences", "error: missing:"} {
+			if !strings.Contains(s, want) {
+				t.Errorf("parallelism %d: got report\n%s\nwant it to contain %q", k, s, want)
+			}
+		}
+	}
+}
+
+func TestCorpusAddDirs(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	for _, f := range []struct{ dir, name, content string }{
+		{a, "x.xml", `<x/>`},
+		{b, "x.xml", `<x></x>`},
+		{a, "y.xml", `<y/>`},
+		{b, "z.xml", `<z/>`},
+	} {
+		if err := os.WriteFile(filepath.Join(f.dir, f.name), []byte(f.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var c Corpus
+	if err := c.AddDirs(a, b); err != nil {
+		t.Fatal(err)
+	}
+	rep := New().RunCorpus(&c)
+	if rep.Passed != 1 || rep.Errors != 2 {
+		t.Errorf("got %d passed and %d errors, want 1 and 2", rep.Passed, rep.Errors)
+	}
+}
+
+func TestStripPredicates(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{
+		{"positions", "/root/p[2]/text()", "/root/p/text()"},
+		{"predicate", "/root/item[@id='7']/@name", "/root/item/@name"},
+		{"quoted bracket", "/root/item[@id='a]b']", "/root/item"},
+	}
+	for _, tc := range testCases {
+		if got := stripPredicates(tc.in); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// AssertCount reports an error to t unless path selects at least min and
+// at most max elements of the normalized document doc, using a Normalizer
+// configured by opts. A negative max sets no upper bound. Path is as
+// described for ExpectText.
+func AssertCount(t *testing.T, doc, path string, min, max int, opts ...Option) {
+	t.Helper()
+	if err := New(opts...).countError(doc, path, min, max); err != nil {
+		t.Error(err)
+	}
+}
+
+// countError returns an error unless path selects between min and max
+// elements of doc.
+func (n *Normalizer) countError(doc, path string, min, max int) error {
+	p, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if p.hasAttr {
+		return fmt.Errorf("xmltest: path %s selects an attribute", path)
+	}
+	toks, err := n.readTokens(strings.NewReader(doc))
+	if err != nil {
+		return err
+	}
+	var (
+		elems []xml.Name
+		count int
+	)
+	for _, t := range toks {
+		switch t := t.(type) {
+		case xml.StartElement:
+			elems = append(elems, t.Name)
+			if p.matchElement(elems) {
+				count++
+			}
+		case xml.EndElement:
+			elems = elems[:len(elems)-1]
+		}
+	}
+	switch {
+	case min == max && count != min:
+		return fmt.Errorf("xmltest: path %s selects %d elements, want %d", path, count, min)
+	case count < min:
+		return fmt.Errorf("xmltest: path %s selects %d elements, want at least %d", path, count, min)
+	case max >= 0 && count > max:
+		return fmt.Errorf("xmltest: path %s selects %d elements, want at most %d", path, count, max)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import "testing"
+
+func TestCountError(t *testing.T) {
+	const doc = `<env><head/><body><item/><item/><x><item/></x></body></env>`
+	testCases := []struct {
+		desc     string
+		path     string
+		min, max int
+		wantErr  string
+	}{{
+		desc: "exactly one",
+		path: "/env/body",
+		min:  1,
+		max:  1,
+	}, {
+		desc: "within range",
+		path: "item",
+		min:  1,
+		max:  10,
+	}, {
+		desc: "unbounded",
+		path: "/env/body/item",
+		min:  2,
+		max:  -1,
+	}, {
+		desc:    "not exactly",
+		path:    "item",
+		min:     2,
+		max:     2,
+		wantErr: "xmltest: path item selects 3 elements, want 2",
+	}, {
+		desc:    "too few",
+		path:    "/env/foot",
+		min:     1,
+		max:     -1,
+		wantErr: "xmltest: path /env/foot selects 0 elements, want at least 1",
+	}, {
+		desc:    "too many",
+		path:    "item",
+		max:     1,
+		wantErr: "xmltest: path item selects 3 elements, want at most 1",
+	}, {
+		desc:    "attribute",
+		path:    "item/@id",
+		wantErr: "xmltest: path item/@id selects an attribute",
+	}}
+
+	for _, tc := range testCases {
+		err := new(Normalizer).countError(doc, tc.path, tc.min, tc.max)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.wantErr {
+			t.Errorf("%s: got error %q, want %q", tc.desc, got, tc.wantErr)
+		}
+	}
+}
+
+func TestAssertCount(t *testing.T) {
+	AssertCount(t, `<a><b/><B/></a>`, "b", 2, 2, CaseInsensitiveNames())
+}
@@ -0,0 +1,32 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"regexp"
+	"strings"
+)
+
+// decimalComma matches numbers with a decimal comma, optionally with
+// thousands separated by points, spaces or apostrophes.
+var decimalComma = regexp.MustCompile(`^[+-]?(?:[0-9]+|[0-9]{1,3}(?:[. '\x{a0}\x{202f}][0-9]{3})+)(?:,[0-9]+)?$`)
+
+// pointDecimal returns s with a decimal point and without thousands
+// separators if it is a number with a decimal comma.
+func pointDecimal(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if !decimalComma.MatchString(s) {
+		return "", false
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ',':
+			return '.'
+		case '.', ' ', '\'', '\u00a0', '\u202f':
+			return -1
+		}
+		return r
+	}, s), true
+}
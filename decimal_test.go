@@ -0,0 +1,33 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import "testing"
+
+func TestPointDecimal(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"decimal comma", "12,5", "12.5", true},
+		{"integer", " 42 ", "42", true},
+		{"thousands points", "-1.234.567,89", "-1234567.89", true},
+		{"thousands spaces", "1 234,5", "1234.5", true},
+		{"thousands apostrophes", "1'234", "1234", true},
+		{"narrow no-break space", "1\u202f234,5", "1234.5", true},
+		{"misplaced separator", "12.34,5", "", false},
+		{"decimal point", "12.5", "", false},
+		{"two commas", "1,2,3", "", false},
+		{"text", "n/a", "", false},
+	}
+	for _, tc := range testCases {
+		got, ok := pointDecimal(tc.in)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("%s: got %q, %v, want %q, %v", tc.desc, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
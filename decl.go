@@ -0,0 +1,54 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// declaration returns the canonical XML declaration of the input read by
+// tn so far.
+func (tn *TokenNormalizer) declaration() xml.ProcInst {
+	inst := `version="1.0" encoding="UTF-8"`
+	switch tn.standalone {
+	case "yes", "no":
+		inst += ` standalone="` + tn.standalone + `"`
+	}
+	return xml.ProcInst{Target: "xml", Inst: []byte(inst)}
+}
+
+// procInstParam returns the value of the pseudo-attribute param of the
+// instruction inst, such as the encoding of an XML declaration, or "" if
+// there is none.
+func procInstParam(inst, param string) string {
+	for off := 0; ; {
+		i := strings.Index(inst[off:], param)
+		if i < 0 {
+			return ""
+		}
+		i += off
+		off = i + len(param)
+		if i > 0 && !isSpace(inst[i-1]) {
+			continue
+		}
+		v := strings.TrimLeft(inst[off:], " \t\r\n")
+		if !strings.HasPrefix(v, "=") {
+			continue
+		}
+		v = strings.TrimLeft(v[1:], " \t\r\n")
+		if v == "" || v[0] != '"' && v[0] != '\'' {
+			continue
+		}
+		if j := strings.IndexByte(v[1:], v[0]); j >= 0 {
+			return v[1 : j+1]
+		}
+		return ""
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestXMLDeclaration(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "no declaration",
+		in:   `<a/>`,
+		want: `<?xml version="1.0" encoding="UTF-8"?><a></a>`,
+	}, {
+		desc: "other encoding and quoting",
+		in:   `<?xml version='1.0' encoding='utf-8'?><a/>`,
+		want: `<?xml version="1.0" encoding="UTF-8"?><a></a>`,
+	}, {
+		desc: "standalone is kept",
+		in:   `<?xml version="1.0"   standalone = 'yes' ?><a/>`,
+		want: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><a></a>`,
+	}, {
+		desc: "invalid standalone is dropped",
+		in:   `<?xml version="1.0" standalone="maybe"?><a/>`,
+		want: `<?xml version="1.0" encoding="UTF-8"?><a></a>`,
+	}, {
+		desc: "other processing instructions are removed",
+		in:   `<?xml version="1.0"?><?style x?><a/>`,
+		want: `<?xml version="1.0" encoding="UTF-8"?><a></a>`,
+	}, {
+		desc: "empty input",
+		in:   ``,
+		want: ``,
+	}}
+
+	n := Normalizer{XMLDeclaration: true}
+	for _, tc := range testCases {
+		var b strings.Builder
+		if err := n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestProcInstParam(t *testing.T) {
+	testCases := []struct {
+		inst, param, want string
+	}{
+		{`version="1.0" encoding="UTF-8"`, "encoding", "UTF-8"},
+		{`version='1.0'`, "version", "1.0"},
+		{`xversion="2" version="1"`, "version", "1"},
+		{`version`, "version", ""},
+		{`version="1.0`, "version", ""},
+		{``, "version", ""},
+	}
+	for _, tc := range testCases {
+		if got := procInstParam(tc.inst, tc.param); got != tc.want {
+			t.Errorf("procInstParam(%q, %q): got %q, want %q", tc.inst, tc.param, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,736 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MatchChildrenBy configures EqualXML and Diff to pair the Child elements
+// of Parent elements by the value of their KeyAttr attribute, the values
+// of all their KeyAttrs attributes, or the text of their first KeyElem
+// child element, rather than by their position. Normalize sorts these
+// children by their key, within the positions they take among their
+// siblings, so the positions still have to match. Names are local names
+// and match elements in any namespace.
+type MatchChildrenBy struct {
+	Parent   string
+	Child    string
+	KeyAttr  string
+	KeyAttrs []string
+	KeyElem  string
+}
+
+// key returns the key of nd, if nd is a child matched by m.
+func (m *MatchChildrenBy) key(nd *node) (string, bool) {
+	start, ok := nd.tok.(xml.StartElement)
+	if !ok || start.Name.Local != m.Child {
+		return "", false
+	}
+	if m.KeyElem != "" {
+		for _, c := range nd.children {
+			if c.isElement() && c.name().Local == m.KeyElem {
+				var b strings.Builder
+				for _, t := range c.children {
+					if t, ok := t.tok.(xml.CharData); ok {
+						b.Write(t)
+					}
+				}
+				return strings.TrimSpace(b.String()), true
+			}
+		}
+		return "", false
+	}
+	if len(m.KeyAttrs) > 0 {
+		vals := make([]string, len(m.KeyAttrs))
+		for i, name := range m.KeyAttrs {
+			v, ok := attrValue(start, name)
+			if !ok {
+				return "", false
+			}
+			vals[i] = v
+		}
+		return strings.Join(vals, "\x00"), true
+	}
+	return attrValue(start, m.KeyAttr)
+}
+
+// attrValue returns the value of the attribute of start with local name.
+func attrValue(start xml.StartElement, local string) (string, bool) {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// predicate returns the XPath predicate that selects the child with key k.
+func (m *MatchChildrenBy) predicate(k string) string {
+	switch {
+	case m.KeyElem != "":
+		return fmt.Sprintf("[%s=%s]", m.KeyElem, quoteXPath(k))
+	case len(m.KeyAttrs) > 0:
+		vals := strings.Split(k, "\x00")
+		conds := make([]string, len(vals))
+		for i, v := range vals {
+			conds[i] = fmt.Sprintf("@%s=%s", m.KeyAttrs[i], quoteXPath(v))
+		}
+		return "[" + strings.Join(conds, " and ") + "]"
+	}
+	return fmt.Sprintf("[@%s=%s]", m.KeyAttr, quoteXPath(k))
+}
+
+// sort sorts the children matched by m by their key. Other nodes keep
+// their position.
+func (m *MatchChildrenBy) sort(nodes []*node) {
+	var (
+		slots []int
+		elems []*node
+		keys  = map[*node]string{}
+	)
+	for i, nd := range nodes {
+		if k, ok := m.key(nd); ok {
+			slots = append(slots, i)
+			elems = append(elems, nd)
+			keys[nd] = k
+		}
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		return keys[elems[i]] < keys[elems[j]]
+	})
+	for i, slot := range slots {
+		nodes[slot] = elems[i]
+	}
+}
+
+// positions returns the positions of the nodes matched by m among nodes,
+// counted from 1.
+func (m *MatchChildrenBy) positions(nodes []*node) []int {
+	var pos []int
+	for i, nd := range nodes {
+		if _, ok := m.key(nd); ok {
+			pos = append(pos, i+1)
+		}
+	}
+	return pos
+}
+
+// describePositions describes the positions pos of the nodes matched by m,
+// as in "<item> at 1, 3".
+func (m *MatchChildrenBy) describePositions(pos []int) string {
+	s := make([]string, len(pos))
+	for i, p := range pos {
+		s[i] = strconv.Itoa(p)
+	}
+	return "<" + m.Child + "> at " + strings.Join(s, ", ")
+}
+
+// equalInts reports whether a and b hold the same integers.
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchChildren returns the configuration to match the children of
+// element name, or nil.
+func (n *Normalizer) matchChildren(name xml.Name) *MatchChildrenBy {
+	for i := range n.MatchChildren {
+		if n.MatchChildren[i].Parent == name.Local {
+			return &n.MatchChildren[i]
+		}
+	}
+	return nil
+}
+
+// Difference describes a difference between two normalized XML documents.
+type Difference struct {
+	// Path locates the difference, for example
+	// /root/items/item[@id='7']/@name or /root/p[2]/text().
+	Path string
+	// A and B describe the differing content in the first and second
+	// document. One of them is empty if the content is missing in that
+	// document.
+	A, B string
+	// Offset is the offset in characters of A and B in the differing
+	// values, if narrowed down with GranularityText.
+	Offset int
+	// Cause classifies the difference.
+	Cause Cause
+}
+
+// Cause classifies a difference by what would remove it. Attributes are
+// sorted by normalization, so their order only causes differences if
+// KeepAttrOrder is set, reported at path/@* with CauseStructure.
+type Cause int
+
+const (
+	// CauseWhitespace is character data that differs only in whitespace,
+	// or that is only whitespace. OmitWhitespace removes the latter.
+	CauseWhitespace Cause = iota + 1
+	// CauseNamespace is an element or attribute whose names differ only in
+	// their namespaces.
+	CauseNamespace
+	// CauseComment is a differing comment. OmitComments removes them.
+	CauseComment
+	// CauseText is differing character data or attribute values.
+	CauseText
+	// CauseStructure is a missing element or attribute, nodes of
+	// different names or kinds, or attributes in a different order if
+	// their order is kept.
+	CauseStructure
+)
+
+var causeNames = [...]string{
+	CauseWhitespace: "whitespace",
+	CauseNamespace:  "namespace",
+	CauseComment:    "comment",
+	CauseText:       "text",
+	CauseStructure:  "structure",
+}
+
+func (c Cause) String() string {
+	if c > 0 && int(c) < len(causeNames) {
+		return causeNames[c]
+	}
+	return fmt.Sprintf("Cause(%d)", int(c))
+}
+
+// CountCauses returns the number of differences of diffs per cause.
+func CountCauses(diffs []Difference) map[Cause]int {
+	m := map[Cause]int{}
+	for _, d := range diffs {
+		m[d.Cause]++
+	}
+	return m
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.A == "":
+		return fmt.Sprintf("%s: only in b: %s", d.Path, d.B)
+	case d.B == "":
+		return fmt.Sprintf("%s: only in a: %s", d.Path, d.A)
+	case d.Offset > 0:
+		return fmt.Sprintf("%s: %s != %s at offset %d", d.Path, d.A, d.B, d.Offset)
+	}
+	return fmt.Sprintf("%s: %s != %s", d.Path, d.A, d.B)
+}
+
+// Granularity configures how Diff reports differences.
+type Granularity int
+
+const (
+	// GranularityToken reports each differing attribute, character data
+	// and comment.
+	GranularityToken Granularity = iota
+	// GranularityElement reports each element whose attributes, character
+	// data or comments differ once, as a whole, which keeps the reports of
+	// very different documents short.
+	GranularityElement
+	// GranularityText is GranularityToken, but narrows differing
+	// character data and attribute values down to the differing
+	// characters.
+	GranularityText
+)
+
+// Diff returns the differences between the normalized XML contents of a
+// and b. It reports no differences if and only if EqualXML reports a and b
+// as equal.
+func (n *Normalizer) Diff(a, b io.Reader) ([]Difference, error) {
+	d, err := n.diff(a, b, false)
+	if err != nil {
+		return nil, err
+	}
+	return d.diffs, nil
+}
+
+// diff compares the normalized XML contents of a and b. If patch is true,
+// it also collects the operations to patch a into b.
+func (n *Normalizer) diff(a, b io.Reader, patch bool) (*differ, error) {
+	ta, err := n.readTokens(a)
+	if err != nil {
+		return nil, err
+	}
+	tb, err := n.readTokens(b)
+	if err != nil {
+		return nil, err
+	}
+	d := &differ{n: n, patch: patch}
+	if !patch {
+		d.granularity, d.max = n.Granularity, n.MaxDifferences
+	}
+	d.nodes("", "", nil, nil, buildTree(ta), buildTree(tb))
+	return d, nil
+}
+
+// differ collects the differences between two document trees.
+type differ struct {
+	n           *Normalizer
+	diffs       []Difference
+	granularity Granularity
+	// max limits the number of differences, if positive.
+	max int
+	// patch instructs to collect the operations to patch the first into
+	// the second document, see patch.go.
+	patch    bool
+	ops      []patchOp
+	patchErr error
+	prefixes map[string]string
+}
+
+func (d *differ) add(cause Cause, path, a, b string) {
+	d.addDifference(Difference{Path: path, A: a, B: b, Cause: cause})
+}
+
+func (d *differ) addDifference(diff Difference) {
+	if !d.done() {
+		d.diffs = append(d.diffs, diff)
+	}
+}
+
+// done reports whether d has found as many differences as it may report.
+func (d *differ) done() bool {
+	return d.max > 0 && len(d.diffs) >= d.max
+}
+
+// addValues adds the difference of the differing values a and b at path.
+func (d *differ) addValues(path, a, b string) {
+	cause := CauseText
+	if strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ") {
+		cause = CauseWhitespace
+	}
+	if d.granularity != GranularityText {
+		d.add(cause, path, strconv.Quote(a), strconv.Quote(b))
+		return
+	}
+	off, a, b := narrow(a, b)
+	d.addDifference(Difference{Path: path, A: strconv.Quote(a), B: strconv.Quote(b), Offset: off, Cause: cause})
+}
+
+// missingCause classifies the node nd that is missing in the other
+// document.
+func missingCause(nd *node) Cause {
+	switch nd.tok.(type) {
+	case xml.Comment:
+		return CauseComment
+	case xml.CharData:
+		if isWhitespaceNode(nd) {
+			return CauseWhitespace
+		}
+		return CauseText
+	}
+	return CauseStructure
+}
+
+// replacedCause classifies the differing nodes a and b.
+func replacedCause(a, b *node) Cause {
+	switch {
+	case a.isElement() && b.isElement() && a.name().Local == b.name().Local:
+		return CauseNamespace
+	case isWhitespaceNode(a) && !b.isElement():
+		return missingCause(b)
+	case isWhitespaceNode(b) && !a.isElement():
+		return missingCause(a)
+	case isComment(a) && isComment(b):
+		return CauseComment
+	}
+	return CauseStructure
+}
+
+func isComment(nd *node) bool {
+	_, ok := nd.tok.(xml.Comment)
+	return ok
+}
+
+// narrow removes the common prefix and suffix of a and b, and returns the
+// length of the prefix in characters.
+func narrow(a, b string) (int, string, string) {
+	off, i := 0, 0
+	for i < len(a) && i < len(b) {
+		ra, na := utf8.DecodeRuneInString(a[i:])
+		rb, _ := utf8.DecodeRuneInString(b[i:])
+		if ra != rb {
+			break
+		}
+		off++
+		i += na
+	}
+	a, b = a[i:], b[i:]
+	for len(a) > 0 && len(b) > 0 {
+		ra, na := utf8.DecodeLastRuneInString(a)
+		rb, nb := utf8.DecodeLastRuneInString(b)
+		if ra != rb {
+			break
+		}
+		a, b = a[:len(a)-na], b[:len(b)-nb]
+	}
+	return off, a, b
+}
+
+// nodes compares the child nodes as and bs of the elements pa and pb at
+// path. The XPath sel selects pa in the first document. The parents are
+// nil for the top-level nodes of a document.
+func (d *differ) nodes(path, sel string, pa, pb *node, as, bs []*node) {
+	var (
+		steps   = d.xpathSteps(as)
+		removes []*node
+	)
+	// order keeps the nodes of as in document order, as the keyed nodes
+	// are taken out of as.
+	order := as
+	var m *MatchChildrenBy
+	if pa != nil {
+		m = d.n.matchChildren(pa.name())
+	}
+	if d.patch && (misaligned(as, bs) || m != nil && !m.patchable(as, bs)) {
+		// The patch format can't replace a node by one of another
+		// kind, nor add keyed nodes other than at the end, so replace
+		// the parent instead.
+		if pa == nil {
+			d.unpatchable()
+		}
+		d.addOp(patchOp{op: "replace", sel: sel, node: pb})
+		d.patch = false
+		defer func() { d.patch = true }()
+	}
+	if m != nil {
+		var rm []*node
+		as, bs, rm = d.keyed(path, sel, steps, m, as, bs)
+		removes = append(removes, rm...)
+	}
+	// The operations of each node of as are emitted in reverse document
+	// order, since replacing or removing a node changes the positions in
+	// the selectors of the following siblings, and added nodes last.
+	base := len(d.ops)
+	opsOf := map[*node][]patchOp{}
+	var adds []patchOp
+	names := stepNames(as, bs)
+	for i := 0; (i < len(as) || i < len(bs)) && !d.done(); i++ {
+		switch {
+		case i >= len(bs):
+			d.add(missingCause(as[i]), path+"/"+names.next(as[i]), describe(as[i]), "")
+			removes = append(removes, as[i])
+		case i >= len(as):
+			d.add(missingCause(bs[i]), path+"/"+names.next(bs[i]), "", describe(bs[i]))
+			if pa == nil {
+				d.unpatchable()
+			}
+			d.addOp(patchOp{op: "add", sel: sel, node: bs[i]})
+		default:
+			d.node(path+"/"+names.next(as[i]), sel+"/"+steps[as[i]], as[i], bs[i])
+		}
+		if i < len(as) {
+			opsOf[as[i]] = append(opsOf[as[i]], d.ops[base:]...)
+		} else {
+			adds = append(adds, d.ops[base:]...)
+		}
+		d.ops = d.ops[:base]
+	}
+	if len(removes) > 0 && pa == nil {
+		d.unpatchable()
+	}
+	for _, nd := range removes {
+		opsOf[nd] = append(opsOf[nd], patchOp{op: "remove", sel: sel + "/" + steps[nd]})
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		for _, op := range opsOf[order[i]] {
+			d.addOp(op)
+		}
+	}
+	d.ops = append(d.ops, adds...)
+}
+
+// keyed compares the children of as and bs that are matched by key. As
+// normalization sorts the keyed children into their positions among their
+// siblings, the positions must be equal, too. It returns the remaining
+// nodes and the keyed nodes only found in as.
+func (d *differ) keyed(path, sel string, steps map[*node]string, m *MatchChildrenBy, as, bs []*node) (restA, restB, removes []*node) {
+	// Positions are only reported if no keyed nodes are missing.
+	if pa, pb := m.positions(as), m.positions(bs); len(pa) == len(pb) && !equalInts(pa, pb) {
+		d.add(CauseStructure, path+"/"+m.Child, m.describePositions(pa), m.describePositions(pb))
+	}
+	byKey := map[string][]*node{}
+	for _, b := range bs {
+		if k, ok := m.key(b); ok {
+			byKey[k] = append(byKey[k], b)
+		} else {
+			restB = append(restB, b)
+		}
+	}
+	step := func(k string) string {
+		return path + "/" + m.Child + m.predicate(k)
+	}
+	for _, a := range as {
+		k, ok := m.key(a)
+		if !ok {
+			restA = append(restA, a)
+			continue
+		}
+		if len(byKey[k]) == 0 {
+			d.add(CauseStructure, step(k), describe(a), "")
+			removes = append(removes, a)
+			continue
+		}
+		d.node(step(k), sel+"/"+steps[a], a, byKey[k][0])
+		byKey[k] = byKey[k][1:]
+	}
+	for _, b := range bs {
+		if k, ok := m.key(b); ok && len(byKey[k]) > 0 {
+			d.add(CauseStructure, step(k), "", describe(b))
+			d.addOp(patchOp{op: "add", sel: sel, node: b})
+			byKey[k] = byKey[k][1:]
+		}
+	}
+	return restA, restB, removes
+}
+
+// node compares the nodes a and b at path. The XPath sel selects a in the
+// first document.
+func (d *differ) node(path, sel string, a, b *node) {
+	switch ta := a.tok.(type) {
+	case xml.StartElement:
+		tb, ok := b.tok.(xml.StartElement)
+		if !ok || ta.Name != tb.Name {
+			d.add(replacedCause(a, b), path, describe(a), describe(b))
+			d.addOp(patchOp{op: "replace", sel: sel, node: b})
+			return
+		}
+		if d.granularity == GranularityElement {
+			d.element(path, a, b)
+			return
+		}
+		d.attrs(path, sel, ta.Attr, tb.Attr)
+		d.nodes(path, sel, a, b, a.children, b.children)
+	case xml.CharData:
+		tb, ok := b.tok.(xml.CharData)
+		switch {
+		case ok && d.n.equalValue(string(ta), string(tb)):
+		case ok:
+			d.addValues(path, string(ta), string(tb))
+			d.addOp(patchOp{op: "replace", sel: sel, node: b})
+		default:
+			d.add(replacedCause(a, b), path, describe(a), describe(b))
+			d.addOp(patchOp{op: "replace", sel: sel, node: b})
+		}
+	case xml.Comment:
+		tb, ok := b.tok.(xml.Comment)
+		if !ok || string(ta) != string(tb) {
+			d.add(replacedCause(a, b), path, describe(a), describe(b))
+			d.addOp(patchOp{op: "replace", sel: sel, node: b})
+		}
+	}
+}
+
+// element compares the elements a and b of equal names at path for
+// GranularityElement. It reports a single difference if their attributes,
+// character data or comments differ, and compares their child elements.
+func (d *differ) element(path string, a, b *node) {
+	var (
+		ea, eb = elementChildren(a), elementChildren(b)
+		oa, ob = otherChildren(a), otherChildren(b)
+		n      = len(d.diffs)
+	)
+	d.attrs(path, "", a.tok.(xml.StartElement).Attr, b.tok.(xml.StartElement).Attr)
+	d.nodes(path, "", a, b, oa, ob)
+	if len(d.diffs) > n {
+		// Classify by the most severe cause.
+		var cause Cause
+		for _, diff := range d.diffs[n:] {
+			if diff.Cause > cause {
+				cause = diff.Cause
+			}
+		}
+		d.diffs = d.diffs[:n]
+		d.add(cause, path, describeOwn(a, oa), describeOwn(b, ob))
+	}
+	d.nodes(path, "", a, b, ea, eb)
+}
+
+// elementChildren returns the child elements of nd.
+func elementChildren(nd *node) []*node {
+	var nodes []*node
+	for _, c := range nd.children {
+		if c.isElement() {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// otherChildren returns the child nodes of nd that are not elements.
+func otherChildren(nd *node) []*node {
+	var nodes []*node
+	for _, c := range nd.children {
+		if !c.isElement() {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// describeOwn describes the element nd with its child nodes other, but
+// without its child elements.
+func describeOwn(nd *node, other []*node) string {
+	s := describe(nd)
+	for _, c := range other {
+		s += " " + describe(c)
+	}
+	return s
+}
+
+// attrs compares the sorted attributes as and bs of the element at path.
+// The XPath sel selects the element in the first document.
+func (d *differ) attrs(path, sel string, as, bs []xml.Attr) {
+	if d.n.KeepAttrOrder {
+		if oa, ob := commonAttrNames(as, bs), commonAttrNames(bs, as); oa != ob {
+			d.add(CauseStructure, path+"/@*", oa, ob)
+		}
+	}
+	if d.n.LessAttr != nil || d.n.KeepAttrOrder {
+		// Match attributes in the order by name.
+		as = append([]xml.Attr(nil), as...)
+		bs = append([]xml.Attr(nil), bs...)
+		sortAttrs(as, nil)
+		sortAttrs(bs, nil)
+	}
+	allA, allB := as, bs
+	for (len(as) > 0 || len(bs) > 0) && !d.done() {
+		switch {
+		case len(bs) == 0 || len(as) > 0 && lessName(as[0].Name, bs[0].Name):
+			d.add(attrCause(as[0].Name, allB), path+"/@"+as[0].Name.Local, strconv.Quote(as[0].Value), "")
+			d.addOp(patchOp{op: "remove", sel: sel + "/@" + d.qname(as[0].Name)})
+			as = as[1:]
+		case len(as) == 0 || lessName(bs[0].Name, as[0].Name):
+			d.add(attrCause(bs[0].Name, allA), path+"/@"+bs[0].Name.Local, "", strconv.Quote(bs[0].Value))
+			d.addOp(patchOp{op: "add", sel: sel, attr: "@" + d.qname(bs[0].Name), text: bs[0].Value})
+			bs = bs[1:]
+		default:
+			if !d.n.equalAttr(as[0].Name, as[0].Value, bs[0].Value) {
+				d.addValues(path+"/@"+as[0].Name.Local, as[0].Value, bs[0].Value)
+				d.addOp(patchOp{op: "replace", sel: sel + "/@" + d.qname(as[0].Name), text: bs[0].Value})
+			}
+			as, bs = as[1:], bs[1:]
+		}
+	}
+}
+
+// commonAttrNames returns the names of the attributes as that are also in
+// bs, in the order of as.
+func commonAttrNames(as, bs []xml.Attr) string {
+	var names []string
+	for _, a := range as {
+		for _, b := range bs {
+			if a.Name == b.Name {
+				names = append(names, a.Name.Local)
+				break
+			}
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// attrCause classifies the attribute name that is missing in the
+// attributes other of the other document.
+func attrCause(name xml.Name, other []xml.Attr) Cause {
+	for _, a := range other {
+		if a.Name.Local == name.Local {
+			return CauseNamespace
+		}
+	}
+	return CauseStructure
+}
+
+func lessName(a, b xml.Name) bool {
+	if a.Space != b.Space {
+		return a.Space < b.Space
+	}
+	return a.Local < b.Local
+}
+
+// describe returns a short description of nd for difference reports.
+func describe(nd *node) string {
+	switch t := nd.tok.(type) {
+	case xml.StartElement:
+		var b strings.Builder
+		b.WriteString("<" + t.Name.Local)
+		for _, a := range t.Attr {
+			fmt.Fprintf(&b, " %s=%q", a.Name.Local, a.Value)
+		}
+		b.WriteString(">")
+		return b.String()
+	case xml.CharData:
+		return strconv.Quote(string(t))
+	case xml.Comment:
+		return "<!--" + string(t) + "-->"
+	}
+	return ""
+}
+
+// quoteXPath quotes s as an XPath string literal.
+func quoteXPath(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+// stepCounter names the location steps of sibling nodes. It only adds a
+// position predicate if the siblings of either document are ambiguous.
+type stepCounter struct {
+	total map[string]int
+	seen  map[string]int
+}
+
+func stepNames(as, bs []*node) *stepCounter {
+	count := func(nodes []*node) map[string]int {
+		m := map[string]int{}
+		for _, nd := range nodes {
+			m[stepName(nd)]++
+		}
+		return m
+	}
+	total := count(as)
+	for k, v := range count(bs) {
+		if v > total[k] {
+			total[k] = v
+		}
+	}
+	return &stepCounter{total: total, seen: map[string]int{}}
+}
+
+// next returns the location step of nd, the next sibling in document
+// order.
+func (c *stepCounter) next(nd *node) string {
+	name := stepName(nd)
+	c.seen[name]++
+	if c.total[name] <= 1 {
+		return name
+	}
+	return fmt.Sprintf("%s[%d]", name, c.seen[name])
+}
+
+func stepName(nd *node) string {
+	switch nd.tok.(type) {
+	case xml.CharData:
+		return "text()"
+	case xml.Comment:
+		return "comment()"
+	}
+	return nd.name().Local
+}
@@ -0,0 +1,260 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	items := []MatchChildrenBy{{Parent: "items", Child: "item", KeyAttr: "id"}}
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		a, b string
+		want []string
+	}{{
+		desc: "equal",
+		a:    `<root a="1"><foo>bar</foo></root>`,
+		b:    `<root a="1"><foo>bar</foo></root>`,
+	}, {
+		desc: "attributes",
+		a:    `<root a="1" b="2"/>`,
+		b:    `<root a="2" c="3"/>`,
+		want: []string{
+			`/root/@a: "1" != "2"`,
+			`/root/@b: only in a: "2"`,
+			`/root/@c: only in b: "3"`,
+		},
+	}, {
+		desc: "attributes in a custom order",
+		n:    Normalizer{LessAttr: idFirst},
+		a:    `<root id="1" a="1" b="2"/>`,
+		b:    `<root a="2" c="3" id="1"/>`,
+		want: []string{
+			`/root/@a: "1" != "2"`,
+			`/root/@b: only in a: "2"`,
+			`/root/@c: only in b: "3"`,
+		},
+	}, {
+		desc: "attribute order",
+		n:    Normalizer{KeepAttrOrder: true},
+		a:    `<root id="1" a="1" b="2"/>`,
+		b:    `<root a="2" c="3" id="1"/>`,
+		want: []string{
+			`/root/@*: id a != a id`,
+			`/root/@a: "1" != "2"`,
+			`/root/@b: only in a: "2"`,
+			`/root/@c: only in b: "3"`,
+		},
+	}, {
+		desc: "text and elements",
+		a:    `<root><p>x</p><p>y</p><foo/></root>`,
+		b:    `<root><p>x</p><p>z</p><bar/><baz/></root>`,
+		want: []string{
+			`/root/p[2]/text(): "y" != "z"`,
+			`/root/foo: <foo> != <bar>`,
+			`/root/baz: only in b: <baz>`,
+		},
+	}, {
+		desc: "positional matching of repeated children",
+		a:    `<items><item id="6"/><item id="7" v="a"/></items>`,
+		b:    `<items><item id="7" v="b"/></items>`,
+		want: []string{
+			`/items/item[1]/@id: "6" != "7"`,
+			`/items/item[1]/@v: only in b: "b"`,
+			`/items/item[2]: only in a: <item id="7" v="a">`,
+		},
+	}, {
+		desc: "key-based matching of repeated children",
+		n:    Normalizer{MatchChildren: items},
+		a:    `<items><item id="6"/><item id="7" v="a"/></items>`,
+		b:    `<items><item id="7" v="b"/><item id="8"/></items>`,
+		want: []string{
+			`/items/item[@id='6']: only in a: <item id="6">`,
+			`/items/item[@id='7']/@v: "a" != "b"`,
+			`/items/item[@id='8']: only in b: <item id="8">`,
+		},
+	}, {
+		desc: "key-based matching by child element",
+		n:    Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "feed", Child: "entry", KeyElem: "id"}}},
+		a:    `<feed><entry><id>1</id><t>a</t></entry><entry><id>2</id></entry></feed>`,
+		b:    `<feed><entry><id>2</id></entry><entry><id> 1 </id><t>b</t></entry></feed>`,
+		want: []string{
+			`/feed/entry[id='1']/id/text(): "1" != " 1 "`,
+			`/feed/entry[id='1']/t/text(): "a" != "b"`,
+		},
+	}, {
+		desc: "key-based matching by several attributes",
+		n:    Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "s", Child: "c", KeyAttrs: []string{"k", "n"}}}},
+		a:    `<s><c k="a" n="1" v="x"/><c k="a" n="2"/></s>`,
+		b:    `<s><c k="a" n="2"/><c k="a" n="1" v="y"/></s>`,
+		want: []string{
+			`/s/c[@k='a' and @n='1']/@v: "x" != "y"`,
+		},
+	}, {
+		desc: "key-based matching ignores order",
+		n:    Normalizer{MatchChildren: items},
+		a:    `<items><item id="6"/><item id="7"/></items>`,
+		b:    `<items><item id="7"/><item id="6"/></items>`,
+	}, {
+		desc: "keyed children interleaved with other children",
+		n:    Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "a", Child: "b", KeyAttr: "c"}}},
+		a:    `<r><a><b c="1"/><x/></a></r>`,
+		b:    `<r><a><x/><b c="1"/></a></r>`,
+		want: []string{
+			`/r/a/b: <b> at 1 != <b> at 2`,
+		},
+	}, {
+		desc: "keyed children interleaved alike",
+		n:    Normalizer{MatchChildren: items},
+		a:    `<items><item id="6"/><x/><item id="7"/></items>`,
+		b:    `<items><item id="7"/><x/><item id="6"/></items>`,
+	}, {
+		desc: "element granularity",
+		n:    Normalizer{Granularity: GranularityElement},
+		a:    `<root><p a="1" b="2">x<i>y</i></p><q>z</q><r/></root>`,
+		b:    `<root><p a="2">x<i>w</i></p><q>z</q><r/><s/></root>`,
+		want: []string{
+			`/root/p: <p a="1" b="2"> "x" != <p a="2"> "x"`,
+			`/root/p/i: <i> "y" != <i> "w"`,
+			`/root/s: only in b: <s>`,
+		},
+	}, {
+		desc: "element granularity with equal content",
+		n:    Normalizer{Granularity: GranularityElement},
+		a:    `<root><p a="1">x<!--c--><i/></p></root>`,
+		b:    `<root><p a="1">x<!--c--><i/></p></root>`,
+	}, {
+		desc: "element granularity with keyed children",
+		n:    Normalizer{Granularity: GranularityElement, MatchChildren: items},
+		a:    `<items><item id="6" v="a"/><item id="7"/></items>`,
+		b:    `<items><item id="7"/><item id="6" v="b"/></items>`,
+		want: []string{
+			`/items/item[@id='6']: <item id="6" v="a"> != <item id="6" v="b">`,
+		},
+	}, {
+		desc: "text granularity",
+		n:    Normalizer{Granularity: GranularityText},
+		a:    `<root v="abcdef"><p>the quick brown fox</p><q>ä1</q></root>`,
+		b:    `<root v="abXYef"><p>the quick red fox</p><q>ä2</q></root>`,
+		want: []string{
+			`/root/@v: "cd" != "XY" at offset 2`,
+			`/root/p/text(): "brown" != "red" at offset 10`,
+			`/root/q/text(): "1" != "2" at offset 1`,
+		},
+	}, {
+		desc: "limited",
+		n:    Normalizer{MaxDifferences: 2},
+		a:    `<root a="1" b="2"><p>x</p><q/></root>`,
+		b:    `<root a="2" b="3"><p>y</p></root>`,
+		want: []string{
+			`/root/@a: "1" != "2"`,
+			`/root/@b: "2" != "3"`,
+		},
+	}, {
+		desc: "limited in children",
+		n:    Normalizer{MaxDifferences: 2},
+		a:    `<root><p>x</p><p>y</p><p>z</p></root>`,
+		b:    `<root><p>a</p><p>b</p><p>c</p></root>`,
+		want: []string{
+			`/root/p[1]/text(): "x" != "a"`,
+			`/root/p[2]/text(): "y" != "b"`,
+		},
+	}, {
+		desc: "limited with element granularity",
+		n:    Normalizer{MaxDifferences: 2, Granularity: GranularityElement},
+		a:    `<root><p a="1" b="1">x</p><q a="1" b="1"/><r a="1"/></root>`,
+		b:    `<root><p a="2" b="2">y</p><q a="2" b="2"/><r a="2"/></root>`,
+		want: []string{
+			`/root/p: <p a="1" b="1"> "x" != <p a="2" b="2"> "y"`,
+			`/root/q: <q a="1" b="1"> != <q a="2" b="2">`,
+		},
+	}, {
+		desc: "text granularity at start",
+		n:    Normalizer{Granularity: GranularityText},
+		a:    `<root>abc</root>`,
+		b:    `<root>xbc</root>`,
+		want: []string{
+			`/root/text(): "a" != "x"`,
+		},
+	}}
+
+	for _, tc := range testCases {
+		diffs, err := tc.n.Diff(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		var got []string
+		for _, d := range diffs {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got, tc.want)
+		}
+		equal, err := tc.n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: EqualXML: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if equal != (len(diffs) == 0) {
+			t.Errorf("%s: EqualXML: got %v, want %v", tc.desc, equal, len(diffs) == 0)
+		}
+	}
+}
+
+func TestDiffCauses(t *testing.T) {
+	testCases := []struct {
+		desc string
+		a, b string
+		want []Cause
+	}{
+		{desc: "whitespace in text", a: `<a>x  y</a>`, b: `<a> x y</a>`, want: []Cause{CauseWhitespace}},
+		{desc: "whitespace in attribute", a: `<a v="x y"/>`, b: `<a v="x  y"/>`, want: []Cause{CauseWhitespace}},
+		{desc: "whitespace node", a: "<a><b/>\n</a>", b: `<a><b/></a>`, want: []Cause{CauseWhitespace}},
+		{desc: "whitespace replaced", a: "<a>\n<b/></a>", b: "<a><!--c--><b/></a>", want: []Cause{CauseComment}},
+		{desc: "element namespace", a: `<a xmlns="x"/>`, b: `<a xmlns="y"/>`, want: []Cause{CauseNamespace}},
+		{desc: "attribute namespace", a: `<a xmlns:p="x" p:v="1"/>`, b: `<a v="1"/>`, want: []Cause{CauseNamespace, CauseNamespace}},
+		{desc: "comment", a: `<a><!--x--></a>`, b: `<a><!--y--></a>`, want: []Cause{CauseComment}},
+		{desc: "missing comment", a: `<a><!--x--></a>`, b: `<a/>`, want: []Cause{CauseComment}},
+		{desc: "text", a: `<a v="1">x</a>`, b: `<a v="2">y</a>`, want: []Cause{CauseText, CauseText}},
+		{desc: "structure", a: `<a v="1"><b/></a>`, b: `<a><c/>x</a>`, want: []Cause{CauseStructure, CauseStructure, CauseText}},
+	}
+	for _, tc := range testCases {
+		var n Normalizer
+		diffs, err := n.Diff(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		var got []Cause
+		for _, d := range diffs {
+			got = append(got, d.Cause)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+	counts := CountCauses([]Difference{{Cause: CauseText}, {Cause: CauseText}, {Cause: CauseComment}})
+	if want := map[Cause]int{CauseText: 2, CauseComment: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountCauses: got %v, want %v", counts, want)
+	}
+}
+
+func TestNormalizeMatchChildren(t *testing.T) {
+	n := Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "items", Child: "item", KeyAttr: "id"}}}
+	var b strings.Builder
+	in := `<items><item id="b"/><other/><item id="a"/><item/></items>`
+	if err := n.Normalize(&b, strings.NewReader(in)); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	want := `<items><item id="a"></item><other></other><item id="b"></item><item></item></items>`
+	if got := b.String(); got != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+var errMalformedDecl = errors.New("xmltest: malformed DTD declaration")
+
+// ParseDTD reads the element and attribute-list declarations of a DTD
+// from r, which holds either an external subset or a document type
+// declaration with an internal subset. Elements with element or EMPTY
+// content have whitespace between their children removed, attributes with
+// default or fixed values are added where missing, and values of
+// attributes of types other than CDATA, such as NMTOKENS, have their
+// whitespace collapsed. Parameter entities are not expanded, and names
+// match by local name.
+func ParseDTD(r io.Reader) (*Schema, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{elements: map[string]*elementDecl{}}
+	typ := func(name string) *contentType {
+		d, ok := s.elements[name]
+		if !ok {
+			d = &elementDecl{typ: &contentType{attrs: map[string]attrDecl{}}}
+			s.elements[name] = d
+		}
+		return d.typ
+	}
+	dtd := string(b)
+	for {
+		i := strings.Index(dtd, "<!")
+		if i < 0 {
+			return s, nil
+		}
+		dtd = dtd[i:]
+		if strings.HasPrefix(dtd, "<!--") {
+			j := strings.Index(dtd, "-->")
+			if j < 0 {
+				return s, nil
+			}
+			dtd = dtd[j+len("-->"):]
+			continue
+		}
+		var keyword string
+		switch {
+		case strings.HasPrefix(dtd, "<!ELEMENT"):
+			keyword = "<!ELEMENT"
+		case strings.HasPrefix(dtd, "<!ATTLIST"):
+			keyword = "<!ATTLIST"
+		default:
+			dtd = dtd[len("<!"):]
+			continue
+		}
+		var toks []string
+		toks, dtd = dtdTokens(dtd[len(keyword):])
+		if len(toks) < 2 {
+			return nil, errMalformedDecl
+		}
+		ct := typ(localName(toks[0]))
+		if keyword == "<!ELEMENT" {
+			// Mixed content starts with #PCDATA, and ANY allows it too.
+			ct.elementOnly = toks[1] == "EMPTY" || strings.HasPrefix(toks[1], "(") && !strings.Contains(toks[1], "#PCDATA")
+			continue
+		}
+		if err := parseAttDefs(ct, toks[1:]); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseAttDefs adds the attribute definitions toks of an attribute-list
+// declaration to ct.
+func parseAttDefs(ct *contentType, toks []string) error {
+	for len(toks) > 0 {
+		if len(toks) < 3 {
+			return errMalformedDecl
+		}
+		name, typ := localName(toks[0]), toks[1]
+		toks = toks[2:]
+		if typ == "NOTATION" && len(toks) > 0 {
+			toks = toks[1:]
+		}
+		var a attrDecl
+		if typ != "CDATA" {
+			a.space = spaceCollapse
+		}
+		if len(toks) > 0 && toks[0] == "#FIXED" {
+			toks = toks[1:]
+		}
+		if len(toks) == 0 {
+			return errMalformedDecl
+		}
+		if v := toks[0]; v != "#REQUIRED" && v != "#IMPLIED" {
+			if len(v) < 2 || v[0] != '"' && v[0] != '\'' {
+				return errMalformedDecl
+			}
+			a.def, a.hasDefault = v[1:len(v)-1], true
+		}
+		toks = toks[1:]
+		ct.attrs[name] = a
+	}
+	return nil
+}
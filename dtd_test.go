@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDTD = `<!DOCTYPE book [
+  <!-- <!ELEMENT chapter ANY> -->
+  <!ELEMENT book (title, (chapter|appendix)+)>
+  <!ELEMENT title (#PCDATA)>
+  <!ELEMENT chapter (#PCDATA|em)*>
+  <!ELEMENT br EMPTY>
+  <!ATTLIST book
+      lang   NMTOKEN  "en"
+      tags   NMTOKENS #IMPLIED
+      note   CDATA    #IMPLIED
+      status (draft|final) #FIXED 'final'>
+  <!ATTLIST br style NOTATION (a|b) #REQUIRED>
+]>`
+
+func TestDTD(t *testing.T) {
+	s, err := ParseDTD(strings.NewReader(testDTD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "defaults are added",
+		in:   `<book/>`,
+		want: `<book lang="en" status="final"></book>`,
+	}, {
+		desc: "whitespace in element content is removed",
+		in:   "<book>\n  <title> T </title>\n  <chapter> a <em> b </em> </chapter>\n  <br> </br>\n</book>",
+		want: `<book lang="en" status="final"><title> T </title><chapter> a <em> b </em> </chapter><br></br></book>`,
+	}, {
+		desc: "tokenized attributes are collapsed",
+		in:   "<book tags=\" a \n b \" note=\" x  y \" lang=\" de \"/>",
+		want: `<book lang="de" note=" x  y " status="final" tags="a b"></book>`,
+	}}
+
+	n := New(WithSchema(s))
+	for _, tc := range testCases {
+		var b strings.Builder
+		if err := n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestParseDTDErrors(t *testing.T) {
+	for _, in := range []string{
+		`<!ELEMENT a>`,
+		`<!ATTLIST a b CDATA>`,
+		`<!ATTLIST a b CDATA default>`,
+	} {
+		if _, err := ParseDTD(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: got nil error", in)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// dumpContext is the number of unchanged lines around changes in the tree
+// diffs of failure messages.
+const dumpContext = 2
+
+// Dump writes the normalized XML content of r to w as a tree, one node per
+// line and indented by depth. An element is written as "element name",
+// followed by a line "@name value" for each of its attributes, and
+// character data and comments as "text value" and "comment value". Names
+// are in Clark notation and values are quoted, so that each node fits on
+// its line and dumps diff well line by line.
+func (n *Normalizer) Dump(w io.Writer, r io.Reader) error {
+	lines, err := n.dumpLines(r)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for _, l := range lines {
+		bw.WriteString(l)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// dumpLines returns the lines of the dump of r.
+func (n *Normalizer) dumpLines(r io.Reader) ([]string, error) {
+	toks, err := n.readTokens(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		lines []string
+		depth int
+	)
+	for _, t := range toks {
+		indent := strings.Repeat("  ", depth)
+		switch t := t.(type) {
+		case xml.StartElement:
+			lines = append(lines, indent+"element "+clarkName(t.Name))
+			for _, a := range t.Attr {
+				lines = append(lines, indent+"  @"+clarkName(a.Name)+" "+strconv.Quote(a.Value))
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			lines = append(lines, indent+"text "+strconv.Quote(string(t)))
+		case xml.Comment:
+			lines = append(lines, indent+"comment "+strconv.Quote(string(t)))
+		}
+	}
+	return lines, nil
+}
+
+// differenceError returns an error that lists the differences between the
+// documents a and b, followed by the changed lines of their dumps, or nil
+// if they are equal.
+func (n *Normalizer) differenceError(msg string, a, b []byte) error {
+	diffs, err := n.Diff(bytes.NewReader(a), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	derr := diffError(msg, diffs)
+	if derr == nil {
+		return nil
+	}
+	la, erra := n.dumpLines(bytes.NewReader(a))
+	lb, errb := n.dumpLines(bytes.NewReader(b))
+	if erra != nil || errb != nil {
+		return derr
+	}
+	var sb strings.Builder
+	sb.WriteString(derr.Error() + "\ntree diff (-a +b):")
+	ops := diffLines(la, lb)
+	last := -1
+	for i, op := range ops {
+		if !nearChange(ops, i) {
+			continue
+		}
+		if i > last+1 {
+			sb.WriteString("\n\t...")
+		}
+		sb.WriteString("\n\t" + string(op.kind) + " " + op.line)
+		last = i
+	}
+	return errors.New(sb.String())
+}
+
+// nearChange reports whether ops[i] is at most dumpContext operations away
+// from a changed line.
+func nearChange(ops []lineOp, i int) bool {
+	for j := i - dumpContext; j <= i+dumpContext; j++ {
+		if j >= 0 && j < len(ops) && ops[j].kind != ' ' {
+			return true
+		}
+	}
+	return false
+}
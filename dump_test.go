@@ -0,0 +1,80 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+		want string
+	}{{
+		desc: "empty element",
+		in:   `<a/>`,
+		want: "element a\n",
+	}, {
+		desc: "tree",
+		in:   `<a xmlns:p="urn:p" z="1" p:y="2"><b>x&#10;y</b><!-- c --></a>`,
+		want: "element a\n" +
+			"  @z \"1\"\n" +
+			"  @{urn:p}y \"2\"\n" +
+			"  element b\n" +
+			"    text \"x\\ny\"\n" +
+			"  comment \" c \"\n",
+	}, {
+		desc: "normalized",
+		n:    Normalizer{OmitWhitespace: true, OmitComments: true},
+		in:   "<a>\n <!-- c -->\n <b/>\n</a>",
+		want: "element a\n  element b\n",
+	}}
+	for _, tc := range testCases {
+		var sb strings.Builder
+		if err := tc.n.Dump(&sb, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		if got := sb.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestDifferenceError(t *testing.T) {
+	var n Normalizer
+	a := `<r><a/><b/><c/><d/><e/><f/><g>1</g><h/><i/><j/><k/><l/><m/><n>1</n><o/></r>`
+	b := `<r><a/><b/><c/><d/><e/><f/><g>2</g><h/><i/><j/><k/><l/><m/><n>2</n><o/></r>`
+	err := n.differenceError("differs", []byte(a), []byte(b))
+	if err == nil {
+		t.Fatal("got nil error, want error")
+	}
+	want := "xmltest: differs:\n" +
+		"\t/r/g/text(): \"1\" != \"2\"\n" +
+		"\t/r/n/text(): \"1\" != \"2\"\n" +
+		"tree diff (-a +b):\n" +
+		"\t...\n" +
+		"\t    element f\n" +
+		"\t    element g\n" +
+		"\t-     text \"1\"\n" +
+		"\t+     text \"2\"\n" +
+		"\t    element h\n" +
+		"\t    element i\n" +
+		"\t...\n" +
+		"\t    element m\n" +
+		"\t    element n\n" +
+		"\t-     text \"1\"\n" +
+		"\t+     text \"2\"\n" +
+		"\t    element o"
+	if got := err.Error(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if err := n.differenceError("differs", []byte(a), []byte(a)); err != nil {
+		t.Errorf("equal: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SyntaxError reports malformed input. Line and Column locate the end of
+// the offending token if the input is read by an xml.Decoder, and are zero
+// otherwise.
+type SyntaxError struct {
+	Line, Column int
+	// Err is the underlying error, often an *xml.SyntaxError.
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	msg := e.Err.Error()
+	var serr *xml.SyntaxError
+	if errors.As(e.Err, &serr) {
+		msg = serr.Msg
+	}
+	return fmt.Sprintf("xmltest: syntax error at line %d, column %d: %s", e.Line, e.Column, msg)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// WriteError reports a failure to write the output.
+type WriteError struct {
+	Err error
+}
+
+func (e *WriteError) Error() string { return "xmltest: write failed: " + e.Err.Error() }
+
+func (e *WriteError) Unwrap() error { return e.Err }
+
+// LimitError reports input that exceeds a limit configured on the
+// Normalizer, such as MaxDepth.
+type LimitError struct {
+	// Limit is the name of the Normalizer field that sets the limit.
+	Limit string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("xmltest: input exceeds %s of %d", e.Limit, e.Max)
+}
+
+// IdempotenceError reports that normalizing the output of Normalize again
+// yields a different output.
+type IdempotenceError struct {
+	Once, Twice []byte
+}
+
+func (e *IdempotenceError) Error() string {
+	return fmt.Sprintf("xmltest: normalization is not idempotent:\nonce:  %s\ntwice: %s", e.Once, e.Twice)
+}
+
+// syntaxError wraps err in a *SyntaxError at the current input position.
+func (tn *TokenNormalizer) syntaxError(err error) error {
+	line, col := tn.d.InputPos()
+	var serr *xml.SyntaxError
+	if errors.As(err, &serr) && serr.Line > 0 {
+		line = serr.Line
+	}
+	return &SyntaxError{Line: line, Column: col, Err: err}
+}
+
+// errWriter records the first error of the underlying writer, to tell
+// write failures from encoding failures.
+type errWriter struct {
+	w   io.Writer
+	err error
+	// n counts the bytes written.
+	n int64
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+// wrap returns err as a *WriteError if the underlying writer failed.
+func (w *errWriter) wrap(err error) error {
+	if err != nil && w.err != nil {
+		return &WriteError{Err: w.err}
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSyntaxError(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		n            Normalizer
+		in           string
+		line, column int
+	}{{
+		desc:   "mismatched end tag",
+		in:     "<root>\n  <a></b>\n</root>",
+		line:   2,
+		column: 10,
+	}, {
+		desc:   "multiple root elements",
+		n:      Normalizer{Rules: RulesV2},
+		in:     "<a/>\n<b/>",
+		line:   2,
+		column: 5,
+	}}
+
+	for _, tc := range testCases {
+		err := tc.n.Normalize(io.Discard, strings.NewReader(tc.in))
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("%s: got err %v, want *SyntaxError", tc.desc, err)
+			continue
+		}
+		if serr.Line != tc.line || serr.Column != tc.column {
+			t.Errorf("%s: got position %d:%d, want %d:%d", tc.desc, serr.Line, serr.Column, tc.line, tc.column)
+		}
+	}
+
+	var n Normalizer
+	err := n.Normalize(io.Discard, strings.NewReader("<root></foo>"))
+	var xerr *xml.SyntaxError
+	if !errors.As(err, &xerr) {
+		t.Errorf("got err %v, want wrapped *xml.SyntaxError", err)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	var n Normalizer
+	err := n.Normalize(&errorWriter{}, strings.NewReader("<root/>"))
+	var werr *WriteError
+	if !errors.As(err, &werr) {
+		t.Errorf("got err %v, want *WriteError", err)
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	n := Normalizer{MaxDepth: 2}
+	if err := n.Normalize(io.Discard, strings.NewReader("<a><b/></a>")); err != nil {
+		t.Errorf("depth 2: got err %v, want nil", err)
+	}
+	err := n.Normalize(io.Discard, strings.NewReader("<a><b><c/></b></a>"))
+	var lerr *LimitError
+	if !errors.As(err, &lerr) || lerr.Limit != "MaxDepth" || lerr.Max != 2 {
+		t.Errorf("depth 3: got err %v, want *LimitError for MaxDepth 2", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type eventKind int
+
+const (
+	startEvent eventKind = iota
+	endEvent
+	textEvent
+	commentEvent
+	anyEvents
+)
+
+// Event is an expected event of a token stream, for use with ExpectEvents.
+type Event struct {
+	kind eventKind
+	// name is the element name in Clark notation, or "*".
+	name string
+	// text is the character data or comment, or "*".
+	text string
+}
+
+// StartEvent expects the start of an element. The name is in Clark
+// notation, a local name matches elements in any namespace, and "*"
+// matches any element.
+func StartEvent(name string) Event { return Event{kind: startEvent, name: name} }
+
+// EndEvent expects the end of an element, named like in StartEvent.
+func EndEvent(name string) Event { return Event{kind: endEvent, name: name} }
+
+// TextEvent expects character data, or any character data if s is "*".
+func TextEvent(s string) Event { return Event{kind: textEvent, text: s} }
+
+// CommentEvent expects a comment, or any comment if s is "*".
+func CommentEvent(s string) Event { return Event{kind: commentEvent, text: s} }
+
+// AnyEvents matches any sequence of zero or more events.
+func AnyEvents() Event { return Event{kind: anyEvents} }
+
+func (e Event) String() string {
+	switch e.kind {
+	case startEvent:
+		return "<" + e.name + ">"
+	case endEvent:
+		return "</" + e.name + ">"
+	case textEvent:
+		return "text " + strconv.Quote(e.text)
+	case commentEvent:
+		return "<!--" + e.text + "-->"
+	}
+	return "..."
+}
+
+// match reports whether the normalized token t matches e.
+func (e Event) match(t xml.Token) bool {
+	switch t := t.(type) {
+	case xml.StartElement:
+		return e.kind == startEvent && e.matchName(t.Name)
+	case xml.EndElement:
+		return e.kind == endEvent && e.matchName(t.Name)
+	case xml.CharData:
+		return e.kind == textEvent && (e.text == "*" || e.text == string(t))
+	case xml.Comment:
+		return e.kind == commentEvent && (e.text == "*" || e.text == string(t))
+	}
+	return false
+}
+
+func (e Event) matchName(name xml.Name) bool {
+	return e.name == "*" || matchName([]xml.Name{parseName(e.name)}, name)
+}
+
+// ExpectEvents checks that the normalized token stream of r matches the
+// events. It returns an error that reports the first token that does not
+// match, or the first missing event, or nil if the stream matches.
+func (n *Normalizer) ExpectEvents(r io.Reader, events ...Event) error {
+	toks, err := n.readTokens(r)
+	if err != nil {
+		return err
+	}
+	m := &eventMatcher{toks: toks, events: events, failed: map[[2]int]bool{}}
+	if m.match(0, 0) {
+		return nil
+	}
+	i, j := m.farTok, m.farEvent
+	switch {
+	case i == len(toks):
+		return fmt.Errorf("xmltest: missing event %d %v at end of input", j+1, events[j])
+	case j == len(events):
+		return fmt.Errorf("xmltest: token %d: unexpected %s after last event", i+1, formatToken(toks[i]))
+	}
+	return fmt.Errorf("xmltest: token %d: got %s, want event %d %v", i+1, formatToken(toks[i]), j+1, events[j])
+}
+
+// eventMatcher matches tokens against events, backtracking at AnyEvents.
+type eventMatcher struct {
+	toks   []xml.Token
+	events []Event
+	// failed memoizes the positions that do not match.
+	failed map[[2]int]bool
+	// farTok and farEvent locate the failure furthest into the tokens.
+	farTok, farEvent int
+}
+
+func (m *eventMatcher) match(i, j int) bool {
+	if m.failed[[2]int{i, j}] {
+		return false
+	}
+	ok := false
+	switch {
+	case j < len(m.events) && m.events[j].kind == anyEvents:
+		ok = m.match(i, j+1) || i < len(m.toks) && m.match(i+1, j)
+	case i == len(m.toks) || j == len(m.events):
+		ok = i == len(m.toks) && j == len(m.events)
+	default:
+		ok = m.events[j].match(m.toks[i]) && m.match(i+1, j+1)
+	}
+	if !ok {
+		m.failed[[2]int{i, j}] = true
+		if i > m.farTok || i == m.farTok && j > m.farEvent {
+			m.farTok, m.farEvent = i, j
+		}
+	}
+	return ok
+}
@@ -0,0 +1,59 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpectEvents(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		in      string
+		events  []Event
+		wantErr string
+	}{{
+		desc:   "exact",
+		in:     `<root xmlns="urn:x">x<!--c--></root>`,
+		events: []Event{StartEvent("{urn:x}root"), TextEvent("x"), CommentEvent("c"), EndEvent("root")},
+	}, {
+		desc:   "wildcards",
+		in:     `<root><a>x</a><b/><c>y</c></root>`,
+		events: []Event{StartEvent("root"), AnyEvents(), StartEvent("*"), TextEvent("*"), EndEvent("c"), EndEvent("root")},
+	}, {
+		desc:    "out of order",
+		in:      `<root><b/><a/></root>`,
+		events:  []Event{StartEvent("root"), StartEvent("a"), EndEvent("a"), StartEvent("b"), EndEvent("b"), EndEvent("root")},
+		wantErr: "xmltest: token 2: got <b>, want event 2 <a>",
+	}, {
+		desc:    "missing",
+		in:      `<root>x</root>`,
+		events:  []Event{StartEvent("root"), TextEvent("x"), StartEvent("a"), AnyEvents()},
+		wantErr: "xmltest: token 3: got </root>, want event 3 <a>",
+	}, {
+		desc:    "missing at end",
+		in:      `<root/>`,
+		events:  []Event{StartEvent("root"), EndEvent("root"), CommentEvent("*")},
+		wantErr: "xmltest: missing event 3 <!--*--> at end of input",
+	}, {
+		desc:    "unexpected",
+		in:      `<root>x</root>`,
+		events:  []Event{StartEvent("root"), TextEvent("x")},
+		wantErr: "xmltest: token 3: unexpected </root> after last event",
+	}}
+
+	for _, tc := range testCases {
+		var n Normalizer
+		err := n.ExpectEvents(strings.NewReader(tc.in), tc.events...)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.wantErr {
+			t.Errorf("%s: got err %q, want %q", tc.desc, got, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// ExpectText constrains the format of text or attribute values. Path
+// selects elements, whose character data must match the regular
+// expression Pattern, or attributes, whose values must match it.
+//
+// A path is a list of element names separated by slashes. An absolute
+// path such as /root/version matches from the root element, a relative
+// path such as item/name matches the innermost elements. A step is a
+// local name, which matches elements in any namespace, a name in Clark
+// notation such as {urn:x}item, or * for any element. The last step may
+// select an attribute, as in item/@id. Paths match normalized names, and
+// values are checked after normalization.
+type ExpectText struct {
+	Path    string
+	Pattern string
+}
+
+// ExpectError reports a value that does not match its ExpectText.
+type ExpectError struct {
+	// Path locates the value in Clark notation, such as /root/@id.
+	Path   string
+	Value  string
+	Expect ExpectText
+}
+
+func (e *ExpectError) Error() string {
+	return fmt.Sprintf("xmltest: %s: %q does not match %s", e.Path, e.Value, e.Expect.Pattern)
+}
+
+// expectation is a compiled ExpectText.
+type expectation struct {
+	ExpectText
+	path pathPattern
+	re   *regexp.Regexp
+}
+
+// compileExpect compiles the ExpectTexts of n.
+func (n *Normalizer) compileExpect() ([]expectation, error) {
+	exps := make([]expectation, len(n.Expect))
+	for i, e := range n.Expect {
+		p, err := parsePath(e.Path)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("xmltest: ExpectText %s: %v", e.Path, err)
+		}
+		exps[i] = expectation{e, p, re}
+	}
+	return exps, nil
+}
+
+// checkAttrs checks the attribute values of start, the element at the end
+// of tn.path.
+func (tn *TokenNormalizer) checkAttrs(start xml.StartElement) error {
+	for _, e := range tn.expects {
+		for _, a := range start.Attr {
+			if e.path.matchAttr(tn.path, a.Name) && !e.re.MatchString(a.Value) {
+				return &ExpectError{Path: formatPath(tn.path) + "/@" + clarkName(a.Name), Value: a.Value, Expect: e.ExpectText}
+			}
+		}
+	}
+	return nil
+}
+
+// checkText checks the character data of the element at the end of
+// tn.path.
+func (tn *TokenNormalizer) checkText() error {
+	text := string(tn.texts[len(tn.texts)-1])
+	for _, e := range tn.expects {
+		if !e.path.hasAttr && e.path.matchElement(tn.path) && !e.re.MatchString(text) {
+			return &ExpectError{Path: formatPath(tn.path), Value: text, Expect: e.ExpectText}
+		}
+	}
+	return nil
+}
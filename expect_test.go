@@ -0,0 +1,94 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestExpect(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		opts     []Option
+		in       string
+		wantPath string
+	}{{
+		desc: "text matches",
+		opts: []Option{Expect("/root/version", `^\d+\.\d+$`)},
+		in:   `<root><version>1.2</version></root>`,
+	}, {
+		desc:     "text mismatches",
+		opts:     []Option{Expect("/root/version", `^\d+\.\d+$`)},
+		in:       `<root><version>1.2.3</version></root>`,
+		wantPath: "/root/version",
+	}, {
+		desc: "text is normalized",
+		opts: []Option{OmitComments(), Expect("version", `^\d+\.\d+$`)},
+		in:   `<root><version>1<!-- c -->.2</version></root>`,
+	}, {
+		desc:     "text of children is excluded",
+		opts:     []Option{Expect("a", `^x$`)},
+		in:       `<a>x<b>y</b>z</a>`,
+		wantPath: "/a",
+	}, {
+		desc:     "relative path",
+		opts:     []Option{Expect("item/id", `^[0-9]+$`)},
+		in:       `<root><item><id>1</id></item><item><id>b</id></item></root>`,
+		wantPath: "/root/item/id",
+	}, {
+		desc:     "attribute",
+		opts:     []Option{Expect("item/@id", `^[0-9]+$`)},
+		in:       `<root><item id="1"/><item id="x"/></root>`,
+		wantPath: "/root/item/@id",
+	}, {
+		desc: "missing attribute",
+		opts: []Option{Expect("item/@id", `^[0-9]+$`)},
+		in:   `<root><item/></root>`,
+	}, {
+		desc:     "namespace",
+		opts:     []Option{Expect("{urn:x}v", `^a$`)},
+		in:       `<root xmlns:x="urn:x"><v>b</v><x:v>c</x:v></root>`,
+		wantPath: "/root/{urn:x}v",
+	}}
+	for _, tc := range testCases {
+		var sb strings.Builder
+		err := Normalize(&sb, strings.NewReader(tc.in), tc.opts...)
+		var eerr *ExpectError
+		if tc.wantPath == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.desc, err)
+			}
+			continue
+		}
+		if !errors.As(err, &eerr) {
+			t.Errorf("%s: got error %v, want *ExpectError", tc.desc, err)
+			continue
+		}
+		if eerr.Path != tc.wantPath {
+			t.Errorf("%s: got path %s, want %s", tc.desc, eerr.Path, tc.wantPath)
+		}
+	}
+}
+
+func TestExpectEqualXML(t *testing.T) {
+	n := New(Expect("/root/version", `^\d+$`))
+	_, err := n.EqualXML(strings.NewReader(`<root><version>1</version></root>`), strings.NewReader(`<root><version>v1</version></root>`))
+	var eerr *ExpectError
+	if !errors.As(err, &eerr) || eerr.Value != "v1" {
+		t.Errorf("got error %v, want *ExpectError for v1", err)
+	}
+}
+
+func TestExpectInvalid(t *testing.T) {
+	for _, e := range []ExpectText{{"/a", `(`}, {"a//b", `.`}} {
+		n := &Normalizer{Expect: []ExpectText{e}}
+		if err := n.Normalize(io.Discard, strings.NewReader(`<a/>`)); err == nil {
+			t.Errorf("%v: got nil error, want error", e)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"io"
+)
+
+// Suggestion is an option that makes documents equal, as reported by
+// ExplainInequality.
+type Suggestion struct {
+	// Name is the option as Go code, such as OmitWhitespace().
+	Name   string
+	Option Option
+}
+
+// suggestions are the options that ExplainInequality tries, in order, each
+// with a func that reports whether a Normalizer already applies it.
+var suggestions = []struct {
+	Suggestion
+	set func(*Normalizer) bool
+}{
+	{Suggestion{"OmitWhitespace()", OmitWhitespace()}, func(n *Normalizer) bool { return n.OmitWhitespace }},
+	{Suggestion{"OmitComments()", OmitComments()}, func(n *Normalizer) bool { return n.OmitComments }},
+	{Suggestion{"SortElements()", SortElements()}, func(n *Normalizer) bool { return n.SortElements }},
+	{Suggestion{"CaseInsensitiveNames()", CaseInsensitiveNames()}, func(n *Normalizer) bool { return n.CaseInsensitiveNames }},
+	{Suggestion{"NumberTolerance(0, 0)", NumberTolerance(0, 0)}, func(n *Normalizer) bool { return n.NumberTolerance != nil }},
+	{Suggestion{"CompareTimestamps(0)", CompareTimestamps(0)}, func(n *Normalizer) bool { return n.CompareTimestamps }},
+	{Suggestion{"NormalizeBooleans()", NormalizeBooleans()}, func(n *Normalizer) bool { return n.NormalizeBooleans }},
+	{Suggestion{"NormalizeLang()", NormalizeLang()}, func(n *Normalizer) bool { return n.NormalizeLang }},
+}
+
+// ExplainInequality reports the options that would each make a and b
+// equal if added to n, or none if a and b are equal already or no single
+// option suffices. It tries OmitWhitespace, OmitComments, SortElements,
+// CaseInsensitiveNames, NumberTolerance with zero tolerances,
+// CompareTimestamps with zero tolerance, NormalizeBooleans and
+// NormalizeLang, unless n applies them already.
+func (n *Normalizer) ExplainInequality(a, b io.Reader) ([]Suggestion, error) {
+	da, err := io.ReadAll(a)
+	if err != nil {
+		return nil, err
+	}
+	db, err := io.ReadAll(b)
+	if err != nil {
+		return nil, err
+	}
+	if equal, err := n.EqualXML(bytes.NewReader(da), bytes.NewReader(db)); err != nil || equal {
+		return nil, err
+	}
+	var found []Suggestion
+	for _, s := range suggestions {
+		if s.set(n) {
+			continue
+		}
+		m := *n
+		// The retries must neither trace nor fill the cache.
+		m.Trace, m.OnToken, m.OnProgress, m.Cache = nil, nil, nil, nil
+		s.Option(&m)
+		if equal, err := m.EqualXML(bytes.NewReader(da), bytes.NewReader(db)); err == nil && equal {
+			found = append(found, s.Suggestion)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExplainInequality(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		a, b string
+		want []string
+	}{{
+		desc: "equal",
+		a:    `<a x="1" y="2"/>`,
+		b:    `<a y="2" x="1"/>`,
+	}, {
+		desc: "whitespace",
+		a:    "<a>\n  <b/>\n</a>",
+		b:    `<a><b/></a>`,
+		want: []string{"OmitWhitespace()"},
+	}, {
+		desc: "comments",
+		a:    `<a><!-- c --><b/></a>`,
+		b:    `<a><b/></a>`,
+		want: []string{"OmitComments()"},
+	}, {
+		desc: "order",
+		a:    `<a><b/><c/></a>`,
+		b:    `<a><c/><b/></a>`,
+		want: []string{"SortElements()"},
+	}, {
+		desc: "several options apply",
+		a:    `<a>1.0</a>`,
+		b:    `<A>1</A>`,
+	}, {
+		desc: "numbers and booleans",
+		a:    `<a n="1.0" b="1"/>`,
+		b:    `<a n="1" b="1"/>`,
+		want: []string{"NumberTolerance(0, 0)"},
+	}, {
+		desc: "options already set",
+		n:    Normalizer{OmitWhitespace: true},
+		a:    "<a>\n  <!-- c --><b/>\n</a>",
+		b:    `<a><b/></a>`,
+		want: []string{"OmitComments()"},
+	}, {
+		desc: "no single option",
+		a:    "<a>\n<!-- c --><b/></a>",
+		b:    `<a><b/></a>`,
+	}}
+	for _, tc := range testCases {
+		sugs, err := tc.n.ExplainInequality(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		var got []string
+		for _, s := range sugs {
+			got = append(got, s.Name)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.desc, got, tc.want)
+		}
+		for _, s := range sugs {
+			m := tc.n
+			s.Option(&m)
+			if equal, err := m.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b)); err != nil || !equal {
+				t.Errorf("%s: %s: got %v, %v, want true", tc.desc, s.Name, equal, err)
+			}
+		}
+	}
+	var n Normalizer
+	if _, err := n.ExplainInequality(strings.NewReader(`<a>`), strings.NewReader(`<a/>`)); err == nil {
+		t.Errorf("malformed: got nil error, want error")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errSelected stops reading once the selected element is complete.
+var errSelected = errors.New("xmltest: element selected")
+
+// DecodeAt unmarshals the first element of the document r that path
+// selects into v, as xml.Unmarshal does, using a Normalizer configured by
+// opts. See Normalizer.DecodeAt.
+func DecodeAt(r io.Reader, path string, v interface{}, opts ...Option) error {
+	return New(opts...).DecodeAt(r, path, v)
+}
+
+// Extract writes the normalized elements of the document r that path
+// selects to w, in document order, so that the interesting part of a huge
+// document can be compared or stored as a fixture. The document is
+// streamed rather than read into memory, unless elements are rearranged.
+// Path is as described for ExpectText and must select elements. Selected
+// elements within a selected element are written as part of it.
+func (n *Normalizer) Extract(r io.Reader, path string, w io.Writer) error {
+	ew := &errWriter{w: w}
+	bw, release := newWriter(ew)
+	defer release()
+	tw := newTokenWriter(bw, n.Format)
+	err := n.selectElements(r, path, func(t xml.Token, depth int) error {
+		return ew.wrap(tw.writeToken(t))
+	})
+	if err != nil {
+		return err
+	}
+	return ew.wrap(tw.close())
+}
+
+// Split calls f with each normalized element of the document r that path
+// selects, as an independent document and in document order, so that
+// records of large exports can be compared one by one. It stops at the
+// first error of f and returns it. The slice passed to f is only valid
+// during the call. Path is as for Extract.
+func (n *Normalizer) Split(r io.Reader, path string, f func(doc []byte) error) error {
+	var (
+		buf bytes.Buffer
+		bw  = bufio.NewWriter(&buf)
+		tw  *tokenWriter
+	)
+	return n.selectElements(r, path, func(t xml.Token, depth int) error {
+		if tw == nil {
+			buf.Reset()
+			bw.Reset(&buf)
+			tw = newTokenWriter(bw, n.Format)
+		}
+		if err := tw.writeToken(t); err != nil {
+			return err
+		}
+		if depth > 0 {
+			return nil
+		}
+		if err := tw.close(); err != nil {
+			return err
+		}
+		tw = nil
+		return f(buf.Bytes())
+	})
+}
+
+// DecodeAt unmarshals the first normalized element of the document r that
+// path selects into v, as xml.Unmarshal does, so that tests can inspect a
+// typed piece of a large document without declaring types for all of it.
+// The element carries namespace URIs rather than prefixes in its names,
+// and the rest of the document is not read. Errors of unmarshaling name
+// the path. Path is as for Extract.
+func (n *Normalizer) DecodeAt(r io.Reader, path string, v interface{}) error {
+	var toks []xml.Token
+	err := n.selectElements(r, path, func(t xml.Token, depth int) error {
+		toks = append(toks, t)
+		if depth == 0 {
+			return errSelected
+		}
+		return nil
+	})
+	switch {
+	case err != nil && err != errSelected:
+		return err
+	case len(toks) == 0:
+		return fmt.Errorf("xmltest: path %s selects no element", path)
+	}
+	if err := xml.NewTokenDecoder(Replay(toks)).Decode(v); err != nil {
+		return fmt.Errorf("xmltest: %s: %v", path, err)
+	}
+	return nil
+}
+
+// selectElements calls visit with the normalized tokens of the elements of
+// the document r that path selects, and the number of elements of the
+// selection that are open after each token.
+func (n *Normalizer) selectElements(r io.Reader, path string, visit func(t xml.Token, depth int) error) error {
+	p, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if p.hasAttr {
+		return fmt.Errorf("xmltest: path %s selects an attribute", path)
+	}
+	r, err = n.decompress(r)
+	if err != nil {
+		return err
+	}
+	d, release := n.newDecoder(r)
+	defer release()
+	tn := n.getTokenNormalizer(d)
+	defer putTokenNormalizer(tn)
+	var (
+		names []xml.Name
+		depth int
+	)
+	for {
+		t, err := tn.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		selected := depth > 0
+		switch t := t.(type) {
+		case xml.StartElement:
+			names = append(names, t.Name)
+			if selected || p.matchElement(names) {
+				selected = true
+				depth++
+			}
+		case xml.EndElement:
+			names = names[:len(names)-1]
+			if selected {
+				depth--
+			}
+		}
+		if selected {
+			if err := visit(t, depth); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	const doc = `<feed xmlns="urn:f"><meta><id>1</id></meta>` +
+		`<entry b="2" a="1"><id>x</id><!-- c --></entry>` +
+		`<entry><id>y</id><entry><id>z</id></entry></entry></feed>`
+	testCases := []struct {
+		desc    string
+		n       Normalizer
+		path    string
+		want    string
+		wantErr bool
+	}{{
+		desc: "absolute path",
+		path: "/feed/meta",
+		want: `<_:meta xmlns:_="urn:f"><_:id>1</_:id></_:meta>`,
+	}, {
+		desc: "relative path selects all matches, outermost only",
+		n:    Normalizer{OmitComments: true},
+		path: "entry",
+		want: `<_:entry xmlns:_="urn:f" a="1" b="2"><_:id>x</_:id></_:entry>` +
+			`<_:entry xmlns:_="urn:f"><_:id>y</_:id><_:entry><_:id>z</_:id></_:entry></_:entry>`,
+	}, {
+		desc: "names in Clark notation",
+		path: "{urn:f}meta/{urn:f}id",
+		want: `<_:id xmlns:_="urn:f">1</_:id>`,
+	}, {
+		desc: "no match",
+		path: "/entry",
+	}, {
+		desc:    "attribute",
+		path:    "entry/@a",
+		wantErr: true,
+	}, {
+		desc:    "invalid path",
+		path:    "",
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := tc.n.Extract(strings.NewReader(doc), tc.path, &b)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	const doc = `<export xmlns="urn:e"><record id="1"><v>a</v></record>` +
+		`<record id="2"/><other/><record id="3"/></export>`
+	var got []string
+	err := new(Normalizer).Split(strings.NewReader(doc), "record", func(doc []byte) error {
+		got = append(got, string(doc))
+		return nil
+	})
+	want := []string{
+		`<_:record xmlns:_="urn:e" id="1"><_:v>a</_:v></_:record>`,
+		`<_:record xmlns:_="urn:e" id="2"></_:record>`,
+		`<_:record xmlns:_="urn:e" id="3"></_:record>`,
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, %v, want %q", got, err, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = new(Normalizer).Split(strings.NewReader(doc), "record", func([]byte) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("stopping: got %v after %d calls, want %v after 1", err, calls, stop)
+	}
+
+	err = new(Normalizer).Split(strings.NewReader(`<export><record>`), "record", func([]byte) error { return nil })
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Errorf("malformed: got %v, want *SyntaxError", err)
+	}
+}
+
+func TestDecodeAt(t *testing.T) {
+	const doc = `<env xmlns="urn:e"><head><id>7</id></head>` +
+		`<body><order id=" 42 "><item>a</item><item>b</item></order></body></env>`
+	type order struct {
+		ID    int      `xml:"id,attr"`
+		Items []string `xml:"urn:e item"`
+	}
+	var got order
+	if err := DecodeAt(strings.NewReader(doc), "/env/body/order", &got, CollapseAttr("id")); err != nil {
+		t.Fatal(err)
+	}
+	if want := (order{ID: 42, Items: []string{"a", "b"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	var id int
+	if err := DecodeAt(strings.NewReader(doc), "id", &id); err != nil || id != 7 {
+		t.Errorf("got %d, %v, want 7, nil", id, err)
+	}
+
+	testCases := []struct {
+		desc    string
+		doc     string
+		path    string
+		wantErr string
+	}{{
+		desc:    "no element",
+		doc:     doc,
+		path:    "/env/tail",
+		wantErr: "xmltest: path /env/tail selects no element",
+	}, {
+		desc:    "unmarshal error",
+		doc:     doc,
+		path:    "item",
+		wantErr: `xmltest: item: strconv.ParseInt: parsing "a": invalid syntax`,
+	}, {
+		// The rest of the document is not read.
+		desc: "malformed after selection",
+		doc:  `<env><id>1</id><oops></env>`,
+		path: "id",
+	}}
+	for _, tc := range testCases {
+		var v int
+		err := DecodeAt(strings.NewReader(tc.doc), tc.path, &v)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.wantErr {
+			t.Errorf("%s: got error %q, want %q", tc.desc, got, tc.wantErr)
+		}
+	}
+}
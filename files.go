@@ -0,0 +1,120 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileDiff describes a file that differs between two directory trees.
+type FileDiff struct {
+	// Path is the slash-separated path of the file relative to the roots
+	// of the trees.
+	Path string
+	// OnlyInA and OnlyInB report whether the file is missing in the second
+	// or first tree.
+	OnlyInA, OnlyInB bool
+	// Differences holds the differences between the normalized XML
+	// contents of the files present in both trees.
+	Differences []Difference
+}
+
+func (d FileDiff) String() string {
+	switch {
+	case d.OnlyInA:
+		return d.Path + ": only in a"
+	case d.OnlyInB:
+		return d.Path + ": only in b"
+	}
+	return fmt.Sprintf("%s: %d differences", d.Path, len(d.Differences))
+}
+
+// EqualXMLFiles tests for equality of the normalized XML contents of the
+// named files.
+func (n *Normalizer) EqualXMLFiles(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+	return n.EqualXML(fa, fb)
+}
+
+// EqualXMLDirs walks the directory trees rooted at a and b, matches their
+// regular files by relative path and compares the normalized XML contents
+// of each pair. It returns the files that differ, sorted by path. All
+// regular files must contain XML.
+func (n *Normalizer) EqualXMLDirs(a, b string) ([]FileDiff, error) {
+	fa, err := listFiles(a)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := listFiles(b)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []FileDiff
+	for p := range fa {
+		if !fb[p] {
+			diffs = append(diffs, FileDiff{Path: p, OnlyInA: true})
+			continue
+		}
+		ds, err := n.diffFiles(filepath.Join(a, filepath.FromSlash(p)), filepath.Join(b, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, fmt.Errorf("xmltest: %s: %v", p, err)
+		}
+		if len(ds) > 0 {
+			diffs = append(diffs, FileDiff{Path: p, Differences: ds})
+		}
+	}
+	for p := range fb {
+		if !fa[p] {
+			diffs = append(diffs, FileDiff{Path: p, OnlyInB: true})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+func (n *Normalizer) diffFiles(a, b string) ([]Difference, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return nil, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return nil, err
+	}
+	defer fb.Close()
+	return n.Diff(fa, fb)
+}
+
+// listFiles returns the slash-separated relative paths of the regular
+// files in the tree rooted at dir.
+func listFiles(dir string) (map[string]bool, error) {
+	files := map[string]bool{}
+	err := filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil || !e.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return files, err
+}
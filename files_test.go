@@ -0,0 +1,71 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestEqualXMLFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.xml": `<root a="1" b="2"/>`,
+		"b.xml": `<root b="2" a="1"></root>`,
+		"c.xml": `<root/>`,
+	})
+	var n Normalizer
+	if got, err := n.EqualXMLFiles(filepath.Join(dir, "a.xml"), filepath.Join(dir, "b.xml")); err != nil || !got {
+		t.Errorf("equal: got %v, %v, want true, nil", got, err)
+	}
+	if got, err := n.EqualXMLFiles(filepath.Join(dir, "a.xml"), filepath.Join(dir, "c.xml")); err != nil || got {
+		t.Errorf("different: got %v, %v, want false, nil", got, err)
+	}
+	if _, err := n.EqualXMLFiles(filepath.Join(dir, "a.xml"), filepath.Join(dir, "missing.xml")); err == nil {
+		t.Errorf("missing: got nil error, want some")
+	}
+}
+
+func TestEqualXMLDirs(t *testing.T) {
+	a := writeTree(t, map[string]string{
+		"same.xml":       `<root a="1" b="2"/>`,
+		"sub/differ.xml": `<root><p>x</p></root>`,
+		"only-a.xml":     `<root/>`,
+	})
+	b := writeTree(t, map[string]string{
+		"same.xml":       `<root b="2" a="1"/>`,
+		"sub/differ.xml": `<root><p>y</p></root>`,
+		"sub/only-b.xml": `<root/>`,
+	})
+	var n Normalizer
+	got, err := n.EqualXMLDirs(a, b)
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	want := []FileDiff{
+		{Path: "only-a.xml", OnlyInA: true},
+		{Path: "sub/differ.xml", Differences: []Difference{{Path: "/root/p/text()", A: `"x"`, B: `"y"`, Cause: CauseText}}},
+		{Path: "sub/only-b.xml", OnlyInB: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+}
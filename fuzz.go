@@ -0,0 +1,110 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// seedCorpus holds documents that exercise the normalization rules.
+var seedCorpus = []string{
+	`<root/>`,
+	`<root b="2" a="1"><a>text</a><!-- comment --><b/></root>`,
+	`<?xml version="1.0"?><!DOCTYPE root><root><?pi data?></root>`,
+	`<s:root xmlns:s="space" xmlns:f="foo"><f:a f:x="1"/></s:root>`,
+	`<root xmlns="space"><a xmlns="">x</a></root>`,
+	"<root>\n  <a> 1 </a>\n  <b>true</b>\n</root>",
+	`<root>a<![CDATA[<b>]]>c&amp;&lt;&#65;</root>`,
+	`<root a="&quot;&apos;&#9;"/>`,
+	`<root><a/><a/><a>x</a></root>`,
+	`<root></a>`,
+	`<root`,
+	``,
+}
+
+// SeedCorpus returns documents suitable as the seed corpus of fuzz
+// targets that normalize XML. Some of them are not well-formed.
+func SeedCorpus() [][]byte {
+	seeds := make([][]byte, len(seedCorpus))
+	for i, s := range seedCorpus {
+		seeds[i] = []byte(s)
+	}
+	return seeds
+}
+
+// FuzzNormalize adds the seed corpus to f and fuzzes n with it. A nil n
+// is the zero Normalizer. For each input that n normalizes without error,
+// it checks that
+//
+//   - the output is well-formed,
+//   - normalizing the output again yields the same output, and
+//   - EqualXML reports the input equal to itself and to the output.
+//
+// The checks assume that the transforms and filters of n are idempotent.
+func FuzzNormalize(f *testing.F, n *Normalizer) {
+	f.Helper()
+	if n == nil {
+		n = new(Normalizer)
+	}
+	for _, s := range SeedCorpus() {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if err := n.checkInvariants(in); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// FuzzEqualXML adds pairs of the seed corpus to f and fuzzes the EqualXML
+// method of n with them. A nil n is the zero Normalizer. It checks that
+// EqualXML is symmetric.
+func FuzzEqualXML(f *testing.F, n *Normalizer) {
+	f.Helper()
+	if n == nil {
+		n = new(Normalizer)
+	}
+	seeds := SeedCorpus()
+	for i := range seeds {
+		f.Add(seeds[i], seeds[(i+1)%len(seeds)])
+	}
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		ab, errab := n.EqualXML(bytes.NewReader(a), bytes.NewReader(b))
+		ba, errba := n.EqualXML(bytes.NewReader(b), bytes.NewReader(a))
+		if (errab == nil) != (errba == nil) {
+			t.Fatalf("xmltest: EqualXML errors differ by argument order: %v, %v", errab, errba)
+		}
+		if ab != ba {
+			t.Errorf("xmltest: EqualXML is not symmetric: %v, %v\na: %q\nb: %q", ab, ba, a, b)
+		}
+	})
+}
+
+// checkInvariants checks the invariants of FuzzNormalize for in.
+func (n *Normalizer) checkInvariants(in []byte) error {
+	var out bytes.Buffer
+	if err := n.Normalize(&out, bytes.NewReader(in)); err != nil {
+		return nil
+	}
+	if err := checkWellFormed(out.Bytes()); err != nil {
+		return fmt.Errorf("%v\ninput:  %q\noutput: %q", err, in, out.Bytes())
+	}
+	var again bytes.Buffer
+	if err := n.Normalize(&again, bytes.NewReader(out.Bytes())); err != nil {
+		return fmt.Errorf("xmltest: normalizing output failed: %v\ninput:  %q\noutput: %q", err, in, out.Bytes())
+	}
+	if !bytes.Equal(out.Bytes(), again.Bytes()) {
+		return fmt.Errorf("xmltest: normalization is not idempotent\ninput:  %q\nonce:   %q\ntwice:  %q", in, out.Bytes(), again.Bytes())
+	}
+	for _, other := range [][]byte{in, out.Bytes()} {
+		eq, err := n.EqualXML(bytes.NewReader(in), bytes.NewReader(other))
+		if err != nil || !eq {
+			return fmt.Errorf("xmltest: EqualXML(input, %q) = %v, %v, want true, nil\ninput: %q", other, eq, err, in)
+		}
+	}
+	return nil
+}
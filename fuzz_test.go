@@ -0,0 +1,19 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import "testing"
+
+func FuzzDefaultNormalize(f *testing.F) {
+	FuzzNormalize(f, nil)
+}
+
+func FuzzSortingNormalize(f *testing.F) {
+	FuzzNormalize(f, &Normalizer{OmitWhitespace: true, OmitComments: true, SortElements: true, NormalizeBooleans: true})
+}
+
+func FuzzDefaultEqualXML(f *testing.F) {
+	FuzzEqualXML(f, nil)
+}
@@ -0,0 +1,137 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// Generator produces random well-formed XML documents for property-based
+// testing. Documents are determined by Seed, so a Generator with the same
+// configuration yields the same sequence of documents. Zero fields select
+// defaults.
+type Generator struct {
+	// Seed seeds the random source.
+	Seed int64
+	// MaxDepth limits the nesting depth of elements. The default is 4.
+	MaxDepth int
+	// MaxChildren limits the number of child nodes of an element. The
+	// default is 4.
+	MaxChildren int
+	// MaxAttrs limits the number of attributes of an element. The default
+	// is 3.
+	MaxAttrs int
+	// Names lists the local names of elements and attributes. The default
+	// is a, b, c and d, so that names repeat.
+	Names []string
+	// Namespaces lists the namespace URIs of element and attribute names.
+	// If empty, names are not in a namespace.
+	Namespaces []string
+	// Alphabet lists the characters of text and attribute values. The
+	// default includes letters, digits, whitespace and the characters that
+	// XML escapes.
+	Alphabet string
+
+	rnd *rand.Rand
+}
+
+const defaultAlphabet = "abcxyz 0129\n\t<>&'\""
+
+var defaultNames = []string{"a", "b", "c", "d"}
+
+// Generate returns the next random document.
+func (g *Generator) Generate() []byte {
+	if g.rnd == nil {
+		g.rnd = rand.New(rand.NewSource(g.Seed))
+	}
+	var b bytes.Buffer
+	g.element(&b, 1)
+	return b.Bytes()
+}
+
+func (g *Generator) element(b *bytes.Buffer, depth int) {
+	name, decls := g.name(nil)
+	b.WriteString("<" + name)
+	seen := map[string]bool{}
+	for i := g.rnd.Intn(orDefault(g.MaxAttrs, 3) + 1); i > 0; i-- {
+		var attr string
+		attr, decls = g.name(decls)
+		if seen[attr] {
+			continue
+		}
+		seen[attr] = true
+		b.WriteString(" " + attr + `="`)
+		xml.EscapeText(b, []byte(g.text()))
+		b.WriteString(`"`)
+	}
+	prefixes := make([]string, 0, len(decls))
+	for prefix := range decls {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		b.WriteString(" xmlns:" + prefix + `="`)
+		xml.EscapeText(b, []byte(decls[prefix]))
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+	if depth < orDefault(g.MaxDepth, 4) {
+		for i := g.rnd.Intn(orDefault(g.MaxChildren, 4) + 1); i > 0; i-- {
+			switch g.rnd.Intn(6) {
+			case 0:
+				b.WriteString("<!--" + strconv.Itoa(g.rnd.Intn(10)) + "-->")
+			case 1, 2:
+				xml.EscapeText(b, []byte(g.text()))
+			default:
+				g.element(b, depth+1)
+			}
+		}
+	}
+	b.WriteString("</" + name + ">")
+}
+
+// name returns a random qualified name. Names in a namespace use a prefix
+// that is added to decls, which is allocated if nil.
+func (g *Generator) name(decls map[string]string) (string, map[string]string) {
+	names := g.Names
+	if len(names) == 0 {
+		names = defaultNames
+	}
+	local := names[g.rnd.Intn(len(names))]
+	if len(g.Namespaces) == 0 || g.rnd.Intn(2) == 0 {
+		return local, decls
+	}
+	i := g.rnd.Intn(len(g.Namespaces))
+	prefix := "n" + strconv.Itoa(i)
+	if decls == nil {
+		decls = map[string]string{}
+	}
+	decls[prefix] = g.Namespaces[i]
+	return prefix + ":" + local, decls
+}
+
+// text returns a random string of the alphabet.
+func (g *Generator) text() string {
+	alphabet := []rune(g.Alphabet)
+	if len(alphabet) == 0 {
+		alphabet = []rune(defaultAlphabet)
+	}
+	s := make([]rune, g.rnd.Intn(8))
+	for i := range s {
+		s[i] = alphabet[g.rnd.Intn(len(alphabet))]
+	}
+	return string(s)
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
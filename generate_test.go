@@ -0,0 +1,56 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestGenerator(t *testing.T) {
+	g1 := Generator{Seed: 1, MaxDepth: 3, Namespaces: []string{"urn:x", "urn:y"}}
+	g2 := Generator{Seed: 1, MaxDepth: 3, Namespaces: []string{"urn:x", "urn:y"}}
+	for i := 0; i < 100; i++ {
+		doc := g1.Generate()
+		if other := g2.Generate(); !bytes.Equal(doc, other) {
+			t.Fatalf("#%d: not deterministic:\n%s\n%s", i, doc, other)
+		}
+		if err := checkWellFormed(doc); err != nil {
+			t.Fatalf("#%d: %v\n%s", i, err, doc)
+		}
+		d := xml.NewDecoder(bytes.NewReader(doc))
+		depth, maxDepth := 0, 0
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			switch tok.(type) {
+			case xml.StartElement:
+				depth++
+				if depth > maxDepth {
+					maxDepth = depth
+				}
+			case xml.EndElement:
+				depth--
+			}
+		}
+		if maxDepth > 3 {
+			t.Errorf("#%d: got depth %d, want at most 3\n%s", i, maxDepth, doc)
+		}
+	}
+}
+
+func TestGeneratorInvariants(t *testing.T) {
+	g := Generator{Seed: 2}
+	n := Normalizer{SortElements: true, OmitComments: true}
+	for i := 0; i < 100; i++ {
+		if err := n.checkInvariants(g.Generate()); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"crypto/sha256"
+	"io"
+)
+
+// Hash returns the SHA-256 digest of the normalized XML content of r, as
+// written by Normalize. The normalized content is streamed into the hash
+// without being buffered. Documents with the same hash are equal, but
+// documents that EqualXML compares equal within a tolerance may hash
+// differently.
+func (n *Normalizer) Hash(r io.Reader) ([32]byte, error) {
+	var sum [32]byte
+	h := sha256.New()
+	m := *n
+	m.MaxOutputBytes = 0
+	if err := m.Normalize(h, r); err != nil {
+		return sum, err
+	}
+	h.Sum(sum[:0])
+	return sum, nil
+}
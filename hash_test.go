@@ -0,0 +1,54 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		n         Normalizer
+		a, b      string
+		wantEqual bool
+	}{{
+		desc:      "equal after normalization",
+		a:         `<root b="2" a="1"><!-- c --></root>`,
+		b:         `<?xml version="1.0"?><root a="1" b="2"><!-- c --></root>`,
+		wantEqual: true,
+	}, {
+		desc: "different",
+		a:    `<root a="1"/>`,
+		b:    `<root a="2"/>`,
+	}, {
+		desc:      "options apply",
+		n:         Normalizer{OmitComments: true},
+		a:         `<root><!-- c --></root>`,
+		b:         `<root/>`,
+		wantEqual: true,
+	}}
+
+	for _, tc := range testCases {
+		ha, err := tc.n.Hash(strings.NewReader(tc.a))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		hb, err := tc.n.Hash(strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := ha == hb; got != tc.wantEqual {
+			t.Errorf("%s: got equal hashes %v, want %v", tc.desc, got, tc.wantEqual)
+		}
+	}
+	var n Normalizer
+	if _, err := n.Hash(strings.NewReader("<root></foo>")); err == nil {
+		t.Errorf("bad input: got nil error, want non-nil")
+	}
+}
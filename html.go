@@ -0,0 +1,161 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strings"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>XML diff</title>
+<style>
+table { border-collapse: collapse; font-family: monospace; width: 100%; }
+td { white-space: pre; vertical-align: top; padding: 0 0.5em; width: 50%; }
+th { text-align: left; }
+.del { background: #fdd; }
+.ins { background: #dfd; }
+.tag { color: #22863a; }
+.attr { color: #6f42c1; }
+.value { color: #032f62; }
+.comment { color: #6a737d; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>a</th><th>b</th></tr>
+`
+
+const htmlFooter = `</table>
+</body>
+</html>
+`
+
+// DiffHTML writes an HTML document to w that shows the normalized XML
+// contents of a and b side by side. The documents are indented with one
+// element per line, lines that differ are marked, and XML markup is syntax
+// highlighted. Namespace prefixes are assigned as by NormalizePair.
+func (n *Normalizer) DiffHTML(w io.Writer, a, b io.Reader) error {
+	pt := newPrefixTable()
+	la, err := n.indentedLines(a, pt)
+	if err != nil {
+		return err
+	}
+	lb, err := n.indentedLines(b, pt)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(htmlHeader)
+	ops := diffLines(la, lb)
+	for len(ops) > 0 {
+		if ops[0].kind == ' ' {
+			writeHTMLRow(bw, "", ops[0].line, "", ops[0].line)
+			ops = ops[1:]
+			continue
+		}
+		// Pair up a run of deleted lines with the inserted lines that
+		// follow it.
+		var del, ins []string
+		for len(ops) > 0 && ops[0].kind == '-' {
+			del = append(del, ops[0].line)
+			ops = ops[1:]
+		}
+		for len(ops) > 0 && ops[0].kind == '+' {
+			ins = append(ins, ops[0].line)
+			ops = ops[1:]
+		}
+		for i := 0; i < len(del) || i < len(ins); i++ {
+			var left, right, lclass, rclass string
+			if i < len(del) {
+				left, lclass = del[i], "del"
+			}
+			if i < len(ins) {
+				right, rclass = ins[i], "ins"
+			}
+			writeHTMLRow(bw, lclass, left, rclass, right)
+		}
+	}
+	bw.WriteString(htmlFooter)
+	return bw.Flush()
+}
+
+func writeHTMLRow(w *bufio.Writer, lclass, left, rclass, right string) {
+	w.WriteString("<tr>")
+	for _, c := range [...][2]string{{lclass, left}, {rclass, right}} {
+		if c[0] != "" {
+			w.WriteString(`<td class="` + c[0] + `">`)
+		} else {
+			w.WriteString("<td>")
+		}
+		highlightXML(w, c[1])
+		w.WriteString("</td>")
+	}
+	w.WriteString("</tr>\n")
+}
+
+// highlightXML writes the HTML-escaped line of serialized XML to w, with
+// markup wrapped in spans for syntax highlighting.
+func highlightXML(w *bufio.Writer, line string) {
+	for line != "" {
+		i := strings.IndexByte(line, '<')
+		if i < 0 {
+			w.WriteString(html.EscapeString(line))
+			return
+		}
+		w.WriteString(html.EscapeString(line[:i]))
+		line = line[i:]
+		if strings.HasPrefix(line, "<!--") {
+			end := strings.Index(line, "-->")
+			if end < 0 {
+				end = len(line)
+			} else {
+				end += len("-->")
+			}
+			w.WriteString(`<span class="comment">` + html.EscapeString(line[:end]) + "</span>")
+			line = line[end:]
+			continue
+		}
+		end := strings.IndexByte(line, '>')
+		if end < 0 {
+			end = len(line) - 1
+		}
+		highlightTag(w, line[:end+1])
+		line = line[end+1:]
+	}
+}
+
+// highlightTag writes the start or end tag to w.
+func highlightTag(w *bufio.Writer, tag string) {
+	name := strings.IndexAny(tag, " \t")
+	if name < 0 {
+		w.WriteString(`<span class="tag">` + html.EscapeString(tag) + "</span>")
+		return
+	}
+	w.WriteString(`<span class="tag">` + html.EscapeString(tag[:name]) + "</span>")
+	rest := tag[name:]
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 || eq+1 >= len(rest) || rest[eq+1] != '"' {
+			break
+		}
+		end := strings.IndexByte(rest[eq+2:], '"')
+		if end < 0 {
+			break
+		}
+		end += eq + 3
+		w.WriteString(`<span class="attr">` + html.EscapeString(rest[:eq]) + "</span>=")
+		w.WriteString(`<span class="value">` + html.EscapeString(rest[eq+1:end]) + "</span>")
+		rest = rest[end:]
+	}
+	if rest != "" {
+		w.WriteString(`<span class="tag">` + html.EscapeString(rest) + "</span>")
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffHTML(t *testing.T) {
+	var n Normalizer
+	var b strings.Builder
+	err := n.DiffHTML(&b, strings.NewReader(`<root a="1"><foo>x&lt;</foo></root>`), strings.NewReader(`<root a="2"><foo>x&lt;</foo></root>`))
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		`<tr><td class="del"><span class="tag">&lt;root</span><span class="attr"> a</span>=<span class="value">&#34;1&#34;</span><span class="tag">&gt;</span></td>` +
+			`<td class="ins"><span class="tag">&lt;root</span><span class="attr"> a</span>=<span class="value">&#34;2&#34;</span><span class="tag">&gt;</span></td></tr>`,
+		`<tr><td>  <span class="tag">&lt;foo&gt;</span>x&amp;lt;<span class="tag">&lt;/foo&gt;</span></td>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %s\noutput:\n%s", want, got)
+		}
+	}
+}
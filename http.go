@@ -0,0 +1,50 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// AssertResponseXML reports an error to t unless resp has an XML content
+// type and a body with the same normalized XML content as want, using a
+// Normalizer configured by opts. It reads and closes the body of resp.
+func AssertResponseXML(t *testing.T, resp *http.Response, want string, opts ...Option) {
+	t.Helper()
+	for _, err := range New(opts...).responseErrors(resp, want) {
+		t.Error(err)
+	}
+}
+
+func (n *Normalizer) responseErrors(resp *http.Response, want string) []error {
+	var errs []error
+	if ct := resp.Header.Get("Content-Type"); !isXMLMediaType(ct) {
+		errs = append(errs, fmt.Errorf("xmltest: response has content type %q, want XML", ct))
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return append(errs, fmt.Errorf("xmltest: reading response body: %v", err))
+	}
+	if err := n.differenceError("response body differs (a: response, b: want)", body, []byte(want)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// isXMLMediaType reports whether the Content-Type header value ct denotes
+// XML, that is application/xml, text/xml or a type with the suffix +xml.
+func isXMLMediaType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml")
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseErrors(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		contentType string
+		body        string
+		opts        []Option
+		wantErrs    int
+	}{
+		{desc: "equal", contentType: "application/xml", body: `<a  x="1"/>`},
+		{desc: "charset parameter", contentType: "text/xml; charset=utf-8", body: `<a x="1"></a>`},
+		{desc: "xml suffix", contentType: "application/atom+xml", body: `<a x="1"/>`},
+		{desc: "options", contentType: "application/xml", body: "<a x=\"1\">\n</a>", opts: []Option{OmitWhitespace()}},
+		{desc: "different", contentType: "application/xml", body: `<a x="2"/>`, wantErrs: 1},
+		{desc: "not XML", contentType: "application/json", body: `<a x="1"/>`, wantErrs: 1},
+		{desc: "no content type", body: `<a x="1"/>`, wantErrs: 1},
+		{desc: "not XML and different", contentType: "text/plain", body: `<b/>`, wantErrs: 2},
+		{desc: "malformed", contentType: "application/xml", body: `<a>`, wantErrs: 1},
+	}
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		if tc.contentType != "" {
+			rec.Header().Set("Content-Type", tc.contentType)
+		}
+		io.WriteString(rec, tc.body)
+		resp := rec.Result()
+		errs := New(tc.opts...).responseErrors(resp, `<a x="1"/>`)
+		if len(errs) != tc.wantErrs {
+			t.Errorf("%s: got errors %v, want %d", tc.desc, errs, tc.wantErrs)
+		}
+	}
+}
+
+func TestAssertResponseXML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		io.WriteString(w, "<feed>\n  <entry id='1'/>\n</feed>")
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertResponseXML(t, resp, `<feed><entry id="1"></entry></feed>`, OmitWhitespace())
+}
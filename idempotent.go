@@ -0,0 +1,41 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// normalizeChecked normalizes the tokens of tr twice and writes the output
+// to w if both passes agree. Prefixes are assigned from pt if it is not
+// nil.
+func (n *Normalizer) normalizeChecked(w io.Writer, tr xml.TokenReader, pt *prefixTable) error {
+	once := getBuffer()
+	defer putBuffer(once)
+	if err := n.normalizeTokens(once, tr, pt); err != nil {
+		return err
+	}
+	// The second pass must not report to the hooks again.
+	m := *n
+	m.Trace, m.OnToken, m.OnProgress = nil, nil, nil
+	twice := getBuffer()
+	defer putBuffer(twice)
+	if err := m.normalizeTokens(twice, xml.NewDecoder(bytes.NewReader(once.Bytes())), pt); err != nil {
+		return err
+	}
+	if !bytes.Equal(once.Bytes(), twice.Bytes()) {
+		// The buffers go back to the pool, so the error gets copies.
+		return &IdempotenceError{
+			Once:  append([]byte(nil), once.Bytes()...),
+			Twice: append([]byte(nil), twice.Bytes()...),
+		}
+	}
+	if _, err := w.Write(once.Bytes()); err != nil {
+		return &WriteError{Err: err}
+	}
+	return nil
+}
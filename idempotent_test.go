@@ -0,0 +1,37 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckIdempotent(t *testing.T) {
+	n := Normalizer{CheckIdempotent: true, OmitWhitespace: true}
+	var b strings.Builder
+	if err := n.Normalize(&b, strings.NewReader("<root b='2' a='1'> <a/> </root>")); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if got, want := b.String(), `<root a="1" b="2"><a></a></root>`; got != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+
+	n.TextTransform = func(path []xml.Name, s string) string { return s + "!" }
+	b.Reset()
+	err := n.Normalize(&b, strings.NewReader("<root>x</root>"))
+	var ierr *IdempotenceError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("got err %v, want *IdempotenceError", err)
+	}
+	if got, want := string(ierr.Twice), `<root>x!!</root>`; got != want {
+		t.Errorf("twice: got %s, want %s", got, want)
+	}
+	if b.Len() != 0 {
+		t.Errorf("got output %s, want none", b.String())
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DanglingRef is an IDREF attribute value that matches no ID of its
+// document.
+type DanglingRef struct {
+	// Path locates the attribute, such as /root/a/@ref, and Line is the
+	// line of its element.
+	Path string
+	Line int
+	// ID is the value, or for IDREFS attributes the list item, that
+	// matches no ID.
+	ID string
+}
+
+// IDRefError reports the dangling references found by CheckIDRefs, in
+// document order.
+type IDRefError struct {
+	Refs []DanglingRef
+}
+
+func (e *IDRefError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "xmltest: %d dangling IDREFs:", len(e.Refs))
+	for _, r := range e.Refs {
+		fmt.Fprintf(&b, "\n\t%d: %s: %s", r.Line, r.Path, r.ID)
+	}
+	return b.String()
+}
+
+// attrType is the type of an attribute declared in a DTD.
+type attrType int
+
+const (
+	typeID attrType = iota + 1
+	typeIDRef
+	typeIDRefs
+)
+
+// CheckIDRefs verifies that each IDREF attribute value of the document r
+// matches an ID, and reports the dangling references with an
+// *IDRefError. IDs are the values of xml:id and of the attributes declared
+// as ID in the internal subset of the document type declaration.
+// References are the values of attributes declared as IDREF or IDREFS
+// there. Names in declarations match attributes by local name.
+func CheckIDRefs(r io.Reader) error {
+	d := xml.NewDecoder(r)
+	// types maps element and attribute local names to declared types.
+	types := map[[2]string]attrType{}
+	ids := map[string]bool{}
+	var (
+		refs []DanglingRef
+		path []xml.Name
+	)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, col := d.InputPos()
+			var serr *xml.SyntaxError
+			if errors.As(err, &serr) {
+				return &SyntaxError{Line: line, Column: col, Err: err}
+			}
+			return err
+		}
+		switch t := t.(type) {
+		case xml.Directive:
+			parseAttlists(string(t), types)
+		case xml.StartElement:
+			path = append(path, t.Name)
+			line, _ := d.InputPos()
+			for _, a := range t.Attr {
+				typ := types[[2]string{t.Name.Local, a.Name.Local}]
+				if a.Name.Space == xmlURL && a.Name.Local == "id" {
+					typ = typeID
+				}
+				switch typ {
+				case typeID:
+					ids[strings.TrimSpace(a.Value)] = true
+				case typeIDRef, typeIDRefs:
+					p := formatPath(path) + "/@" + clarkName(a.Name)
+					vals := []string{strings.TrimSpace(a.Value)}
+					if typ == typeIDRefs {
+						vals = strings.Fields(a.Value)
+					}
+					for _, v := range vals {
+						refs = append(refs, DanglingRef{Path: p, Line: line, ID: v})
+					}
+				}
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		}
+	}
+	var dangling []DanglingRef
+	for _, r := range refs {
+		if !ids[r.ID] {
+			dangling = append(dangling, r)
+		}
+	}
+	if len(dangling) > 0 {
+		return &IDRefError{Refs: dangling}
+	}
+	return nil
+}
+
+// parseAttlists adds the ID, IDREF and IDREFS attributes declared by the
+// attribute-list declarations of the directive dir to types.
+func parseAttlists(dir string, types map[[2]string]attrType) {
+	for {
+		i := strings.Index(dir, "<!ATTLIST")
+		if i < 0 {
+			return
+		}
+		var toks []string
+		toks, dir = dtdTokens(dir[i+len("<!ATTLIST"):])
+		if len(toks) == 0 {
+			continue
+		}
+		elem := localName(toks[0])
+		for toks = toks[1:]; len(toks) >= 3; {
+			attr, typ := localName(toks[0]), toks[1]
+			toks = toks[2:]
+			if typ == "NOTATION" && len(toks) > 0 {
+				toks = toks[1:]
+			}
+			switch typ {
+			case "ID":
+				types[[2]string{elem, attr}] = typeID
+			case "IDREF":
+				types[[2]string{elem, attr}] = typeIDRef
+			case "IDREFS":
+				types[[2]string{elem, attr}] = typeIDRefs
+			}
+			// Skip the default declaration.
+			if len(toks) > 0 && toks[0] == "#FIXED" {
+				toks = toks[1:]
+			}
+			if len(toks) > 0 {
+				toks = toks[1:]
+			}
+		}
+	}
+}
+
+// dtdTokens splits s up to the closing '>' of a declaration into names,
+// quoted literals and parenthesized groups, and returns the rest of s.
+func dtdTokens(s string) (toks []string, rest string) {
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '>':
+			return toks, s[i+1:]
+		case isSpace(c):
+			i++
+		case c == '"' || c == '\'' || c == '(':
+			end := c
+			if c == '(' {
+				end = ')'
+			}
+			j := strings.IndexByte(s[i+1:], end)
+			if j < 0 {
+				return toks, ""
+			}
+			toks = append(toks, s[i:i+j+2])
+			i += j + 2
+		default:
+			j := i
+			for j < len(s) && !isSpace(s[j]) && s[j] != '>' && s[j] != '(' {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		}
+	}
+	return toks, ""
+}
+
+// localName returns the local part of the qualified name s.
+func localName(s string) string {
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
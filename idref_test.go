@@ -0,0 +1,84 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const idrefDTD = `<!DOCTYPE r [
+  <!ATTLIST item key ID #REQUIRED
+                 next IDREF #IMPLIED
+                 kind (a|b) "a">
+  <!ATTLIST link to IDREFS #IMPLIED mode NOTATION (x|y) #FIXED "x">
+]>
+`
+
+func TestCheckIDRefs(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		in      string
+		want    []DanglingRef
+		wantErr bool
+	}{{
+		desc: "no references",
+		in:   `<r><a xml:id="x"/></r>`,
+	}, {
+		desc: "xml:id is not a reference",
+		in:   `<r><a id="x"/><b ref="y"/></r>`,
+	}, {
+		desc: "declared references resolve, forward too",
+		in:   idrefDTD + `<r><item key="a" next="b"/><item key="b"/><link to="a b"/></r>`,
+	}, {
+		desc: "references to xml:id",
+		in:   idrefDTD + `<r><item key="a" next="z"/><p xml:id="z"/></r>`,
+	}, {
+		desc: "dangling references",
+		in: idrefDTD + "<r>\n" +
+			`<item key="a" next="c"/>` + "\n" +
+			`<s><link to=" a  d e"/></s>` + "\n" +
+			`</r>`,
+		want: []DanglingRef{
+			{Path: "/r/item/@next", Line: 8, ID: "c"},
+			{Path: "/r/s/link/@to", Line: 9, ID: "d"},
+			{Path: "/r/s/link/@to", Line: 9, ID: "e"},
+		},
+	}, {
+		desc:    "malformed",
+		in:      `<r>`,
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		err := CheckIDRefs(strings.NewReader(tc.in))
+		var ierr *IDRefError
+		switch {
+		case tc.wantErr:
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Errorf("%s: got err %v, want *SyntaxError", tc.desc, err)
+			}
+		case tc.want == nil:
+			if err != nil {
+				t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			}
+		case !errors.As(err, &ierr):
+			t.Errorf("%s: got err %v, want *IDRefError", tc.desc, err)
+		case !reflect.DeepEqual(ierr.Refs, tc.want):
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tc.desc, ierr.Refs, tc.want)
+		}
+	}
+}
+
+func TestIDRefErrorString(t *testing.T) {
+	err := &IDRefError{Refs: []DanglingRef{{Path: "/r/a/@ref", Line: 2, ID: "x"}}}
+	want := "xmltest: 1 dangling IDREFs:\n\t2: /r/a/@ref: x"
+	if got := err.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
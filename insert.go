@@ -0,0 +1,74 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// InsertAt writes the normalized document base to w, with the normalized
+// fragment inserted as the last content of each element that path
+// selects, so that expected documents can be assembled from reusable
+// fragments. The fragment may hold any sequence of elements and character
+// data, and does not inherit the namespace declarations of base. Path is
+// as for Extract and matches the elements of base only.
+func (n *Normalizer) InsertAt(base io.Reader, path string, fragment io.Reader, w io.Writer) error {
+	p, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if p.hasAttr {
+		return fmt.Errorf("xmltest: path %s selects an attribute", path)
+	}
+	nf := *n
+	nf.Fragment = true
+	frag, err := nf.readTokens(fragment)
+	if err != nil {
+		return err
+	}
+	toks, err := n.readTokens(base)
+	if err != nil {
+		return err
+	}
+	var (
+		out      []xml.Token
+		names    []xml.Name
+		selected []bool
+	)
+	for _, t := range toks {
+		switch t := t.(type) {
+		case xml.StartElement:
+			names = append(names, t.Name)
+			selected = append(selected, p.matchElement(names))
+		case xml.EndElement:
+			if selected[len(selected)-1] {
+				out = append(out, frag...)
+			}
+			names, selected = names[:len(names)-1], selected[:len(selected)-1]
+		}
+		out = append(out, t)
+	}
+	if n.rearranges() {
+		// Bring the fragments into order with their new siblings.
+		nodes := buildTree(out)
+		out = out[:0]
+		for _, nd := range nodes {
+			n.arrange([]*node{nd})
+			out = nd.appendTokens(out)
+		}
+	}
+	ew := &errWriter{w: w}
+	bw, release := newWriter(ew)
+	defer release()
+	tw := newTokenWriter(bw, n.Format)
+	for _, t := range out {
+		if err := tw.writeToken(t); err != nil {
+			return ew.wrap(err)
+		}
+	}
+	return ew.wrap(tw.close())
+}
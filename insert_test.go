@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInsertAt(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		n        Normalizer
+		base     string
+		path     string
+		fragment string
+		want     string
+		wantErr  bool
+	}{{
+		desc:     "insert as last content",
+		base:     `<order><id>1</id></order>`,
+		path:     "/order",
+		fragment: `<item  sku="a"/> <item sku="b"/>`,
+		want:     `<order><id>1</id><item sku="a"></item> <item sku="b"></item></order>`,
+	}, {
+		desc:     "insert into each selected element",
+		n:        Normalizer{OmitWhitespace: true},
+		base:     `<r><list/><x><list><a/></list></x></r>`,
+		path:     "list",
+		fragment: "\n<b>t</b>\n",
+		want:     `<r><list><b>t</b></list><x><list><a></a><b>t</b></list></x></r>`,
+	}, {
+		desc:     "sort inserted elements",
+		n:        Normalizer{SortElements: true},
+		base:     `<r><c/><a/></r>`,
+		path:     "/r",
+		fragment: `<b/>`,
+		want:     `<r><a></a><b></b><c></c></r>`,
+	}, {
+		desc:     "no match",
+		base:     `<r/>`,
+		path:     "/x",
+		fragment: `<b/>`,
+		want:     `<r></r>`,
+	}, {
+		desc:     "malformed fragment",
+		base:     `<r/>`,
+		path:     "/r",
+		fragment: `<b>`,
+		wantErr:  true,
+	}, {
+		desc:     "attribute path",
+		base:     `<r/>`,
+		path:     "/r/@a",
+		fragment: `<b/>`,
+		wantErr:  true,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := tc.n.InsertAt(strings.NewReader(tc.base), tc.path, strings.NewReader(tc.fragment), &b)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// MarshalJSON implements json.Marshaler. A difference is encoded as an
+// object with the fields path, a, b, offset, cause and message. The fields
+// a and b are omitted if the content is missing in the respective
+// document, offset if zero, and cause if not classified.
+func (d Difference) MarshalJSON() ([]byte, error) {
+	var cause string
+	if d.Cause != 0 {
+		cause = d.Cause.String()
+	}
+	return json.Marshal(struct {
+		Path    string `json:"path"`
+		A       string `json:"a,omitempty"`
+		B       string `json:"b,omitempty"`
+		Offset  int    `json:"offset,omitempty"`
+		Cause   string `json:"cause,omitempty"`
+		Message string `json:"message"`
+	}{d.Path, d.A, d.B, d.Offset, cause, d.String()})
+}
+
+// DiffJSON compares the normalized XML contents of a and b, and returns
+// the result as a JSON object with the fields equal, differences and
+// causes, which counts the differences per cause.
+func (n *Normalizer) DiffJSON(a, b io.Reader) ([]byte, error) {
+	diffs, err := n.Diff(a, b)
+	if err != nil {
+		return nil, err
+	}
+	if diffs == nil {
+		diffs = []Difference{}
+	}
+	causes := map[string]int{}
+	for c, k := range CountCauses(diffs) {
+		causes[c.String()] = k
+	}
+	return json.Marshal(struct {
+		Equal       bool           `json:"equal"`
+		Differences []Difference   `json:"differences"`
+		Causes      map[string]int `json:"causes"`
+	}{len(diffs) == 0, diffs, causes})
+}
+
+// JSONConvention configures the JSON projection of ToJSON.
+type JSONConvention struct {
+	// AttrPrefix prefixes the keys of attributes. The zero value selects
+	// "@".
+	AttrPrefix string
+	// TextKey is the key of character data. The zero value selects
+	// "#text".
+	TextKey string
+	// Arrays projects all child elements to arrays, rather than only
+	// repeated ones, so that the shape does not depend on their number.
+	Arrays bool
+}
+
+// ToJSON writes a deterministic JSON projection of the normalized XML
+// content of r to w, configured by n.JSON. The document is an object with
+// a key for each root element. An element without attributes and child
+// elements is its character data, any other element an object with a key
+// for each attribute, for its character data if not empty, and for each
+// name of its child elements, whose value is an array if the name
+// repeats. Names in a namespace are in Clark notation, and comments are
+// omitted. Keys are sorted, so the projection loses the order of
+// differently named siblings and of text in mixed content.
+func (n *Normalizer) ToJSON(r io.Reader, w io.Writer) error {
+	toks, err := n.readTokens(r)
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	return e.Encode(n.JSON.object(nil, buildTree(toks), true))
+}
+
+// object projects an element with the attributes attrs and the child
+// nodes children, or the document with the root nodes children.
+func (c JSONConvention) object(attrs []xml.Attr, children []*node, doc bool) map[string]interface{} {
+	prefix, textKey := c.AttrPrefix, c.TextKey
+	if prefix == "" {
+		prefix = "@"
+	}
+	if textKey == "" {
+		textKey = "#text"
+	}
+	obj := map[string]interface{}{}
+	for _, a := range attrs {
+		obj[prefix+clarkName(a.Name)] = a.Value
+	}
+	var (
+		text  strings.Builder
+		names = map[string][]interface{}{}
+		order []string
+	)
+	for _, nd := range children {
+		switch t := nd.tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			name := clarkName(t.Name)
+			if _, ok := names[name]; !ok {
+				order = append(order, name)
+			}
+			names[name] = append(names[name], c.value(nd))
+		}
+	}
+	if text.Len() > 0 {
+		obj[textKey] = text.String()
+	}
+	for _, name := range order {
+		if vs := names[name]; len(vs) == 1 && (doc || !c.Arrays) {
+			obj[name] = vs[0]
+		} else {
+			obj[name] = vs
+		}
+	}
+	return obj
+}
+
+// value projects the element nd.
+func (c JSONConvention) value(nd *node) interface{} {
+	start := nd.tok.(xml.StartElement)
+	if len(start.Attr) == 0 {
+		var text strings.Builder
+		simple := true
+		for _, child := range nd.children {
+			switch t := child.tok.(type) {
+			case xml.CharData:
+				text.Write(t)
+			case xml.StartElement:
+				simple = false
+			}
+		}
+		if simple {
+			return text.String()
+		}
+	}
+	return c.object(start.Attr, nd.children, false)
+}
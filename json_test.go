@@ -0,0 +1,111 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	testCases := []struct {
+		desc string
+		a, b string
+		want string
+	}{{
+		desc: "equal",
+		a:    `<root/>`,
+		b:    `<root></root>`,
+		want: `{"equal":true,"differences":[],"causes":{}}`,
+	}, {
+		desc: "different",
+		a:    `<root a="1"><b/></root>`,
+		b:    `<root a="2"/>`,
+		want: `{"equal":false,"differences":[` +
+			`{"path":"/root/@a","a":"\"1\"","b":"\"2\"","cause":"text","message":"/root/@a: \"1\" != \"2\""},` +
+			`{"path":"/root/b","a":"\u003cb\u003e","cause":"structure","message":"/root/b: only in a: \u003cb\u003e"}` +
+			`],"causes":{"structure":1,"text":1}}`,
+	}}
+
+	for _, tc := range testCases {
+		var n Normalizer
+		got, err := n.DiffJSON(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+		want string
+	}{{
+		desc: "text",
+		in:   `<root>x</root>`,
+		want: `{"root":"x"}`,
+	}, {
+		desc: "empty",
+		in:   `<root/>`,
+		want: `{"root":""}`,
+	}, {
+		desc: "attributes and text",
+		in:   `<root b="2" a="1">x</root>`,
+		want: `{"root":{"#text":"x","@a":"1","@b":"2"}}`,
+	}, {
+		desc: "children",
+		in:   `<root><b>1</b><a/><b>2</b></root>`,
+		want: `{"root":{"a":"","b":["1","2"]}}`,
+	}, {
+		desc: "namespaces and comments",
+		in:   `<root xmlns="urn:x" xmlns:p="urn:p" p:a="1"><!-- c --><b>&lt;</b></root>`,
+		want: `{"{urn:x}root":{"@{urn:p}a":"1","{urn:x}b":"<"}}`,
+	}, {
+		desc: "normalized",
+		n:    Normalizer{OmitWhitespace: true, SortElements: true},
+		in:   "<root>\n <b>2</b>\n <b>1</b>\n</root>",
+		want: `{"root":{"b":["1","2"]}}`,
+	}, {
+		desc: "conventions",
+		n:    Normalizer{JSON: JSONConvention{AttrPrefix: "-", TextKey: "_", Arrays: true}},
+		in:   `<root a="1">x<b/></root>`,
+		want: `{"root":{"-a":"1","_":"x","b":[""]}}`,
+	}, {
+		desc: "multiple roots",
+		n:    Normalizer{AllowMultipleRoots: true},
+		in:   `<a/><a>x</a>`,
+		want: `{"a":["","x"]}`,
+	}}
+	for _, tc := range testCases {
+		var b bytes.Buffer
+		if err := tc.n.ToJSON(strings.NewReader(tc.in), &b); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		var got bytes.Buffer
+		if err := json.Compact(&got, b.Bytes()); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got.String(), tc.want)
+		}
+	}
+}
+
+func TestToJSONError(t *testing.T) {
+	var n Normalizer
+	if err := n.ToJSON(strings.NewReader(`<a>`), new(bytes.Buffer)); err == nil {
+		t.Errorf("got nil error, want error")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// isLangAttr reports whether name is xml:lang. The prefix of token
+// streams that do not resolve namespaces is accepted, too.
+func isLangAttr(name xml.Name) bool {
+	return name.Local == "lang" && (name.Space == xmlURL || name.Space == "xml")
+}
+
+// canonicalLang returns the language tag s in the case conventions of BCP
+// 47: the language lowercase, a script titlecase and a region uppercase.
+// Subtags after the first singleton, such as those of extensions and
+// private use, are lowercase. If primary, canonicalLang returns just the
+// primary language subtag.
+func canonicalLang(s string, primary bool) string {
+	subtags := strings.Split(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"), "-")
+	singleton := false
+	for i, t := range subtags {
+		t = strings.ToLower(t)
+		switch {
+		case i == 0 || singleton:
+		case len(t) == 1:
+			singleton = true
+		case len(t) == 2:
+			t = strings.ToUpper(t)
+		case len(t) == 4:
+			t = strings.ToUpper(t[:1]) + t[1:]
+		}
+		subtags[i] = t
+	}
+	if primary {
+		return subtags[0]
+	}
+	return strings.Join(subtags, "-")
+}
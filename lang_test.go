@@ -0,0 +1,86 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalLang(t *testing.T) {
+	testCases := []struct {
+		in, want, primary string
+	}{
+		{"en", "en", "en"},
+		{"EN-us", "en-US", "en"},
+		{"en_gb", "en-GB", "en"},
+		{"zh-hant-tw", "zh-Hant-TW", "zh"},
+		{"es-419", "es-419", "es"},
+		{"de-CH-1996", "de-CH-1996", "de"},
+		{"en-US-x-Twain-AB", "en-US-x-twain-ab", "en"},
+		{"X-Klingon", "x-klingon", "x"},
+		{" fr ", "fr", "fr"},
+		{"", "", ""},
+	}
+	for _, tc := range testCases {
+		if got := canonicalLang(tc.in, false); got != tc.want {
+			t.Errorf("canonicalLang(%q, false): got %q, want %q", tc.in, got, tc.want)
+		}
+		if got := canonicalLang(tc.in, true); got != tc.primary {
+			t.Errorf("canonicalLang(%q, true): got %q, want %q", tc.in, got, tc.primary)
+		}
+	}
+}
+
+func TestLang(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		n         Normalizer
+		a, b      string
+		wantEqual bool
+	}{{
+		desc: "case differs by default",
+		a:    `<a xml:lang="en-us"/>`,
+		b:    `<a xml:lang="en-US"/>`,
+	}, {
+		desc:      "case ignored",
+		n:         Normalizer{NormalizeLang: true},
+		a:         `<a xml:lang="en-us"><b xml:lang="ZH-HANT"/></a>`,
+		b:         `<a xml:lang="en-US"><b xml:lang="zh-Hant"/></a>`,
+		wantEqual: true,
+	}, {
+		desc: "region differs",
+		n:    Normalizer{NormalizeLang: true},
+		a:    `<a xml:lang="en"/>`,
+		b:    `<a xml:lang="en-US"/>`,
+	}, {
+		desc:      "region ignored",
+		n:         Normalizer{IgnoreLangSubtags: true},
+		a:         `<a xml:lang="en"/>`,
+		b:         `<a xml:lang="EN-us"/>`,
+		wantEqual: true,
+	}, {
+		desc: "language differs",
+		n:    Normalizer{IgnoreLangSubtags: true},
+		a:    `<a xml:lang="en-US"/>`,
+		b:    `<a xml:lang="de-US"/>`,
+	}, {
+		desc: "other lang attributes are kept",
+		n:    Normalizer{NormalizeLang: true},
+		a:    `<a lang="en-us"/>`,
+		b:    `<a lang="en-US"/>`,
+	}}
+
+	for _, tc := range testCases {
+		got, err := tc.n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got != tc.wantEqual {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.wantEqual)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// CheckLaws checks that the EqualXML method of n satisfies the laws of
+// equality on all documents and pairs of documents of corpus, and reports
+// counterexamples to t. A nil n is the zero Normalizer. The laws are
+//
+//   - reflexivity: a document equals itself,
+//   - symmetry: EqualXML(a, b) equals EqualXML(b, a), and
+//   - consistency: documents with the same Normalize output are equal,
+//     and, unless n compares values approximately, equal documents have
+//     the same Normalize output.
+//
+// Documents that n fails to normalize are skipped.
+func CheckLaws(t testing.TB, n *Normalizer, corpus [][]byte) {
+	t.Helper()
+	if n == nil {
+		n = new(Normalizer)
+	}
+	for _, err := range n.lawViolations(corpus) {
+		t.Error(err)
+	}
+}
+
+// approximate reports whether n compares values approximately, so that
+// EqualXML may report documents with different normalized output as equal.
+func (n *Normalizer) approximate() bool {
+	return n.NumberTolerance != nil || n.CompareTimestamps || len(n.NumericAttrs) > 0
+}
+
+func (n *Normalizer) lawViolations(corpus [][]byte) []error {
+	var (
+		docs [][]byte
+		outs [][]byte
+		errs []error
+	)
+	for _, doc := range corpus {
+		var out bytes.Buffer
+		if err := n.Normalize(&out, bytes.NewReader(doc)); err != nil {
+			continue
+		}
+		docs = append(docs, doc)
+		outs = append(outs, out.Bytes())
+	}
+	equal := func(a, b []byte) bool {
+		eq, err := n.EqualXML(bytes.NewReader(a), bytes.NewReader(b))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("xmltest: EqualXML failed on normalized documents: %v\na: %q\nb: %q", err, a, b))
+		}
+		return eq
+	}
+	for i, a := range docs {
+		if !equal(a, a) {
+			errs = append(errs, fmt.Errorf("xmltest: EqualXML is not reflexive\ndoc: %q", a))
+		}
+		for j := i + 1; j < len(docs); j++ {
+			b := docs[j]
+			ab, ba := equal(a, b), equal(b, a)
+			if ab != ba {
+				errs = append(errs, fmt.Errorf("xmltest: EqualXML is not symmetric: EqualXML(a, b) = %v, EqualXML(b, a) = %v\na: %q\nb: %q", ab, ba, a, b))
+			}
+			same := bytes.Equal(outs[i], outs[j])
+			if same && !ab || !same && ab && !n.approximate() {
+				errs = append(errs, fmt.Errorf("xmltest: EqualXML = %v is inconsistent with Normalize\na: %q\nb: %q\nnormalized a: %q\nnormalized b: %q", ab, a, b, outs[i], outs[j]))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestCheckLaws(t *testing.T) {
+	g := Generator{Seed: 3, MaxDepth: 2, Names: []string{"a", "b"}, Alphabet: "xy 1"}
+	var corpus [][]byte
+	for i := 0; i < 30; i++ {
+		corpus = append(corpus, g.Generate())
+	}
+	corpus = append(corpus, []byte(`<a>1</a>`), []byte(`<a>1.0</a>`), []byte(`<a`))
+	CheckLaws(t, nil, corpus)
+	CheckLaws(t, &Normalizer{SortElements: true, OmitWhitespace: true}, corpus)
+	CheckLaws(t, &Normalizer{NumberTolerance: &Tolerance{}}, corpus)
+}
+
+func TestLawViolations(t *testing.T) {
+	// A filter that depends on state breaks consistency between
+	// Normalize and EqualXML.
+	calls := 0
+	n := Normalizer{Filters: []TokenFilter{func(path []xml.Name, t xml.Token) (xml.Token, bool) {
+		if _, ok := t.(xml.CharData); ok {
+			calls++
+			return xml.CharData([]byte{byte('0' + calls%2)}), true
+		}
+		return t, true
+	}}}
+	if errs := n.lawViolations([][]byte{[]byte(`<a>x</a>`), []byte(`<a>y</a>`)}); len(errs) == 0 {
+		t.Errorf("got no violations, want some")
+	}
+}
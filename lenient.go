@@ -0,0 +1,180 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Issue describes a problem in the input that NormalizeLenient worked
+// around.
+type Issue struct {
+	Line, Column int
+	Msg          string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, i.Msg)
+}
+
+// NormalizeLenient is like Normalize but keeps going where the input is
+// not well-formed. It
+//
+//   - skips end tags that match no open element,
+//   - closes open elements that an end tag of an enclosing element skips,
+//   - skips other malformed markup up to the next tag, and
+//   - closes the elements still open at the end of the input.
+//
+// It returns the problems worked around as issues. The error is only
+// non-nil if the input can not be read or decompressed, the partial output
+// can not be written, or a rule of the Normalizer fails. The input is read
+// into memory. Input read by a Decoder stops at its first syntax error.
+func (n *Normalizer) NormalizeLenient(w io.Writer, r io.Reader) ([]Issue, error) {
+	r, err := n.decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	src, isUTF16 := transcode(bufio.NewReader(r))
+	b, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	lr := &lenientReader{n: n, src: b, isUTF16: isUTF16, line: 1, col: 1}
+	lr.d = lr.newDecoder()
+	err = n.NormalizeTokens(w, lr)
+	return lr.issues, err
+}
+
+// lenientReader reads raw tokens and repairs their nesting.
+type lenientReader struct {
+	n *Normalizer
+	d *xml.Decoder
+	// src holds the UTF-8 input, of which d reads from offset off on, which
+	// is at line and col.
+	src       []byte
+	isUTF16   bool
+	off       int
+	line, col int
+	stack     []xml.Name
+	pending   []xml.Token
+	done      bool
+	issues    []Issue
+}
+
+// newDecoder returns a decoder of the input from lr.off on.
+func (lr *lenientReader) newDecoder() *xml.Decoder {
+	r := bytes.NewReader(lr.src[lr.off:])
+	if lr.n.Decoder != nil {
+		return xml.NewTokenDecoder(lr.n.Decoder(r))
+	}
+	d := xml.NewDecoder(r)
+	if lr.isUTF16 {
+		d.CharsetReader = utf16Charset
+	}
+	return d
+}
+
+func (lr *lenientReader) issue(format string, args ...interface{}) {
+	line, col := lr.d.InputPos()
+	if line == 1 {
+		col += lr.col - 1
+	}
+	line += lr.line - 1
+	lr.issues = append(lr.issues, Issue{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)})
+}
+
+// resync makes lr read from the next tag after the syntax error of its
+// decoder, and reports whether there is one.
+func (lr *lenientReader) resync() bool {
+	if lr.n.Decoder != nil {
+		return false
+	}
+	// The decoder may have consumed the '<' of the next tag.
+	from := lr.off + int(lr.d.InputOffset()) - 1
+	if from <= lr.off {
+		from = lr.off + 1
+	}
+	if from >= len(lr.src) {
+		return false
+	}
+	i := bytes.IndexByte(lr.src[from:], '<')
+	if i < 0 {
+		return false
+	}
+	for _, c := range lr.src[lr.off : from+i] {
+		if c == '\n' {
+			lr.line, lr.col = lr.line+1, 1
+		} else if c < 0x80 || c >= 0xc0 {
+			lr.col++
+		}
+	}
+	lr.off = from + i
+	lr.d = lr.newDecoder()
+	return true
+}
+
+func (lr *lenientReader) Token() (xml.Token, error) {
+	for len(lr.pending) == 0 {
+		if lr.done {
+			return nil, io.EOF
+		}
+		lr.read()
+	}
+	t := lr.pending[0]
+	lr.pending = lr.pending[1:]
+	return t, nil
+}
+
+// read reads the next raw token and queues it along with the end tags
+// needed to keep the tokens balanced.
+func (lr *lenientReader) read() {
+	t, err := lr.d.RawToken()
+	if err != nil {
+		var serr *xml.SyntaxError
+		switch {
+		case errors.As(err, &serr):
+			// The line of the error is relative to the decoder.
+			lr.issue("%s", serr.Msg)
+			if lr.resync() {
+				return
+			}
+		case err != io.EOF:
+			lr.issue("%v", err)
+		}
+		lr.done = true
+		for i := len(lr.stack) - 1; i >= 0; i-- {
+			if err == io.EOF {
+				lr.issue("unclosed element <%s>", rawName(lr.stack[i]))
+			}
+			lr.pending = append(lr.pending, xml.EndElement{Name: lr.stack[i]})
+		}
+		lr.stack = nil
+		return
+	}
+	switch t := t.(type) {
+	case xml.StartElement:
+		lr.stack = append(lr.stack, t.Name)
+	case xml.EndElement:
+		i := len(lr.stack) - 1
+		for i >= 0 && lr.stack[i] != t.Name {
+			i--
+		}
+		if i < 0 {
+			lr.issue("skipped unexpected end tag </%s>", rawName(t.Name))
+			return
+		}
+		for j := len(lr.stack) - 1; j > i; j-- {
+			lr.issue("element <%s> closed by </%s>", rawName(lr.stack[j]), rawName(t.Name))
+			lr.pending = append(lr.pending, xml.EndElement{Name: lr.stack[j]})
+		}
+		lr.stack = lr.stack[:i]
+	}
+	lr.pending = append(lr.pending, xml.CopyToken(t))
+}
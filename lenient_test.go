@@ -0,0 +1,82 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLenient(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		in         string
+		wantXML    string
+		wantIssues []string
+	}{{
+		desc:    "well-formed",
+		in:      `<root b="2" a="1"/>`,
+		wantXML: `<root a="1" b="2"></root>`,
+	}, {
+		desc:       "unexpected end tag",
+		in:         `<root><a></b></a></root>`,
+		wantXML:    `<root><a></a></root>`,
+		wantIssues: []string{"1:14: skipped unexpected end tag </b>"},
+	}, {
+		desc:       "end tag of enclosing element",
+		in:         `<root><a><b>x</root>`,
+		wantXML:    `<root><a><b>x</b></a></root>`,
+		wantIssues: []string{"1:21: element <b> closed by </root>", "1:21: element <a> closed by </root>"},
+	}, {
+		desc:       "unclosed elements",
+		in:         "<root>\n<a>",
+		wantXML:    "<root>\n<a></a></root>",
+		wantIssues: []string{"2:4: unclosed element <a>", "2:4: unclosed element <root>"},
+	}, {
+		desc:       "skip to the next tag after syntax error",
+		in:         `<root><a>x</a><b <c/></root>`,
+		wantXML:    `<root><a>x</a><c></c></root>`,
+		wantIssues: []string{"1:18: expected attribute name in element"},
+	}, {
+		desc:    "several syntax errors",
+		in:      "<root>\n<a x=1/>\n<b>&nope;</b><c/></root>",
+		wantXML: "<root>\n<b></b><c></c></root>",
+		wantIssues: []string{
+			"2:7: unquoted or missing attribute value in element",
+			"3:10: invalid character entity &nope;",
+		},
+	}, {
+		desc:       "gzip",
+		in:         gzipped(`<root><a></b></root>`),
+		wantXML:    `<root><a></a></root>`,
+		wantIssues: []string{"1:14: skipped unexpected end tag </b>", "1:21: element <a> closed by </root>"},
+	}, {
+		desc:       "UTF-16",
+		in:         encodeUTF16("\ufeff<?xml version=\"1.0\" encoding=\"UTF-16\"?><root>\u00e9<a></root>", false),
+		wantXML:    "<root>\u00e9<a></a></root>",
+		wantIssues: []string{"1:58: element <a> closed by </root>"},
+	}}
+
+	for _, tc := range testCases {
+		n := Normalizer{Decompress: true}
+		var b strings.Builder
+		issues, err := n.NormalizeLenient(&b, strings.NewReader(tc.in))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.wantXML {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.wantXML)
+		}
+		var got []string
+		for _, i := range issues {
+			got = append(got, i.String())
+		}
+		if !reflect.DeepEqual(got, tc.wantIssues) {
+			t.Errorf("%s: got issues %q, want %q", tc.desc, got, tc.wantIssues)
+		}
+	}
+}
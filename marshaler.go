@@ -0,0 +1,139 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// marshalRuns is the number of times TestMarshaler marshals a value to
+// check that its encoding is deterministic.
+const marshalRuns = 10
+
+// TestMarshaler checks that the custom MarshalXML implementation of v
+// conforms to the rules of encoding/xml. It reports an error to t if
+//
+//   - v fails to marshal,
+//   - the output is not well-formed XML,
+//   - a namespace prefix is used outside the scope of its declaration,
+//   - start and end tags do not nest correctly, or
+//   - marshaling v repeatedly yields different output.
+func TestMarshaler(t *testing.T, v xml.Marshaler) {
+	t.Helper()
+	for _, err := range checkMarshaler(v) {
+		t.Error(err)
+	}
+}
+
+func checkMarshaler(v xml.Marshaler) []error {
+	first, err := xml.Marshal(v)
+	if err != nil {
+		return []error{fmt.Errorf("xmltest: marshal failed: %v", err)}
+	}
+	var errs []error
+	if err := checkWellFormed(first); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkNesting(first); err != nil {
+		errs = append(errs, err)
+	}
+	for i := 1; i < marshalRuns; i++ {
+		b, err := xml.Marshal(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("xmltest: marshal run %d failed: %v", i+1, err))
+			break
+		}
+		if !bytes.Equal(first, b) {
+			errs = append(errs, fmt.Errorf("xmltest: marshal run %d is not deterministic:\ngot  %s\nwant %s", i+1, b, first))
+			break
+		}
+	}
+	return errs
+}
+
+// checkWellFormed reports if b is not well-formed XML according to a
+// strict decoder.
+func checkWellFormed(b []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("xmltest: output is not well-formed: %v", err)
+		}
+	}
+}
+
+// checkNesting reports if the raw tokens of b use undeclared namespace
+// prefixes or if end tags do not match their start tags literally.
+func checkNesting(b []byte) error {
+	type scope struct {
+		name     xml.Name
+		prefixes []string
+	}
+	var (
+		stack    []scope
+		declared = map[string]int{"xml": 1, "xmlns": 1}
+	)
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("xmltest: output is not well-formed: %v", err)
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			s := scope{name: t.Name}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					declared[a.Name.Local]++
+					s.prefixes = append(s.prefixes, a.Name.Local)
+				}
+			}
+			stack = append(stack, s)
+			if p := t.Name.Space; p != "" && declared[p] == 0 {
+				return fmt.Errorf("xmltest: element <%s:%s> uses undeclared prefix %q", p, t.Name.Local, p)
+			}
+			for _, a := range t.Attr {
+				if p := a.Name.Space; p != "" && declared[p] == 0 {
+					return fmt.Errorf("xmltest: attribute %s:%s uses undeclared prefix %q", p, a.Name.Local, p)
+				}
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return fmt.Errorf("xmltest: unexpected end tag </%s>", rawName(t.Name))
+			}
+			s := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if s.name != t.Name {
+				return fmt.Errorf("xmltest: end tag </%s> does not match start tag <%s>", rawName(t.Name), rawName(s.name))
+			}
+			for _, p := range s.prefixes {
+				declared[p]--
+			}
+		}
+	}
+	if len(stack) != 0 {
+		return fmt.Errorf("xmltest: unclosed start tag <%s>", rawName(stack[len(stack)-1].name))
+	}
+	return nil
+}
+
+// rawName formats a name returned by xml.Decoder.RawToken.
+func rawName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type tokensMarshaler []xml.Token
+
+func (m tokensMarshaler) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	for _, t := range m {
+		if err := e.EncodeToken(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type counterMarshaler struct{ n *int }
+
+func (m counterMarshaler) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	*m.n++
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "n"}, Value: strconv.Itoa(*m.n)}}
+	return e.EncodeElement("", start)
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return errors.New("no way")
+}
+
+func TestCheckMarshaler(t *testing.T) {
+	root := xml.Name{Space: "space", Local: "root"}
+	testCases := []struct {
+		desc    string
+		v       xml.Marshaler
+		wantErr bool
+	}{{
+		desc: "well-behaved marshaler",
+		v: tokensMarshaler{
+			xml.StartElement{Name: root},
+			xml.CharData("hello"),
+			xml.EndElement{Name: root},
+		},
+	}, {
+		desc: "undeclared attribute prefix",
+		v: tokensMarshaler{
+			xml.StartElement{Name: root, Attr: []xml.Attr{{Name: xml.Name{Local: "p:a"}, Value: "v"}}},
+			xml.EndElement{Name: root},
+		},
+		wantErr: true,
+	}, {
+		desc:    "non-deterministic output",
+		v:       counterMarshaler{n: new(int)},
+		wantErr: true,
+	}, {
+		desc:    "marshal error",
+		v:       failingMarshaler{},
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		errs := checkMarshaler(tc.v)
+		if tc.wantErr && len(errs) == 0 {
+			t.Errorf("%s: got no errors, want some", tc.desc)
+		}
+		if !tc.wantErr && len(errs) != 0 {
+			t.Errorf("%s: got errors %v, want none", tc.desc, errs)
+		}
+	}
+}
+
+func TestTestMarshaler(t *testing.T) {
+	TestMarshaler(t, tokensMarshaler{
+		xml.StartElement{Name: xml.Name{Local: "root"}},
+		xml.EndElement{Name: xml.Name{Local: "root"}},
+	})
+}
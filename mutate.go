@@ -0,0 +1,228 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math/rand"
+)
+
+// maxMutateAttempts limits the attempts to find a mutation that changes
+// the normalized content of a document.
+const maxMutateAttempts = 100
+
+var errNothingToMutate = errors.New("xmltest: document has nothing to mutate")
+
+// Mutate returns n variants of the XML document doc. Each variant applies
+// one random mutation that changes its normalized content under the zero
+// Normalizer:
+//
+//   - an attribute value is changed,
+//   - two sibling elements are swapped,
+//   - character data is changed, or
+//   - a namespace declaration is renamed to a different URI.
+//
+// The variants are determined by seed. Mutate fails if doc is not
+// well-formed or has nothing to mutate.
+func Mutate(doc io.Reader, n int, seed int64) ([][]byte, error) {
+	b, err := io.ReadAll(doc)
+	if err != nil {
+		return nil, err
+	}
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	raw, err := rawTokens(b)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		rnd      = rand.New(rand.NewSource(seed))
+		zero     Normalizer
+		variants [][]byte
+	)
+	for len(variants) < n {
+		found := false
+		for attempt := 0; attempt < maxMutateAttempts && !found; attempt++ {
+			nodes := buildTree(copyTokens(raw))
+			if !mutate(rnd, nodes) {
+				return nil, errNothingToMutate
+			}
+			var v bytes.Buffer
+			for _, nd := range nodes {
+				writeRaw(&v, nd)
+			}
+			if eq, err := zero.EqualXML(bytes.NewReader(b), bytes.NewReader(v.Bytes())); err == nil && !eq {
+				variants = append(variants, v.Bytes())
+				found = true
+			}
+		}
+		if !found {
+			return nil, errNothingToMutate
+		}
+	}
+	return variants, nil
+}
+
+// rawTokens returns copies of the raw tokens of b.
+func rawTokens(b []byte) ([]xml.Token, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var toks []xml.Token
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, xml.CopyToken(t))
+	}
+}
+
+func copyTokens(toks []xml.Token) []xml.Token {
+	c := make([]xml.Token, len(toks))
+	for i, t := range toks {
+		c[i] = xml.CopyToken(t)
+	}
+	return c
+}
+
+// mutation candidates of a raw document tree.
+type mutationTargets struct {
+	attrs   []*xml.Attr
+	decls   []*xml.Attr
+	parents []*node
+	texts   []*node
+}
+
+func (m *mutationTargets) collect(nodes []*node) {
+	elems := 0
+	for _, nd := range nodes {
+		switch t := nd.tok.(type) {
+		case xml.StartElement:
+			elems++
+			for i := range t.Attr {
+				a := &t.Attr[i]
+				if a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns" {
+					m.decls = append(m.decls, a)
+				} else {
+					m.attrs = append(m.attrs, a)
+				}
+			}
+			m.collect(nd.children)
+			if countElements(nd.children) >= 2 {
+				m.parents = append(m.parents, nd)
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				m.texts = append(m.texts, nd)
+			}
+		}
+	}
+}
+
+func countElements(nodes []*node) int {
+	n := 0
+	for _, nd := range nodes {
+		if nd.isElement() {
+			n++
+		}
+	}
+	return n
+}
+
+// mutate applies a random mutation to nodes. It reports false if there is
+// nothing to mutate.
+func mutate(rnd *rand.Rand, nodes []*node) bool {
+	var m mutationTargets
+	m.collect(nodes)
+	var kinds []func()
+	if len(m.attrs) > 0 {
+		kinds = append(kinds, func() {
+			a := m.attrs[rnd.Intn(len(m.attrs))]
+			a.Value += "~"
+		})
+	}
+	if len(m.decls) > 0 {
+		kinds = append(kinds, func() {
+			a := m.decls[rnd.Intn(len(m.decls))]
+			a.Value += "-renamed"
+		})
+	}
+	if len(m.parents) > 0 {
+		kinds = append(kinds, func() {
+			p := m.parents[rnd.Intn(len(m.parents))]
+			var elems []int
+			for i, c := range p.children {
+				if c.isElement() {
+					elems = append(elems, i)
+				}
+			}
+			i := rnd.Intn(len(elems))
+			j := (i + 1 + rnd.Intn(len(elems)-1)) % len(elems)
+			a, b := elems[i], elems[j]
+			p.children[a], p.children[b] = p.children[b], p.children[a]
+		})
+	}
+	if len(m.texts) > 0 {
+		kinds = append(kinds, func() {
+			nd := m.texts[rnd.Intn(len(m.texts))]
+			r := []rune(string(nd.tok.(xml.CharData)))
+			i := rnd.Intn(len(r))
+			if r[i] == 'x' {
+				r[i] = 'y'
+			} else {
+				r[i] = 'x'
+			}
+			nd.tok = xml.CharData(string(r))
+		})
+	}
+	if len(kinds) == 0 {
+		return false
+	}
+	kinds[rnd.Intn(len(kinds))]()
+	return true
+}
+
+// writeRaw writes the raw tree nd as XML.
+func writeRaw(b *bytes.Buffer, nd *node) {
+	switch t := nd.tok.(type) {
+	case xml.StartElement:
+		b.WriteString("<" + rawName(t.Name))
+		for _, a := range t.Attr {
+			b.WriteString(" " + rawName(a.Name) + `="`)
+			xml.EscapeText(b, []byte(a.Value))
+			b.WriteString(`"`)
+		}
+		b.WriteString(">")
+		for _, c := range nd.children {
+			writeRaw(b, c)
+		}
+		b.WriteString("</" + rawName(t.Name) + ">")
+	case xml.CharData:
+		xml.EscapeText(b, t)
+	case xml.Comment:
+		b.WriteString("<!--")
+		b.Write(t)
+		b.WriteString("-->")
+	case xml.ProcInst:
+		b.WriteString("<?" + t.Target + " ")
+		b.Write(t.Inst)
+		b.WriteString("?>")
+	case xml.Directive:
+		b.WriteString("<!")
+		b.Write(t)
+		b.WriteString(">")
+	}
+}
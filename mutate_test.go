@@ -0,0 +1,42 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+	doc := `<?xml version="1.0"?><p:root xmlns:p="urn:p" a="1"><b>text</b><c/><!--x--></p:root>`
+	variants, err := Mutate(strings.NewReader(doc), 20, 1)
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if len(variants) != 20 {
+		t.Fatalf("got %d variants, want 20", len(variants))
+	}
+	var n Normalizer
+	for i, v := range variants {
+		eq, err := n.EqualXML(strings.NewReader(doc), bytes.NewReader(v))
+		if err != nil || eq {
+			t.Errorf("#%d: EqualXML got %v, %v, want false, nil\n%s", i, eq, err, v)
+		}
+	}
+	again, err := Mutate(strings.NewReader(doc), 20, 1)
+	if err != nil || !reflect.DeepEqual(again, variants) {
+		t.Errorf("not deterministic for the same seed")
+	}
+}
+
+func TestMutateErrors(t *testing.T) {
+	for _, doc := range []string{`<root/>`, `<root>`, `<root> </root>`} {
+		if _, err := Mutate(strings.NewReader(doc), 1, 1); err == nil {
+			t.Errorf("%s: got nil error, want some", doc)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlnsURL is the namespace of namespace declarations.
+const xmlnsURL = "http://www.w3.org/2000/xmlns/"
+
+// NamespaceViolation is a violation of the Namespaces in XML
+// recommendation.
+type NamespaceViolation struct {
+	// Path locates the element or attribute, such as /root/a/@p:b, and
+	// Line and Column the start of its tag.
+	Path         string
+	Line, Column int
+	Msg          string
+}
+
+// NamespaceError reports the violations found by CheckNamespaces, in
+// document order.
+type NamespaceError struct {
+	Violations []NamespaceViolation
+}
+
+func (e *NamespaceError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "xmltest: %d namespace violations:", len(e.Violations))
+	for _, v := range e.Violations {
+		fmt.Fprintf(&b, "\n\t%d:%d: %s: %s", v.Line, v.Column, v.Path, v.Msg)
+	}
+	return b.String()
+}
+
+// CheckNamespaces verifies that the document r is namespace-well-formed,
+// which encoding/xml does not fully enforce, and reports the violations
+// with a *NamespaceError. It reports undeclared prefixes, prefixes bound to
+// the empty namespace name, colons in local names such as in <:a/>,
+// declarations that misuse the reserved xml and xmlns prefixes or their
+// namespaces, and attributes of an element that have the same expanded
+// name.
+func CheckNamespaces(r io.Reader) error {
+	d := xml.NewDecoder(r)
+	var (
+		vs    []NamespaceViolation
+		path  []xml.Name
+		raw   []xml.Name
+		scope []map[string]string
+	)
+	// resolve returns the namespace bound to prefix, and whether it is
+	// declared.
+	resolve := func(prefix string) (string, bool) {
+		switch prefix {
+		case "xml":
+			return xmlURL, true
+		case "xmlns":
+			return xmlnsURL, true
+		}
+		for i := len(scope) - 1; i >= 0; i-- {
+			if uri, ok := scope[i][prefix]; ok {
+				return uri, uri != "" || prefix == ""
+			}
+		}
+		return "", prefix == ""
+	}
+	for {
+		line, col := d.InputPos()
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var serr *xml.SyntaxError
+			if errors.As(err, &serr) {
+				line, col := d.InputPos()
+				return &SyntaxError{Line: line, Column: col, Err: err}
+			}
+			return err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			report := func(p, format string, args ...interface{}) {
+				vs = append(vs, NamespaceViolation{Path: p, Line: line, Column: col, Msg: fmt.Sprintf(format, args...)})
+			}
+			scope = append(scope, namespaceDecls(t))
+			name, ok := resolve(t.Name.Space)
+			path = append(path, xml.Name{Space: name, Local: t.Name.Local})
+			if !ok {
+				path[len(path)-1] = xml.Name{Local: qualifiedName(t.Name)}
+			}
+			raw = append(raw, t.Name)
+			p := formatPath(path)
+			switch {
+			case !ok:
+				report(p, "undeclared prefix %s", t.Name.Space)
+			case t.Name.Space == "xmlns":
+				report(p, "element with xmlns prefix")
+			}
+			if strings.Contains(t.Name.Local, ":") {
+				report(p, "colon in local name %s", t.Name.Local)
+			}
+			seen := map[xml.Name]bool{}
+			for _, a := range t.Attr {
+				ap := p + "/@" + qualifiedName(a.Name)
+				if strings.Contains(a.Name.Local, ":") {
+					report(ap, "colon in local name %s", a.Name.Local)
+				}
+				switch {
+				case a.Name.Space == "xmlns":
+					if msg := checkDecl(a.Name.Local, a.Value); msg != "" {
+						report(ap, "%s", msg)
+					}
+					continue
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					if msg := checkDecl("", a.Value); msg != "" {
+						report(ap, "%s", msg)
+					}
+					continue
+				}
+				// Unprefixed attributes are in no namespace.
+				expanded := a.Name
+				if a.Name.Space != "" {
+					uri, ok := resolve(a.Name.Space)
+					if !ok {
+						report(ap, "undeclared prefix %s", a.Name.Space)
+						continue
+					}
+					expanded.Space = uri
+				}
+				if seen[expanded] {
+					report(ap, "duplicate attribute %s", clarkName(expanded))
+				}
+				seen[expanded] = true
+			}
+		case xml.EndElement:
+			if len(raw) == 0 || raw[len(raw)-1] != t.Name {
+				return &SyntaxError{Line: line, Column: col, Err: &xml.SyntaxError{Msg: "unexpected end element </" + qualifiedName(t.Name) + ">", Line: line}}
+			}
+			path, raw, scope = path[:len(path)-1], raw[:len(raw)-1], scope[:len(scope)-1]
+		}
+	}
+	if len(vs) > 0 {
+		return &NamespaceError{Violations: vs}
+	}
+	return nil
+}
+
+// checkDecl returns why the declaration of prefix, or of the default
+// namespace for the empty prefix, for uri is not allowed, if it is not.
+func checkDecl(prefix, uri string) string {
+	switch {
+	case prefix == "xmlns":
+		return "xmlns prefix declared"
+	case prefix == "xml" && uri != xmlURL:
+		return "xml prefix bound to " + uri
+	case prefix != "xml" && uri == xmlURL:
+		return "xml namespace bound to prefix other than xml"
+	case uri == xmlnsURL:
+		return "xmlns namespace declared"
+	case prefix != "" && uri == "":
+		return "prefix " + prefix + " bound to empty namespace name"
+	}
+	return ""
+}
+
+// qualifiedName returns the prefixed form of the raw name.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,96 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckNamespaces(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		in      string
+		want    []NamespaceViolation
+		wantErr bool
+	}{{
+		desc: "well-formed",
+		in:   `<a xmlns="urn:d" xmlns:p="urn:p" xml:lang="en"><p:b p:c="1" c="2"/><xml:x/></a>`,
+	}, {
+		desc: "undeclared prefixes",
+		in:   "<a>\n  <p:b q:c=\"1\"/>\n</a>",
+		want: []NamespaceViolation{
+			{Path: "/a/p:b", Line: 2, Column: 3, Msg: "undeclared prefix p"},
+			{Path: "/a/p:b/@q:c", Line: 2, Column: 3, Msg: "undeclared prefix q"},
+		},
+	}, {
+		desc: "prefixes are scoped",
+		in:   `<a><b xmlns:p="urn:p"/><p:c/></a>`,
+		want: []NamespaceViolation{
+			{Path: "/a/p:c", Line: 1, Column: 24, Msg: "undeclared prefix p"},
+		},
+	}, {
+		desc: "reserved prefixes and namespaces",
+		in: `<a xmlns:xml="urn:x" xmlns:xmlns="urn:y" xmlns:p="http://www.w3.org/XML/1998/namespace"` +
+			` xmlns="http://www.w3.org/2000/xmlns/"><xmlns:b/></a>`,
+		want: []NamespaceViolation{
+			{Path: "/{http://www.w3.org/2000/xmlns/}a/@xmlns:xml", Line: 1, Column: 1, Msg: "xml prefix bound to urn:x"},
+			{Path: "/{http://www.w3.org/2000/xmlns/}a/@xmlns:xmlns", Line: 1, Column: 1, Msg: "xmlns prefix declared"},
+			{Path: "/{http://www.w3.org/2000/xmlns/}a/@xmlns:p", Line: 1, Column: 1, Msg: "xml namespace bound to prefix other than xml"},
+			{Path: "/{http://www.w3.org/2000/xmlns/}a/@xmlns", Line: 1, Column: 1, Msg: "xmlns namespace declared"},
+			{Path: "/{http://www.w3.org/2000/xmlns/}a/{http://www.w3.org/2000/xmlns/}b", Line: 1, Column: 127, Msg: "element with xmlns prefix"},
+		},
+	}, {
+		desc: "undeclaring a prefix",
+		in:   `<a xmlns:p="urn:p"><b xmlns:p=""><p:c/></b></a>`,
+		want: []NamespaceViolation{
+			{Path: "/a/b/@xmlns:p", Line: 1, Column: 20, Msg: "prefix p bound to empty namespace name"},
+			{Path: "/a/b/p:c", Line: 1, Column: 34, Msg: "undeclared prefix p"},
+		},
+	}, {
+		desc: "colons in local names",
+		in:   `<a><:b c:="1"/></a>`,
+		want: []NamespaceViolation{
+			{Path: "/a/:b", Line: 1, Column: 4, Msg: "colon in local name :b"},
+			{Path: "/a/:b/@c:", Line: 1, Column: 4, Msg: "colon in local name c:"},
+		},
+	}, {
+		desc: "duplicate expanded attribute names",
+		in:   `<a xmlns:p="urn:x" xmlns:q="urn:x" p:b="1" q:b="2" b="3"/>`,
+		want: []NamespaceViolation{
+			{Path: "/a/@q:b", Line: 1, Column: 1, Msg: "duplicate attribute {urn:x}b"},
+		},
+	}, {
+		desc:    "mismatched end element",
+		in:      `<a></b>`,
+		wantErr: true,
+	}, {
+		desc:    "malformed",
+		in:      `<a`,
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		err := CheckNamespaces(strings.NewReader(tc.in))
+		var nerr *NamespaceError
+		switch {
+		case tc.wantErr:
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Errorf("%s: got error %v, want *SyntaxError", tc.desc, err)
+			}
+		case tc.want == nil:
+			if err != nil {
+				t.Errorf("%s: got error %v", tc.desc, err)
+			}
+		case !errors.As(err, &nerr):
+			t.Errorf("%s: got error %v, want *NamespaceError", tc.desc, err)
+		case !reflect.DeepEqual(nerr.Violations, tc.want):
+			t.Errorf("%s: got %+v, want %+v", tc.desc, nerr.Violations, tc.want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option configures a Normalizer. Options that take lists append to the
+// lists of the Normalizer, so options and presets compose.
+//
+// Options that take names accept them in Clark notation, that is {uri}local
+// for names in a namespace, or local for names that match any namespace.
+type Option func(*Normalizer)
+
+// New returns a Normalizer configured by opts.
+func New(opts ...Option) *Normalizer {
+	n := new(Normalizer)
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Normalize writes the normalized XML content of r to w, using a Normalizer
+// configured by opts.
+func Normalize(w io.Writer, r io.Reader, opts ...Option) error {
+	return New(opts...).Normalize(w, r)
+}
+
+// EqualXML tests for equality of the normalized XML contents of a and b,
+// using a Normalizer configured by opts.
+func EqualXML(a, b io.Reader, opts ...Option) (bool, error) {
+	return New(opts...).EqualXML(a, b)
+}
+
+// WithRules sets Normalizer.Rules.
+func WithRules(r Rules) Option { return func(n *Normalizer) { n.Rules = r } }
+
+// OmitWhitespace sets Normalizer.OmitWhitespace.
+func OmitWhitespace() Option { return func(n *Normalizer) { n.OmitWhitespace = true } }
+
+// OmitComments sets Normalizer.OmitComments.
+func OmitComments() Option { return func(n *Normalizer) { n.OmitComments = true } }
+
+// WithSchema sets Normalizer.Schema.
+func WithSchema(s *Schema) Option { return func(n *Normalizer) { n.Schema = s } }
+
+// StructureOnly sets Normalizer.StructureOnly.
+func StructureOnly() Option { return func(n *Normalizer) { n.StructureOnly = true } }
+
+// ResolveURIAttr appends to Normalizer.ResolveURIAttrs.
+func ResolveURIAttr(names ...string) Option {
+	return func(n *Normalizer) { n.ResolveURIAttrs = appendNames(n.ResolveURIAttrs, names) }
+}
+
+// NormalizeLang sets Normalizer.NormalizeLang.
+func NormalizeLang() Option { return func(n *Normalizer) { n.NormalizeLang = true } }
+
+// IgnoreLangSubtags sets Normalizer.IgnoreLangSubtags.
+func IgnoreLangSubtags() Option { return func(n *Normalizer) { n.IgnoreLangSubtags = true } }
+
+// Rename adds a mapping of the name from to the name to to
+// Normalizer.Rename.
+func Rename(from, to string) Option {
+	return func(n *Normalizer) {
+		if n.Rename == nil {
+			n.Rename = map[xml.Name]xml.Name{}
+		}
+		n.Rename[parseName(from)] = parseName(to)
+	}
+}
+
+// CaseInsensitiveNames sets Normalizer.CaseInsensitiveNames.
+func CaseInsensitiveNames() Option { return func(n *Normalizer) { n.CaseInsensitiveNames = true } }
+
+// NormalizeBooleans sets Normalizer.NormalizeBooleans.
+func NormalizeBooleans() Option { return func(n *Normalizer) { n.NormalizeBooleans = true } }
+
+// Base64 appends to Normalizer.Base64Paths.
+func Base64(paths ...string) Option {
+	return func(n *Normalizer) { n.Base64Paths = append(n.Base64Paths, paths...) }
+}
+
+// Base64Digest sets Normalizer.Base64Digest.
+func Base64Digest() Option { return func(n *Normalizer) { n.Base64Digest = true } }
+
+// DecimalComma appends to Normalizer.DecimalCommaPaths.
+func DecimalComma(paths ...string) Option {
+	return func(n *Normalizer) { n.DecimalCommaPaths = append(n.DecimalCommaPaths, paths...) }
+}
+
+// LessAttr sets Normalizer.LessAttr.
+func LessAttr(less func(a, b xml.Attr) bool) Option { return func(n *Normalizer) { n.LessAttr = less } }
+
+// KeepAttrOrder sets Normalizer.KeepAttrOrder.
+func KeepAttrOrder() Option { return func(n *Normalizer) { n.KeepAttrOrder = true } }
+
+// SortElements sets Normalizer.SortElements.
+func SortElements() Option { return func(n *Normalizer) { n.SortElements = true } }
+
+// AllowMultipleRoots sets Normalizer.AllowMultipleRoots.
+func AllowMultipleRoots() Option { return func(n *Normalizer) { n.AllowMultipleRoots = true } }
+
+// Fragment sets Normalizer.Fragment.
+func Fragment() Option { return func(n *Normalizer) { n.Fragment = true } }
+
+// NumberTolerance compares numbers within the absolute tolerance abs or
+// the relative tolerance rel.
+func NumberTolerance(abs, rel float64) Option {
+	return func(n *Normalizer) { n.NumberTolerance = &Tolerance{Abs: abs, Rel: rel} }
+}
+
+// CompareTimestamps compares timestamps that are at most tol apart as
+// equal.
+func CompareTimestamps(tol time.Duration) Option {
+	return func(n *Normalizer) {
+		n.CompareTimestamps = true
+		n.TimestampTolerance = tol
+	}
+}
+
+// TextTransform sets Normalizer.TextTransform.
+func TextTransform(f func(path []xml.Name, s string) string) Option {
+	return func(n *Normalizer) { n.TextTransform = f }
+}
+
+// AttrTransform sets Normalizer.AttrTransform.
+func AttrTransform(f func(elem xml.Name, a xml.Attr) xml.Attr) Option {
+	return func(n *Normalizer) { n.AttrTransform = f }
+}
+
+// Filter appends to Normalizer.Filters.
+func Filter(fs ...TokenFilter) Option {
+	return func(n *Normalizer) { n.Filters = append(n.Filters, fs...) }
+}
+
+// WithMixedContent sets Normalizer.MixedContent.
+func WithMixedContent(m MixedContent) Option { return func(n *Normalizer) { n.MixedContent = m } }
+
+// MatchChildren appends to Normalizer.MatchChildren.
+func MatchChildren(ms ...MatchChildrenBy) Option {
+	return func(n *Normalizer) { n.MatchChildren = append(n.MatchChildren, ms...) }
+}
+
+// WithGranularity sets Normalizer.Granularity.
+func WithGranularity(g Granularity) Option { return func(n *Normalizer) { n.Granularity = g } }
+
+// MaxDifferences sets Normalizer.MaxDifferences.
+func MaxDifferences(k int) Option { return func(n *Normalizer) { n.MaxDifferences = k } }
+
+// WithCache sets Normalizer.Cache.
+func WithCache(c Cache) Option { return func(n *Normalizer) { n.Cache = c } }
+
+// IgnoreAttr appends to Normalizer.IgnoreAttrs.
+func IgnoreAttr(names ...string) Option {
+	return func(n *Normalizer) { n.IgnoreAttrs = appendNames(n.IgnoreAttrs, names) }
+}
+
+// IgnoreElement appends to Normalizer.IgnoreElements.
+func IgnoreElement(names ...string) Option {
+	return func(n *Normalizer) { n.IgnoreElements = appendNames(n.IgnoreElements, names) }
+}
+
+// MarkIgnored sets Normalizer.MarkIgnored.
+func MarkIgnored() Option { return func(n *Normalizer) { n.MarkIgnored = true } }
+
+// WithFormat sets Normalizer.Format.
+func WithFormat(f Format) Option { return func(n *Normalizer) { n.Format = f } }
+
+// MaxOutputBytes sets Normalizer.MaxOutputBytes.
+func MaxOutputBytes(max int) Option { return func(n *Normalizer) { n.MaxOutputBytes = max } }
+
+// WithDecoder sets Normalizer.Decoder.
+func WithDecoder(f func(r io.Reader) xml.TokenReader) Option {
+	return func(n *Normalizer) { n.Decoder = f }
+}
+
+// WithJSON sets Normalizer.JSON.
+func WithJSON(c JSONConvention) Option { return func(n *Normalizer) { n.JSON = c } }
+
+// XMLDeclaration sets Normalizer.XMLDeclaration.
+func XMLDeclaration() Option { return func(n *Normalizer) { n.XMLDeclaration = true } }
+
+// Decompress sets Normalizer.Decompress.
+func Decompress() Option { return func(n *Normalizer) { n.Decompress = true } }
+
+// Precheck sets Normalizer.Precheck.
+func Precheck() Option { return func(n *Normalizer) { n.Precheck = true } }
+
+// CheckIdempotent sets Normalizer.CheckIdempotent.
+func CheckIdempotent() Option { return func(n *Normalizer) { n.CheckIdempotent = true } }
+
+// Trace sets Normalizer.Trace.
+func Trace(w io.Writer) Option { return func(n *Normalizer) { n.Trace = w } }
+
+// OnToken sets Normalizer.OnToken.
+func OnToken(f func(depth int, t xml.Token)) Option { return func(n *Normalizer) { n.OnToken = f } }
+
+// OnProgress sets Normalizer.OnProgress and Normalizer.ProgressInterval.
+func OnProgress(every int, f func(p Progress) error) Option {
+	return func(n *Normalizer) {
+		n.OnProgress = f
+		n.ProgressInterval = every
+	}
+}
+
+// MaxDepth sets Normalizer.MaxDepth.
+func MaxDepth(d int) Option { return func(n *Normalizer) { n.MaxDepth = d } }
+
+// Parallelism sets Normalizer.Parallelism.
+func Parallelism(k int) Option { return func(n *Normalizer) { n.Parallelism = k } }
+
+// RedactAttr appends to Normalizer.RedactAttrs.
+func RedactAttr(names ...string) Option {
+	return func(n *Normalizer) { n.RedactAttrs = appendNames(n.RedactAttrs, names) }
+}
+
+// RedactElement appends to Normalizer.RedactElements.
+func RedactElement(names ...string) Option {
+	return func(n *Normalizer) { n.RedactElements = appendNames(n.RedactElements, names) }
+}
+
+// WithRedaction sets Normalizer.Redaction.
+func WithRedaction(s string) Option { return func(n *Normalizer) { n.Redaction = s } }
+
+// Expect appends an ExpectText for path and pattern to
+// Normalizer.Expect.
+func Expect(path, pattern string) Option {
+	return func(n *Normalizer) { n.Expect = append(n.Expect, ExpectText{path, pattern}) }
+}
+
+// Unwrap appends to Normalizer.Unwrap.
+func Unwrap(names ...string) Option {
+	return func(n *Normalizer) { n.Unwrap = appendNames(n.Unwrap, names) }
+}
+
+// UnorderedUnder appends to Normalizer.UnorderedUnder.
+func UnorderedUnder(names ...string) Option {
+	return func(n *Normalizer) { n.UnorderedUnder = appendNames(n.UnorderedUnder, names) }
+}
+
+// OrderedUnder appends to Normalizer.OrderedUnder.
+func OrderedUnder(names ...string) Option {
+	return func(n *Normalizer) { n.OrderedUnder = appendNames(n.OrderedUnder, names) }
+}
+
+// QNameAttr appends to Normalizer.QNameAttrs.
+func QNameAttr(names ...string) Option {
+	return func(n *Normalizer) { n.QNameAttrs = appendNames(n.QNameAttrs, names) }
+}
+
+// CollapseAttr appends to Normalizer.CollapseAttrs.
+func CollapseAttr(names ...string) Option {
+	return func(n *Normalizer) { n.CollapseAttrs = appendNames(n.CollapseAttrs, names) }
+}
+
+// NumericAttr appends to Normalizer.NumericAttrs.
+func NumericAttr(names ...string) Option {
+	return func(n *Normalizer) { n.NumericAttrs = appendNames(n.NumericAttrs, names) }
+}
+
+// OmitNamespace appends to Normalizer.OmitNamespaces.
+func OmitNamespace(uris ...string) Option {
+	return func(n *Normalizer) { n.OmitNamespaces = append(n.OmitNamespaces, uris...) }
+}
+
+// appendNames appends the names in Clark notation to list.
+func appendNames(list []xml.Name, names []string) []xml.Name {
+	for _, s := range names {
+		list = append(list, parseName(s))
+	}
+	return list
+}
+
+// parseName parses a name in Clark notation.
+func parseName(s string) xml.Name {
+	if strings.HasPrefix(s, "{") {
+		if i := strings.IndexByte(s, '}'); i > 0 {
+			return xml.Name{Space: s[1:i], Local: s[i+1:]}
+		}
+	}
+	return xml.Name{Local: s}
+}
+
+var (
+	presetsMu sync.RWMutex
+	presets   = map[string][]Option{}
+)
+
+// RegisterPreset makes the options opts available under name. It panics
+// if a preset is registered twice under the same name. The package
+// github.com/rsto/xmltest/presets registers presets for common dialects.
+func RegisterPreset(name string, opts ...Option) {
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	if _, dup := presets[name]; dup {
+		panic("xmltest: RegisterPreset called twice for preset " + name)
+	}
+	presets[name] = opts
+}
+
+// LookupPreset returns the options of the preset registered under name.
+func LookupPreset(name string) ([]Option, bool) {
+	presetsMu.RLock()
+	defer presetsMu.RUnlock()
+	opts, ok := presets[name]
+	return opts, ok
+}
+
+// Preset returns an Option that applies the options of the preset
+// registered under name. It panics if no such preset is registered.
+func Preset(name string) Option {
+	opts, ok := LookupPreset(name)
+	if !ok {
+		panic(fmt.Sprintf("xmltest: unknown preset %q", name))
+	}
+	return func(n *Normalizer) {
+		for _, opt := range opts {
+			opt(n)
+		}
+	}
+}
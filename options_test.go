@@ -0,0 +1,50 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	n := New(OmitComments(), IgnoreAttr("id", "{urn:x}a"), IgnoreAttr("b"), NumberTolerance(1, 0))
+	want := Normalizer{
+		OmitComments:    true,
+		IgnoreAttrs:     []xml.Name{{Local: "id"}, {Space: "urn:x", Local: "a"}, {Local: "b"}},
+		NumberTolerance: &Tolerance{Abs: 1},
+	}
+	if !reflect.DeepEqual(*n, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", *n, want)
+	}
+
+	var b strings.Builder
+	err := Normalize(&b, strings.NewReader(`<root id="1"><!-- c --><a/></root>`), OmitComments(), IgnoreAttr("id"))
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if got, want := b.String(), `<root><a></a></root>`; got != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestPreset(t *testing.T) {
+	RegisterPreset("test-preset", OmitWhitespace(), IgnoreAttr("x"))
+	ok, err := EqualXML(strings.NewReader("<a x='1'> </a>"), strings.NewReader("<a/>"), Preset("test-preset"))
+	if err != nil || !ok {
+		t.Errorf("got %v, %v, want true, nil", ok, err)
+	}
+	if _, ok := LookupPreset("no-such-preset"); ok {
+		t.Errorf("no-such-preset: got ok, want not found")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Preset: got no panic for unknown preset")
+		}
+	}()
+	Preset("no-such-preset")
+}
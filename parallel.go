@@ -0,0 +1,48 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"io"
+	"sync"
+)
+
+// readAllTokens reads the normalized tokens of each of readers. It
+// normalizes up to n.Parallelism documents at once, or one at a time if
+// tracing. The error returned is that of the first failing reader.
+func (n *Normalizer) readAllTokens(readers []io.Reader) ([][]xml.Token, error) {
+	docs := make([][]xml.Token, len(readers))
+	if n.Parallelism <= 1 || len(readers) < 2 || n.Trace != nil {
+		for i, r := range readers {
+			ts, err := n.readTokens(r)
+			if err != nil {
+				return nil, err
+			}
+			docs[i] = ts
+		}
+		return docs, nil
+	}
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, n.Parallelism)
+		errs = make([]error, len(readers))
+	)
+	for i, r := range readers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, r io.Reader) {
+			defer func() { <-sem; wg.Done() }()
+			docs[i], errs[i] = n.readTokens(r)
+		}(i, r)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParallelism(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		docs      []string
+		wantEqual bool
+		wantErr   string
+	}{{
+		desc:      "equal",
+		docs:      []string{`<a x="1" y="2"/>`, `<a y="2" x="1"/>`},
+		wantEqual: true,
+	}, {
+		desc: "not equal",
+		docs: []string{`<a/>`, `<b/>`},
+	}, {
+		desc:      "more documents than goroutines",
+		docs:      []string{`<a/>`, `<a></a>`, `<a/>`, `<a/>`, `<a/>`},
+		wantEqual: true,
+	}, {
+		desc:    "first failing document is reported",
+		docs:    []string{`<a/>`, `<a>`, `<a><b></a>`},
+		wantErr: "xmltest: syntax error at line 1, column 4: unexpected EOF",
+	}}
+
+	for _, tc := range testCases {
+		for _, p := range []int{0, 2} {
+			n := Normalizer{Parallelism: p}
+			var rs []io.Reader
+			for _, d := range tc.docs {
+				rs = append(rs, strings.NewReader(d))
+			}
+			var got bool
+			var err error
+			if len(rs) == 2 {
+				got, err = n.EqualXML(rs[0], rs[1])
+			} else {
+				got, err = n.AllEqualXML(rs...)
+			}
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("%s: Parallelism %d: got err %v, want %s", tc.desc, p, err, tc.wantErr)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: Parallelism %d: got err %v, want nil", tc.desc, p, err)
+				continue
+			}
+			if got != tc.wantEqual {
+				t.Errorf("%s: Parallelism %d: got %v, want %v", tc.desc, p, got, tc.wantEqual)
+			}
+		}
+	}
+}
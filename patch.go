@@ -0,0 +1,465 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xmlURL is the namespace bound to the xml prefix.
+const xmlURL = "http://www.w3.org/XML/1998/namespace"
+
+// errUnpatchable is returned by DiffPatch if the differences of two
+// documents can't be expressed as an XML patch.
+var errUnpatchable = errors.New("xmltest: differences of top-level nodes can't be expressed as XML patch")
+
+// patchOp is an XML patch operation as defined by RFC 5261.
+type patchOp struct {
+	// op is one of add, replace or remove.
+	op string
+	// sel is the XPath selector of the target node.
+	sel string
+	// attr is the name of the attribute to add, prefixed by @.
+	attr string
+	// node is the node to add or to replace the target with. If node is
+	// nil, text holds the value of the attribute to add or replace.
+	node *node
+	text string
+}
+
+// DiffPatch writes an XML patch document to w that describes how to
+// transform the normalized XML content of a into the normalized XML content
+// of b. The patch document is a <diff> element as defined by RFC 5261, and
+// its selectors refer to the normalized content of a as written by
+// Normalize, as changed by the operations before them.
+//
+// DiffPatch fails if the documents differ in their top-level nodes other
+// than the root element, since the patch format can't express such
+// differences.
+func (n *Normalizer) DiffPatch(w io.Writer, a, b io.Reader) error {
+	d, err := n.diff(a, b, true)
+	if err != nil {
+		return err
+	}
+	if d.patchErr != nil {
+		return d.patchErr
+	}
+	for _, op := range d.ops {
+		if op.node != nil {
+			d.registerPrefixes(op.node)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<diff")
+	uris := make([]string, 0, len(d.prefixes))
+	for uri := range d.prefixes {
+		if uri != xmlURL {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Slice(uris, func(i, j int) bool { return d.prefixes[uris[i]] < d.prefixes[uris[j]] })
+	for _, uri := range uris {
+		fmt.Fprintf(bw, ` xmlns:%s="%s"`, d.prefixes[uri], escapeAttr(uri))
+	}
+	bw.WriteString(">")
+	for _, op := range d.ops {
+		fmt.Fprintf(bw, `<%s sel="%s"`, op.op, escapeAttr(op.sel))
+		if op.attr != "" {
+			fmt.Fprintf(bw, ` type="%s"`, escapeAttr(op.attr))
+		}
+		switch {
+		case op.op == "remove":
+			bw.WriteString("/>")
+			continue
+		case op.node != nil:
+			bw.WriteString(">")
+			d.writeNode(bw, op.node)
+		default:
+			bw.WriteString(">")
+			xml.EscapeText(bw, []byte(op.text))
+		}
+		fmt.Fprintf(bw, "</%s>", op.op)
+	}
+	bw.WriteString("</diff>")
+	return bw.Flush()
+}
+
+// addOp adds op to the patch operations, if the differ collects them.
+func (d *differ) addOp(op patchOp) {
+	if d.patch {
+		d.ops = append(d.ops, op)
+	}
+}
+
+// unpatchable records that the differences can't be expressed as patch.
+func (d *differ) unpatchable() {
+	if d.patch && d.patchErr == nil {
+		d.patchErr = errUnpatchable
+	}
+}
+
+// misaligned reports whether nodes at the same position of as and bs are
+// of different kinds.
+func misaligned(as, bs []*node) bool {
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if fmt.Sprintf("%T", as[i].tok) != fmt.Sprintf("%T", bs[i].tok) {
+			return true
+		}
+	}
+	return false
+}
+
+// patchable reports whether the keyed children of as and bs end up at
+// the positions they have in bs if the patch removes keyed nodes only in as
+// and appends those only in bs, followed by the other nodes only in bs.
+func (m *MatchChildrenBy) patchable(as, bs []*node) bool {
+	keys := map[string]int{}
+	var other int
+	for _, b := range bs {
+		if k, ok := m.key(b); ok {
+			keys[k]++
+		} else {
+			other++
+		}
+	}
+	// got holds the positions of the keyed nodes of the patched nodes.
+	var got []int
+	var i int
+	for _, a := range as {
+		k, ok := m.key(a)
+		switch {
+		case !ok && other > 0:
+			i++
+			other--
+		case ok && keys[k] > 0:
+			i++
+			got = append(got, i)
+			keys[k]--
+		}
+	}
+	for _, n := range keys {
+		for ; n > 0; n-- {
+			i++
+			got = append(got, i)
+		}
+	}
+	return equalInts(got, m.positions(bs))
+}
+
+// xpathSteps returns the XPath location steps that select each of the
+// sibling nodes.
+func (d *differ) xpathSteps(nodes []*node) map[*node]string {
+	if !d.patch {
+		return nil
+	}
+	steps := make(map[*node]string, len(nodes))
+	seen := map[string]int{}
+	for _, nd := range nodes {
+		var test string
+		switch nd.tok.(type) {
+		case xml.StartElement:
+			test = d.qname(nd.name())
+		case xml.CharData:
+			test = "text()"
+		case xml.Comment:
+			test = "comment()"
+		}
+		seen[test]++
+		steps[nd] = test + "[" + strconv.Itoa(seen[test]) + "]"
+	}
+	return steps
+}
+
+// qname returns the qualified name of name in the patch document.
+func (d *differ) qname(name xml.Name) string {
+	if p := d.prefix(name.Space); p != "" {
+		return p + ":" + name.Local
+	}
+	return name.Local
+}
+
+// prefix returns the namespace prefix bound to uri in the patch document.
+func (d *differ) prefix(uri string) string {
+	switch uri {
+	case "":
+		return ""
+	case xmlURL:
+		return "xml"
+	}
+	if d.prefixes == nil {
+		d.prefixes = map[string]string{}
+	}
+	p, ok := d.prefixes[uri]
+	if !ok {
+		p = "p" + strconv.Itoa(len(d.prefixes)+1)
+		d.prefixes[uri] = p
+	}
+	return p
+}
+
+// registerPrefixes binds prefixes for all namespaces used in nd.
+func (d *differ) registerPrefixes(nd *node) {
+	if start, ok := nd.tok.(xml.StartElement); ok {
+		d.prefix(start.Name.Space)
+		for _, a := range start.Attr {
+			d.prefix(a.Name.Space)
+		}
+		for _, c := range nd.children {
+			d.registerPrefixes(c)
+		}
+	}
+}
+
+// writeNode writes nd using the namespace prefixes of the patch document.
+func (d *differ) writeNode(w *bufio.Writer, nd *node) {
+	switch t := nd.tok.(type) {
+	case xml.StartElement:
+		w.WriteString("<" + d.qname(t.Name))
+		for _, a := range t.Attr {
+			fmt.Fprintf(w, ` %s="%s"`, d.qname(a.Name), escapeAttr(a.Value))
+		}
+		w.WriteString(">")
+		for _, c := range nd.children {
+			d.writeNode(w, c)
+		}
+		w.WriteString("</" + d.qname(t.Name) + ">")
+	case xml.CharData:
+		xml.EscapeText(w, t)
+	case xml.Comment:
+		w.WriteString("<!--")
+		w.Write(t)
+		w.WriteString("-->")
+	}
+}
+
+// escapeAttr escapes s for use in a double-quoted attribute value.
+func escapeAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// ApplyPatch applies the XML patch document patch to the XML document doc
+// and writes the patched document to w. The patch document is a <diff>
+// element holding add, replace and remove operations as defined by RFC
+// 5261. Selectors may use the subset of XPath that selects children by
+// name, position and attribute value, and a final attribute step.
+//
+// The patched document keeps the comments, processing instructions and
+// whitespace of doc, but its namespace declarations are regenerated with
+// the prefixes of normalized output.
+func ApplyPatch(doc, patch io.Reader, w io.Writer) error {
+	toks, err := readAllTokens(doc)
+	if err != nil {
+		return err
+	}
+	root := &node{tok: xml.StartElement{}, children: buildTree(toks)}
+	toks, err = readAllTokens(patch)
+	if err != nil {
+		return err
+	}
+	var diff *node
+	for _, nd := range buildTree(toks) {
+		if nd.isElement() {
+			diff = nd
+			break
+		}
+	}
+	if diff == nil {
+		return errors.New("xmltest: patch document has no root element")
+	}
+	ns := bindNamespaces(nil, diff)
+	for _, op := range diff.children {
+		if !op.isElement() {
+			continue
+		}
+		if err := applyOp(root, op, bindNamespaces(ns, op)); err != nil {
+			return err
+		}
+	}
+	tw := newTokenWriter(bufio.NewWriter(w), Format{})
+	for _, nd := range root.children {
+		for _, t := range stripNamespaceDecls(nd).appendTokens(nil) {
+			if err := tw.writeToken(t); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.close()
+}
+
+// readAllTokens reads all tokens of r.
+func readAllTokens(r io.Reader) ([]xml.Token, error) {
+	d := xml.NewDecoder(r)
+	var toks []xml.Token
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, xml.CopyToken(t))
+	}
+}
+
+// bindNamespaces returns a copy of ns extended by the namespace
+// declarations of element nd.
+func bindNamespaces(ns map[string]string, nd *node) map[string]string {
+	m := make(map[string]string, len(ns))
+	for k, v := range ns {
+		m[k] = v
+	}
+	for _, a := range nd.tok.(xml.StartElement).Attr {
+		switch {
+		case a.Name.Space == "xmlns":
+			m[a.Name.Local] = a.Value
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			m[""] = a.Value
+		}
+	}
+	return m
+}
+
+// stripNamespaceDecls removes the namespace declarations from the elements
+// of the tree nd, and returns nd.
+func stripNamespaceDecls(nd *node) *node {
+	if start, ok := nd.tok.(xml.StartElement); ok {
+		attr := start.Attr[:0]
+		for _, a := range start.Attr {
+			if a.Name.Space != "xmlns" && !(a.Name.Space == "" && a.Name.Local == "xmlns") {
+				attr = append(attr, a)
+			}
+		}
+		start.Attr = attr
+		nd.tok = start
+		for _, c := range nd.children {
+			stripNamespaceDecls(c)
+		}
+	}
+	return nd
+}
+
+// applyOp applies the patch operation element op to the document root,
+// resolving prefixes with ns.
+func applyOp(root, op *node, ns map[string]string) error {
+	start := op.tok.(xml.StartElement)
+	attrs := map[string]string{}
+	for _, a := range start.Attr {
+		if a.Name.Space == "" {
+			attrs[a.Name.Local] = a.Value
+		}
+	}
+	target, err := selectXPath(root, attrs["sel"], ns)
+	if err != nil {
+		return err
+	}
+	var text strings.Builder
+	for _, c := range op.children {
+		if t, ok := c.tok.(xml.CharData); ok {
+			text.Write(t)
+		}
+	}
+	switch start.Name.Local {
+	case "add":
+		if target.attr >= 0 {
+			return fmt.Errorf("xmltest: can't add to attribute selected by %q", attrs["sel"])
+		}
+		if typ := attrs["type"]; typ != "" {
+			if !strings.HasPrefix(typ, "@") {
+				return fmt.Errorf("xmltest: unsupported add type %q", typ)
+			}
+			name, err := resolveQName(typ[1:], ns, false)
+			if err != nil {
+				return err
+			}
+			el := target.node()
+			elStart, ok := el.tok.(xml.StartElement)
+			if !ok {
+				return fmt.Errorf("xmltest: can't add attribute to non-element selected by %q", attrs["sel"])
+			}
+			for _, a := range elStart.Attr {
+				if a.Name == name {
+					return fmt.Errorf("xmltest: attribute %s already exists at %q", typ[1:], attrs["sel"])
+				}
+			}
+			elStart.Attr = append(elStart.Attr, xml.Attr{Name: name, Value: text.String()})
+			el.tok = elStart
+			return nil
+		}
+		var parent *node
+		var at int
+		switch pos := attrs["pos"]; pos {
+		case "":
+			parent, at = target.node(), len(target.node().children)
+		case "prepend":
+			parent, at = target.node(), 0
+		case "before":
+			parent, at = target.parent, target.i
+		case "after":
+			parent, at = target.parent, target.i+1
+		default:
+			return fmt.Errorf("xmltest: unsupported add position %q", pos)
+		}
+		if !parent.isElement() {
+			return fmt.Errorf("xmltest: can't add children to non-element selected by %q", attrs["sel"])
+		}
+		parent.children = insertNodes(parent.children, at, op.children)
+	case "replace":
+		if target.attr >= 0 {
+			el := target.node()
+			elStart := el.tok.(xml.StartElement)
+			elStart.Attr[target.attr].Value = text.String()
+			return nil
+		}
+		children := target.parent.children
+		children = append(children[:target.i:target.i], children[target.i+1:]...)
+		target.parent.children = insertNodes(children, target.i, op.children)
+	case "remove":
+		if target.attr >= 0 {
+			el := target.node()
+			elStart := el.tok.(xml.StartElement)
+			elStart.Attr = append(elStart.Attr[:target.attr:target.attr], elStart.Attr[target.attr+1:]...)
+			el.tok = elStart
+			return nil
+		}
+		from, to := target.i, target.i+1
+		ws := attrs["ws"]
+		if (ws == "before" || ws == "both") && from > 0 && isWhitespaceNode(target.parent.children[from-1]) {
+			from--
+		}
+		if (ws == "after" || ws == "both") && to < len(target.parent.children) && isWhitespaceNode(target.parent.children[to]) {
+			to++
+		}
+		children := target.parent.children
+		target.parent.children = append(children[:from:from], children[to:]...)
+	default:
+		return fmt.Errorf("xmltest: unsupported patch operation <%s>", start.Name.Local)
+	}
+	return nil
+}
+
+// insertNodes returns a new slice with ins inserted into nodes at index i.
+func insertNodes(nodes []*node, i int, ins []*node) []*node {
+	out := make([]*node, 0, len(nodes)+len(ins))
+	out = append(out, nodes[:i]...)
+	out = append(out, ins...)
+	return append(out, nodes[i:]...)
+}
+
+// isWhitespaceNode reports whether nd is character data that only contains
+// whitespace.
+func isWhitespaceNode(nd *node) bool {
+	t, ok := nd.tok.(xml.CharData)
+	return ok && strings.TrimSpace(string(t)) == ""
+}
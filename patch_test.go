@@ -0,0 +1,229 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffPatch(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		n       Normalizer
+		a, b    string
+		want    string
+		wantErr bool
+	}{{
+		desc: "equal",
+		a:    `<root/>`,
+		b:    `<root/>`,
+		want: `<diff></diff>`,
+	}, {
+		desc: "attributes",
+		a:    `<root a="1" b="2"/>`,
+		b:    `<root a="2" c="3"/>`,
+		want: `<diff>` +
+			`<replace sel="/root[1]/@a">2</replace>` +
+			`<remove sel="/root[1]/@b"/>` +
+			`<add sel="/root[1]" type="@c">3</add>` +
+			`</diff>`,
+	}, {
+		desc: "text and elements",
+		a:    `<root><p>x</p><p>y</p><foo/><bar/><baz/></root>`,
+		b:    `<root><p>x</p><p>z</p><bar/></root>`,
+		want: `<diff>` +
+			`<remove sel="/root[1]/baz[1]"/>` +
+			`<remove sel="/root[1]/bar[1]"/>` +
+			`<replace sel="/root[1]/foo[1]"><bar></bar></replace>` +
+			`<replace sel="/root[1]/p[2]/text()[1]">z</replace>` +
+			`</diff>`,
+	}, {
+		desc: "added elements in namespaces",
+		a:    `<root xmlns="urn:a"/>`,
+		b:    `<root xmlns="urn:a"><b:foo xmlns:b="urn:b" b:x="1"/></root>`,
+		want: `<diff xmlns:p1="urn:a" xmlns:p2="urn:b">` +
+			`<add sel="/p1:root[1]"><p2:foo p2:x="1"></p2:foo></add>` +
+			`</diff>`,
+	}, {
+		desc: "replace parent of nodes of different kinds",
+		a:    `<root><p>x<b/></p></root>`,
+		b:    `<root><p><b/>x</p></root>`,
+		want: `<diff>` +
+			`<replace sel="/root[1]/p[1]"><p><b></b>x</p></replace>` +
+			`</diff>`,
+	}, {
+		desc: "keyed children",
+		n:    Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "items", Child: "item", KeyAttr: "id"}}},
+		a:    `<items><item id="1"/><item id="2"/><item id="3"/></items>`,
+		b:    `<items><item id="4"/><item id="2"/></items>`,
+		want: `<diff>` +
+			`<add sel="/items[1]"><item id="4"></item></add>` +
+			`<remove sel="/items[1]/item[3]"/>` +
+			`<remove sel="/items[1]/item[1]"/>` +
+			`</diff>`,
+	}, {
+		desc:    "bad: different top-level nodes",
+		a:       `<root/>`,
+		b:       `<!-- c --><root/>`,
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := tc.n.DiffPatch(&b, strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: got nil error, want non-nil", tc.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{{
+		desc:  "add element",
+		doc:   `<root><a/></root>`,
+		patch: `<diff><add sel="/root"><b/></add></diff>`,
+		want:  `<root><a></a><b></b></root>`,
+	}, {
+		desc: "add element at positions",
+		doc:  `<root><a/></root>`,
+		patch: `<diff>` +
+			`<add sel="/root/a" pos="before"><b/></add>` +
+			`<add sel="/root/a" pos="after"><c/></add>` +
+			`<add sel="/root" pos="prepend"><d/></add>` +
+			`</diff>`,
+		want: `<root><d></d><b></b><a></a><c></c></root>`,
+	}, {
+		desc: "attributes",
+		doc:  `<root a="1" b="2"/>`,
+		patch: `<diff>` +
+			`<replace sel="/root/@a">x</replace>` +
+			`<remove sel="/root/@b"/>` +
+			`<add sel="/root" type="@c">y</add>` +
+			`</diff>`,
+		want: `<root a="x" c="y"></root>`,
+	}, {
+		desc:  "replace text",
+		doc:   `<root><p>x</p><p>y</p></root>`,
+		patch: `<diff><replace sel="/root/p[2]/text()">z</replace></diff>`,
+		want:  `<root><p>x</p><p>z</p></root>`,
+	}, {
+		desc:  "remove element with whitespace",
+		doc:   "<root>\n  <a/>\n  <b/>\n</root>",
+		patch: `<diff><remove sel="/root/a" ws="after"/></diff>`,
+		want:  "<root>\n  <b></b>\n</root>",
+	}, {
+		desc:  "select by attribute and prefix",
+		doc:   `<root xmlns="urn:x"><item id="1"/><item id="2"/></root>`,
+		patch: `<diff xmlns:x="urn:x"><remove sel="/x:root/x:item[@id='1']"/></diff>`,
+		want:  `<_:root xmlns:_="urn:x"><_:item id="2"></_:item></_:root>`,
+	}, {
+		desc:  "unnamespaced child of prefixed element",
+		doc:   `<p:r xmlns:p="urn:x"><c/></p:r>`,
+		patch: `<diff/>`,
+		want:  `<_:r xmlns:_="urn:x"><c></c></_:r>`,
+	}, {
+		desc:    "bad: ambiguous selector",
+		doc:     `<root><a/><a/></root>`,
+		patch:   `<diff><remove sel="/root/a"/></diff>`,
+		wantErr: true,
+	}, {
+		desc:    "bad: unsupported selector",
+		doc:     `<root><a/></root>`,
+		patch:   `<diff><remove sel="//a"/></diff>`,
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := ApplyPatch(strings.NewReader(tc.doc), strings.NewReader(tc.patch), &b)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: got nil error, want non-nil", tc.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestDiffPatchRoundTrip(t *testing.T) {
+	testCases := []struct {
+		n    Normalizer
+		a, b string
+	}{{
+		a: `<root a="1" b="2"><p>x</p><p>y</p><foo/><bar/><baz/></root>`,
+		b: `<root a="2" c="3"><p>x</p><p>z</p><bar/></root>`,
+	}, {
+		a: `<root xmlns="urn:a"><c>1</c></root>`,
+		b: `<root xmlns="urn:a"><b:foo xmlns:b="urn:b" b:x="1"/><c>2<!-- c --></c></root>`,
+	}, {
+		n: Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "items", Child: "item", KeyAttr: "id"}}},
+		a: `<items><item id="1"/><item id="2" v="a"/><x/><item id="3"/></items>`,
+		b: `<items><item id="4"/><x/><item id="2" v="b"/></items>`,
+	}, {
+		n: Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "items", Child: "item", KeyAttr: "id"}}},
+		a: `<items><item id="1"/><x/></items>`,
+		b: `<items><x/><item id="1"/></items>`,
+	}, {
+		n: Normalizer{MatchChildren: []MatchChildrenBy{{Parent: "items", Child: "item", KeyAttr: "id"}}},
+		a: `<items><item id="1"/><x/></items>`,
+		b: `<items><item id="2"/><x/></items>`,
+	}, {
+		a: `<r><a/><b x="1"/></r>`,
+		b: `<r><b x="2" y="3"/><c/></r>`,
+	}, {
+		a: `<p:r xmlns:p="urn:x"><c/><p:d><e xmlns="urn:y"><f xmlns=""/></e></p:d></p:r>`,
+		b: `<p:r xmlns:p="urn:x"><c x="1"/><p:d><e xmlns="urn:y"><f xmlns=""/><g/></e></p:d></p:r>`,
+	}, {
+		a: `<r xmlns="urn:x"><a/><q:b xmlns:q="urn:y"><c xmlns=""/></q:b></r>`,
+		b: `<r xmlns="urn:x"><q:b xmlns:q="urn:y" q:at="1"><c xmlns=""><a/></c></q:b><a xmlns=""/></r>`,
+	}, {
+		a: `<r><a xmlns="urn:x"><b/></a><p:a xmlns:p="urn:y"/></r>`,
+		b: `<r><a xmlns="urn:y"><b xmlns=""/></a><p:a xmlns:p="urn:x"><b/></p:a></r>`,
+	}}
+
+	for i, tc := range testCases {
+		var norm, patch, patched strings.Builder
+		if err := tc.n.Normalize(&norm, strings.NewReader(tc.a)); err != nil {
+			t.Fatalf("#%d: Normalize: %v", i, err)
+		}
+		if err := tc.n.DiffPatch(&patch, strings.NewReader(tc.a), strings.NewReader(tc.b)); err != nil {
+			t.Fatalf("#%d: DiffPatch: %v", i, err)
+		}
+		if err := ApplyPatch(strings.NewReader(norm.String()), strings.NewReader(patch.String()), &patched); err != nil {
+			t.Fatalf("#%d: ApplyPatch: %v\npatch: %s", i, err, patch.String())
+		}
+		equal, err := tc.n.EqualXML(strings.NewReader(patched.String()), strings.NewReader(tc.b))
+		if err != nil {
+			t.Fatalf("#%d: EqualXML: %v", i, err)
+		}
+		if !equal {
+			t.Errorf("#%d: patched document differs\npatch   %s\npatched %s\nwant    %s", i, patch.String(), patched.String(), tc.b)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// pathPattern is a parsed path expression, as described for ExpectText.
+type pathPattern struct {
+	abs   bool
+	steps []xml.Name
+	// attr is the selected attribute, if hasAttr.
+	attr    xml.Name
+	hasAttr bool
+}
+
+// parsePath parses the path expression s.
+func parsePath(s string) (pathPattern, error) {
+	var p pathPattern
+	rel := s
+	if strings.HasPrefix(s, "/") {
+		p.abs = true
+		rel = s[1:]
+	}
+	steps := splitPath(rel)
+	for i, step := range steps {
+		if strings.HasPrefix(step, "@") {
+			if i != len(steps)-1 {
+				return p, fmt.Errorf("xmltest: path %s: attribute step must be last", s)
+			}
+			step = step[1:]
+			p.attr, p.hasAttr = parseName(step), true
+			if p.attr.Local == "" {
+				return p, fmt.Errorf("xmltest: path %s: empty step", s)
+			}
+			break
+		}
+		name := parseName(step)
+		if name.Local == "" {
+			return p, fmt.Errorf("xmltest: path %s: empty step", s)
+		}
+		p.steps = append(p.steps, name)
+	}
+	if len(p.steps) == 0 && !p.hasAttr {
+		return p, fmt.Errorf("xmltest: empty path")
+	}
+	return p, nil
+}
+
+// splitPath splits s at slashes outside of braces.
+func splitPath(s string) []string {
+	var (
+		steps []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				steps = append(steps, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(steps, s[start:])
+}
+
+// matchElement reports whether the elements of p match path, the names of
+// an element and its ancestors.
+func (p pathPattern) matchElement(path []xml.Name) bool {
+	if len(path) < len(p.steps) || p.abs && len(path) != len(p.steps) {
+		return false
+	}
+	path = path[len(path)-len(p.steps):]
+	for i, step := range p.steps {
+		if step.Local != "*" && !matchName([]xml.Name{step}, path[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAttr reports whether p selects the attribute name of the element at
+// path.
+func (p pathPattern) matchAttr(path []xml.Name, name xml.Name) bool {
+	if !p.hasAttr || !p.matchElement(path) {
+		return false
+	}
+	return p.attr.Local == "*" || matchName([]xml.Name{p.attr}, name)
+}
+
+// compilePaths parses the path expressions paths.
+func compilePaths(paths []string) ([]pathPattern, error) {
+	ps := make([]pathPattern, len(paths))
+	for i, s := range paths {
+		p, err := parsePath(s)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = p
+	}
+	return ps, nil
+}
+
+// matchText reports whether one of ps selects the character data of the
+// element at path.
+func matchText(ps []pathPattern, path []xml.Name) bool {
+	for _, p := range ps {
+		if !p.hasAttr && p.matchElement(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteAttrs replaces the values of attrs, the attributes of the element
+// at path, that one of ps selects by their rewrite by f, if f accepts them.
+func rewriteAttrs(ps []pathPattern, path []xml.Name, attrs []xml.Attr, f func(s string) (string, bool)) {
+	for i, a := range attrs {
+		for _, p := range ps {
+			if !p.matchAttr(path, a.Name) {
+				continue
+			}
+			if v, ok := f(a.Value); ok {
+				attrs[i].Value = v
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParsePathError(t *testing.T) {
+	for _, s := range []string{"", "/", "a//b", "a/@b/c", "a/@", "/a/"} {
+		if _, err := parsePath(s); err == nil {
+			t.Errorf("parsePath(%q): got nil error, want error", s)
+		}
+	}
+}
+
+func TestPathMatch(t *testing.T) {
+	path := []xml.Name{{Local: "root"}, {Space: "urn:a/b", Local: "item"}, {Local: "name"}}
+	testCases := []struct {
+		desc string
+		path string
+		attr xml.Name
+		want bool
+	}{
+		{desc: "absolute", path: "/root/item/name", want: true},
+		{desc: "absolute prefix", path: "/root/item"},
+		{desc: "relative", path: "item/name", want: true},
+		{desc: "relative single", path: "name", want: true},
+		{desc: "relative mismatch", path: "root/name"},
+		{desc: "wildcard", path: "/root/*/name", want: true},
+		{desc: "namespace", path: "{urn:a/b}item/name", want: true},
+		{desc: "other namespace", path: "{urn:c}item/name"},
+		{desc: "too long", path: "x/root/item/name"},
+		{desc: "attribute", path: "item/name/@id", attr: xml.Name{Local: "id"}, want: true},
+		{desc: "attribute wildcard", path: "/root/item/name/@*", attr: xml.Name{Local: "id"}, want: true},
+		{desc: "other attribute", path: "name/@ref", attr: xml.Name{Local: "id"}},
+		{desc: "attribute of other element", path: "item/@id", attr: xml.Name{Local: "id"}},
+	}
+	for _, tc := range testCases {
+		p, err := parsePath(tc.path)
+		if err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		var got bool
+		if tc.attr.Local != "" {
+			got = p.matchAttr(path, tc.attr)
+		} else {
+			got = p.matchElement(path)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
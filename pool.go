@@ -0,0 +1,120 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not pooled, so
+// that a single huge document does not pin its memory.
+const maxPooledBuffer = 16 << 20
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns b to the pool. The contents of b must no longer be
+// referenced.
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+var (
+	readerPool = sync.Pool{
+		New: func() interface{} { return bufio.NewReader(nil) },
+	}
+	writerPool = sync.Pool{
+		New: func() interface{} { return bufio.NewWriter(nil) },
+	}
+	tokenNormalizerPool = sync.Pool{
+		New: func() interface{} { return new(TokenNormalizer) },
+	}
+)
+
+// newDecoder returns a decoder that reads from r through a pooled buffer,
+// and a func that releases the buffer once the decoder is no longer used.
+// The input is transcoded to UTF-8 if necessary.
+func newDecoder(r io.Reader) (*xml.Decoder, func()) {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(r)
+	return newTranscodingDecoder(br), func() {
+		br.Reset(nil)
+		readerPool.Put(br)
+	}
+}
+
+// newDecoder is like the function newDecoder, but reads the tokens with
+// n.Decoder if set.
+func (n *Normalizer) newDecoder(r io.Reader) (*xml.Decoder, func()) {
+	if n.Decoder != nil {
+		return xml.NewTokenDecoder(n.Decoder(r)), func() {}
+	}
+	return newDecoder(r)
+}
+
+// newWriter returns a buffered writer to w from the pool, and a func that
+// releases it once it is flushed.
+func newWriter(w io.Writer) (*bufio.Writer, func()) {
+	bw := writerPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw, func() {
+		bw.Reset(nil)
+		writerPool.Put(bw)
+	}
+}
+
+// getTokenNormalizer is like NewTokenNormalizer, but reuses the buffers of
+// a pooled TokenNormalizer. It must be returned with putTokenNormalizer
+// once its tokens are read.
+func (n *Normalizer) getTokenNormalizer(tr xml.TokenReader) *TokenNormalizer {
+	tn := tokenNormalizerPool.Get().(*TokenNormalizer)
+	*tn = TokenNormalizer{
+		n:     n,
+		d:     xml.NewTokenDecoder(tr),
+		queue: tn.queue[:0],
+		text:  tn.text[:0],
+		path:  tn.path[:0],
+		tree:  tn.tree[:0],
+		ns:    tn.ns[:0],
+		bases: tn.bases[:0],
+		texts: tn.texts[:0],
+		types: tn.types[:0],
+	}
+	return tn
+}
+
+// putTokenNormalizer returns tn to the pool. The tokens it returned stay
+// valid.
+func putTokenNormalizer(tn *TokenNormalizer) {
+	// Drop the references to tokens and namespaces not yet returned.
+	for i := range tn.queue {
+		tn.queue[i] = nil
+	}
+	for i := range tn.tree {
+		tn.tree[i] = nil
+	}
+	for i := range tn.types {
+		tn.types[i] = nil
+	}
+	for i := range tn.ns {
+		tn.ns[i] = nil
+	}
+	tn.n, tn.d, tn.err = nil, nil, nil
+	tokenNormalizerPool.Put(tn)
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalizerShared(t *testing.T) {
+	n := &Normalizer{
+		OmitWhitespace: true,
+		SortElements:   true,
+		Cache:          NewMemoryCache(),
+	}
+	for i := 0; i < 8; i++ {
+		i := i
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			a := fmt.Sprintf(`<r><b>%d</b> <a x="%d"/></r>`, i, i)
+			b := fmt.Sprintf(`<r><a x="%d"></a><b>%d</b></r>`, i, i)
+			for j := 0; j < 50; j++ {
+				var sb strings.Builder
+				if err := n.Normalize(&sb, strings.NewReader(a)); err != nil {
+					t.Fatalf("Normalize: got err %v, want nil", err)
+				}
+				want := fmt.Sprintf(`<r><a x="%d"></a><b>%d</b></r>`, i, i)
+				if got := sb.String(); got != want {
+					t.Fatalf("Normalize: got %s, want %s", got, want)
+				}
+				equal, err := n.EqualXML(strings.NewReader(a), strings.NewReader(b))
+				if err != nil || !equal {
+					t.Fatalf("EqualXML: got %v, %v, want true, nil", equal, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPooledTokensStayValid(t *testing.T) {
+	var n Normalizer
+	first, err := n.readTokens(strings.NewReader(`<a x="1">text</a>`))
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if _, err := n.readTokens(strings.NewReader(`<b y="2">other</b>`)); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if got, want := fmt.Sprint(first), `[{{ a} [{{ x} 1}]} [116 101 120 116] {{ a}}]`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"hash/fnv"
+	"io"
+	"strings"
+)
+
+// shape summarizes the element structure of a document.
+type shape struct {
+	elems int
+	root  xml.Name
+	// names is the sum of the hashes of all element names, so that it does
+	// not depend on the order of elements.
+	names uint64
+}
+
+// prechecks reports whether n keeps all elements, including the content of
+// redacted ones, and their names, so that documents of different shape
+// cannot be equal, and has no ExpectTexts that skipping normalization would
+// leave unchecked.
+func (n *Normalizer) prechecks() bool {
+	return len(n.Unwrap) == 0 && len(n.IgnoreElements) == 0 && len(n.OmitNamespaces) == 0 &&
+		len(n.Filters) == 0 && len(n.Expect) == 0 && len(n.Rename) == 0 && len(n.RedactElements) == 0
+}
+
+// equalPrechecked implements EqualXML for Normalizers with Precheck set. It
+// reports false without normalizing if the shapes of a and b differ.
+func (n *Normalizer) equalPrechecked(a, b io.Reader) (bool, error) {
+	bufa, bufb := getBuffer(), getBuffer()
+	defer putBuffer(bufa)
+	defer putBuffer(bufb)
+	for _, in := range []struct {
+		buf *bytes.Buffer
+		r   io.Reader
+	}{{bufa, a}, {bufb, b}} {
+		r, err := n.decompress(in.r)
+		if err != nil {
+			return false, err
+		}
+		if _, err := in.buf.ReadFrom(r); err != nil {
+			return false, err
+		}
+	}
+	sa, oka := n.scanShape(bufa.Bytes())
+	sb, okb := n.scanShape(bufb.Bytes())
+	if oka && okb && sa != sb {
+		return false, nil
+	}
+	return n.equal(bytes.NewReader(bufa.Bytes()), bytes.NewReader(bufb.Bytes()))
+}
+
+// scanShape returns the shape of the document b. It reports false if b is
+// not a document that n normalizes without error, which is then left to
+// normalization to report.
+func (n *Normalizer) scanShape(b []byte) (shape, bool) {
+	var (
+		s    shape
+		open int
+	)
+	d, release := n.newDecoder(bytes.NewReader(b))
+	defer release()
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return s, true
+		}
+		if err != nil {
+			return s, false
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if n.MaxDepth > 0 && open >= n.MaxDepth {
+				return s, false
+			}
+			if open == 0 && s.elems > 0 && !n.AllowMultipleRoots && !n.Fragment && n.rules() >= RulesV2 {
+				return s, false
+			}
+			open++
+			name := t.Name
+			if n.CaseInsensitiveNames {
+				name.Local = strings.ToLower(name.Local)
+			}
+			if s.elems == 0 {
+				s.root = name
+			}
+			s.elems++
+			h := fnv.New64a()
+			io.WriteString(h, name.Space)
+			h.Write([]byte{0})
+			io.WriteString(h, name.Local)
+			s.names += h.Sum64()
+		case xml.EndElement:
+			open--
+		case xml.CharData:
+			if open == 0 && !n.Fragment && n.rules() >= RulesV2 && len(bytes.Trim(t, " \t\r\n\ufeff")) > 0 {
+				return s, false
+			}
+		}
+	}
+}
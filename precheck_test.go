@@ -0,0 +1,109 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestPrecheck(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		n             Normalizer
+		a, b          string
+		want          bool
+		wantErr       bool
+		wantNormalize bool
+	}{{
+		desc:          "same shape",
+		a:             `<a><b>1</b></a>`,
+		b:             `<a><b>2</b></a>`,
+		wantNormalize: true,
+	}, {
+		desc: "different element count",
+		a:    `<a><b/></a>`,
+		b:    `<a><b/><b/></a>`,
+	}, {
+		desc: "different root",
+		a:    `<a><b/></a>`,
+		b:    `<c><b/></c>`,
+	}, {
+		desc: "different names",
+		a:    `<a><b/></a>`,
+		b:    `<a><c/></a>`,
+	}, {
+		desc: "different namespace",
+		a:    `<a xmlns="x"/>`,
+		b:    `<p:a xmlns:p="y"/>`,
+	}, {
+		desc:          "equal with other prefix",
+		a:             `<a xmlns="x"><b/></a>`,
+		b:             `<p:a xmlns:p="x"><p:b/></p:a>`,
+		want:          true,
+		wantNormalize: true,
+	}, {
+		desc:          "reordered elements have the same shape",
+		n:             Normalizer{SortElements: true},
+		a:             `<a><b/><c/></a>`,
+		b:             `<a><c/><b/></a>`,
+		want:          true,
+		wantNormalize: true,
+	}, {
+		desc:          "case insensitive names",
+		n:             Normalizer{CaseInsensitiveNames: true},
+		a:             `<A><b/></A>`,
+		b:             `<a><B/></a>`,
+		want:          true,
+		wantNormalize: true,
+	}, {
+		desc:          "skipped if elements are ignored",
+		n:             Normalizer{IgnoreElements: []xml.Name{{Local: "c"}}},
+		a:             `<a><b/></a>`,
+		b:             `<a><b/><c/></a>`,
+		want:          true,
+		wantNormalize: true,
+	}, {
+		desc:          "skipped if elements are renamed",
+		n:             Normalizer{Rename: map[xml.Name]xml.Name{{Local: "x"}: {Local: "y"}}},
+		a:             `<r><x/></r>`,
+		b:             `<r><y/></r>`,
+		want:          true,
+		wantNormalize: true,
+	}, {
+		desc:          "skipped if element content is redacted",
+		n:             Normalizer{RedactElements: []xml.Name{{Local: "x"}}},
+		a:             `<r><x><a/></x></r>`,
+		b:             `<r><x>q</x></r>`,
+		want:          true,
+		wantNormalize: true,
+	}, {
+		desc:          "malformed input is reported",
+		a:             `<a/>`,
+		b:             `<a><b></a>`,
+		wantErr:       true,
+		wantNormalize: true,
+	}}
+
+	for _, tc := range testCases {
+		n := tc.n
+		n.Precheck = true
+		// Normalization writes to the trace.
+		var trace strings.Builder
+		n.Trace = &trace
+		got, err := n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got err %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.want)
+		}
+		if normalized := trace.Len() > 0; normalized != tc.wantNormalize {
+			t.Errorf("%s: got normalized %v, want %v", tc.desc, normalized, tc.wantNormalize)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package presets provides xmltest Normalizers configured for common XML
+// dialects. Each function returns a new Normalizer that callers may adjust
+// further.
+//
+// Importing the package also registers its presets with xmltest under the
+// names xmpp, soap, soap-body, feed, svg and junit, for use with
+// xmltest.Preset.
+package presets
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/rsto/xmltest"
+)
+
+const (
+	streamsNS = "http://etherx.jabber.org/streams"
+	soap11NS  = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12NS  = "http://www.w3.org/2003/05/soap-envelope"
+	xsiNS     = "http://www.w3.org/2001/XMLSchema-instance"
+	atomNS    = "http://www.w3.org/2005/Atom"
+
+	inkscapeNS = "http://www.inkscape.org/namespaces/inkscape"
+	sodipodiNS = "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"
+)
+
+var (
+	xmppOptions = []xmltest.Option{
+		xmltest.OmitWhitespace(),
+		xmltest.Fragment(),
+		xmltest.Unwrap("{" + streamsNS + "}stream"),
+		xmltest.IgnoreAttr("id"),
+	}
+	soapOptions = []xmltest.Option{
+		xmltest.OmitWhitespace(),
+		xmltest.UnorderedUnder(soapNames("Header")...),
+		xmltest.QNameAttr("{" + xsiNS + "}type"),
+		xmltest.AttrTransform(canonicalMustUnderstand),
+	}
+	soapBodyOptions = append(soapOptions[:len(soapOptions):len(soapOptions)],
+		xmltest.Unwrap(soapNames("Envelope")...),
+		xmltest.Unwrap(soapNames("Body")...),
+		xmltest.IgnoreElement(soapNames("Header")...),
+	)
+	feedOptions = []xmltest.Option{
+		xmltest.OmitWhitespace(),
+		xmltest.IgnoreElement("{"+atomNS+"}updated", "lastBuildDate", "generator"),
+		xmltest.MatchChildren(
+			xmltest.MatchChildrenBy{Parent: "feed", Child: "entry", KeyElem: "id"},
+			xmltest.MatchChildrenBy{Parent: "channel", Child: "item", KeyElem: "guid"},
+		),
+	}
+	svgOptions = []xmltest.Option{
+		xmltest.OmitWhitespace(),
+		xmltest.NumberTolerance(0.001, 0),
+		xmltest.NumericAttr("d", "points", "transform"),
+		xmltest.CollapseAttr("d", "points", "transform"),
+		xmltest.OmitNamespace(inkscapeNS, sodipodiNS),
+	}
+	junitOptions = []xmltest.Option{
+		xmltest.OmitWhitespace(),
+		xmltest.IgnoreAttr("time", "timestamp", "hostname"),
+		xmltest.MatchChildren(xmltest.MatchChildrenBy{
+			Parent: "testsuite", Child: "testcase", KeyAttrs: []string{"classname", "name"},
+		}),
+	}
+)
+
+func init() {
+	xmltest.RegisterPreset("xmpp", xmppOptions...)
+	xmltest.RegisterPreset("soap", soapOptions...)
+	xmltest.RegisterPreset("soap-body", soapBodyOptions...)
+	xmltest.RegisterPreset("feed", feedOptions...)
+	xmltest.RegisterPreset("svg", svgOptions...)
+	xmltest.RegisterPreset("junit", junitOptions...)
+}
+
+// XMPP returns a Normalizer for comparing XMPP streams and stanzas. It
+//
+//   - removes the <stream:stream> wrapper, which need not be closed,
+//   - accepts stanzas as fragments and compares them one by one in order,
+//   - ignores whitespace between tags, and
+//   - ignores id attributes.
+func XMPP() *xmltest.Normalizer {
+	return xmltest.New(xmppOptions...)
+}
+
+// SOAP returns a Normalizer for comparing SOAP 1.1 and 1.2 envelopes. It
+//
+//   - compares the children of the Header element regardless of order,
+//   - compares mustUnderstand attributes by their boolean value,
+//   - compares xsi:type attributes by the namespace URI of their QName
+//     rather than its prefix, and
+//   - ignores whitespace between tags.
+func SOAP() *xmltest.Normalizer {
+	return xmltest.New(soapOptions...)
+}
+
+// SOAPBody returns a Normalizer like SOAP that only compares the content
+// of the Body element of the envelopes.
+func SOAPBody() *xmltest.Normalizer {
+	return xmltest.New(soapBodyOptions...)
+}
+
+// soapNames returns the SOAP 1.1 and 1.2 names of element local in Clark
+// notation.
+func soapNames(local string) []string {
+	return []string{"{" + soap11NS + "}" + local, "{" + soap12NS + "}" + local}
+}
+
+func canonicalMustUnderstand(elem xml.Name, a xml.Attr) xml.Attr {
+	if a.Name.Local != "mustUnderstand" || (a.Name.Space != soap11NS && a.Name.Space != soap12NS) {
+		return a
+	}
+	switch strings.TrimSpace(a.Value) {
+	case "1", "true":
+		a.Value = "true"
+	case "0", "false":
+		a.Value = "false"
+	}
+	return a
+}
+
+// Feed returns a Normalizer for comparing Atom and RSS feeds. It
+//
+//   - ignores the volatile updated, lastBuildDate and generator elements,
+//   - matches Atom entries by their id and RSS items by their guid rather
+//     than by their position, and
+//   - ignores whitespace between tags.
+func Feed() *xmltest.Normalizer {
+	return xmltest.New(feedOptions...)
+}
+
+// SVG returns a Normalizer for comparing SVG images. It
+//
+//   - compares the numbers in the d, points and transform attributes
+//     within an absolute tolerance of 0.001, as well as numeric values,
+//   - collapses whitespace in these attributes,
+//   - removes elements and attributes of the Inkscape and Sodipodi editor
+//     namespaces, and
+//   - ignores whitespace between tags.
+//
+// Callers may change the tolerance by setting NumberTolerance.
+func SVG() *xmltest.Normalizer {
+	return xmltest.New(svgOptions...)
+}
+
+// JUnit returns a Normalizer for comparing JUnit XML test reports. It
+//
+//   - ignores the time, timestamp and hostname attributes,
+//   - matches testcase elements by their classname and name rather than
+//     by their position, and
+//   - ignores whitespace between tags.
+func JUnit() *xmltest.Normalizer {
+	return xmltest.New(junitOptions...)
+}
@@ -0,0 +1,191 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package presets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rsto/xmltest"
+)
+
+type presetCase struct {
+	desc      string
+	a, b      string
+	wantEqual bool
+}
+
+func testPreset(t *testing.T, n *xmltest.Normalizer, testCases []presetCase) {
+	t.Helper()
+	for _, tc := range testCases {
+		got, err := n.EqualXML(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got != tc.wantEqual {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.wantEqual)
+		}
+	}
+}
+
+func TestXMPP(t *testing.T) {
+	stream := `<stream:stream xmlns="jabber:client" xmlns:stream="http://etherx.jabber.org/streams" id="s1">`
+	testPreset(t, XMPP(), []presetCase{{
+		desc: "unterminated stream and stanzas",
+		a: stream + "\n" +
+			`<message id="a1" to="juliet@example.com"><body>hi</body></message>` + "\n" +
+			`<presence id="a2"/>`,
+		b: `<message xmlns="jabber:client" to="juliet@example.com" id="b1"><body>hi</body></message>` +
+			`<presence xmlns="jabber:client"/>`,
+		wantEqual: true,
+	}, {
+		desc: "stanzas are compared in order",
+		a:    stream + `<message/><presence/>`,
+		b:    stream + `<presence/><message/>`,
+	}, {
+		desc: "stanza content differs",
+		a:    stream + `<message><body>hi</body></message>`,
+		b:    stream + `<message><body>bye</body></message></stream:stream>`,
+	}})
+}
+
+func TestSOAP(t *testing.T) {
+	envelope := func(header, body string) string {
+		return `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"` +
+			` xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+			`<soap:Header>` + header + `</soap:Header>` +
+			`<soap:Body>` + body + `</soap:Body></soap:Envelope>`
+	}
+	testPreset(t, SOAP(), []presetCase{{
+		desc: "header order and mustUnderstand",
+		a: envelope(
+			`<a xmlns="urn:a" soap:mustUnderstand="1"/><b xmlns="urn:b"/>`,
+			`<op xmlns="urn:x"/>`),
+		b: envelope(
+			`<b xmlns="urn:b"/><a xmlns="urn:a" soap:mustUnderstand="true"/>`,
+			`<op xmlns="urn:x"/>`),
+		wantEqual: true,
+	}, {
+		desc:      "xsi:type prefixes",
+		a:         envelope("", `<v xmlns:p="urn:t" xsi:type="p:Int">1</v>`),
+		b:         envelope("", `<v xmlns:q="urn:t" xsi:type="q:Int">1</v>`),
+		wantEqual: true,
+	}, {
+		desc: "xsi:type namespaces",
+		a:    envelope("", `<v xmlns:p="urn:t" xsi:type="p:Int">1</v>`),
+		b:    envelope("", `<v xmlns:p="urn:u" xsi:type="p:Int">1</v>`),
+	}, {
+		desc: "body order matters",
+		a:    envelope("", `<a/><b/>`),
+		b:    envelope("", `<b/><a/>`),
+	}})
+}
+
+func TestSOAPBody(t *testing.T) {
+	testPreset(t, SOAPBody(), []presetCase{{
+		desc: "headers are ignored",
+		a: `<Envelope xmlns="http://www.w3.org/2003/05/soap-envelope">` +
+			`<Header><h>1</h></Header><Body><op xmlns="urn:x"/></Body></Envelope>`,
+		b:         `<op xmlns="urn:x"/>`,
+		wantEqual: true,
+	}})
+}
+
+func TestFeed(t *testing.T) {
+	testPreset(t, Feed(), []presetCase{{
+		desc: "atom",
+		a: `<feed xmlns="http://www.w3.org/2005/Atom">
+			<updated>2015-08-01T10:00:00Z</updated>
+			<generator version="1.0">gen</generator>
+			<entry><id>urn:1</id><title>one</title><updated>2015-08-01T10:00:00Z</updated></entry>
+			<entry><id>urn:2</id><title>two</title></entry>
+		</feed>`,
+		b: `<feed xmlns="http://www.w3.org/2005/Atom">
+			<updated>2016-01-01T00:00:00Z</updated>
+			<generator version="2.0">gen</generator>
+			<entry><id>urn:2</id><title>two</title></entry>
+			<entry><id>urn:1</id><title>one</title></entry>
+		</feed>`,
+		wantEqual: true,
+	}, {
+		desc: "rss",
+		a: `<rss version="2.0"><channel><lastBuildDate>Sat, 01 Aug 2015 10:00:00 GMT</lastBuildDate>
+			<item><guid>1</guid></item><item><guid>2</guid></item></channel></rss>`,
+		b: `<rss version="2.0"><channel><lastBuildDate>Fri, 01 Jan 2016 00:00:00 GMT</lastBuildDate>
+			<generator>gen 2.0</generator>
+			<item><guid>2</guid></item><item><guid>1</guid></item></channel></rss>`,
+		wantEqual: true,
+	}, {
+		desc: "entry content differs",
+		a:    `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>1</id><title>a</title></entry></feed>`,
+		b:    `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>1</id><title>b</title></entry></feed>`,
+	}})
+}
+
+func TestSVG(t *testing.T) {
+	testPreset(t, SVG(), []presetCase{{
+		desc: "coordinates within tolerance",
+		a: `<svg xmlns="http://www.w3.org/2000/svg">` +
+			`<path d="M10,20 L30.0001,40z" transform="translate(1 2)"/>` +
+			`<polygon points="0,0 1,1"/></svg>`,
+		b: `<svg xmlns="http://www.w3.org/2000/svg">` +
+			`<path d="M 10 20
+				L 30 40 z" transform="translate(1,2.0)"/>` +
+			`<polygon points=" 0 0, 1 1 "/></svg>`,
+		wantEqual: true,
+	}, {
+		desc: "coordinates out of tolerance",
+		a:    `<svg xmlns="http://www.w3.org/2000/svg"><path d="M10,20"/></svg>`,
+		b:    `<svg xmlns="http://www.w3.org/2000/svg"><path d="M10,20.1"/></svg>`,
+	}, {
+		desc: "path commands differ",
+		a:    `<svg xmlns="http://www.w3.org/2000/svg"><path d="M10,20"/></svg>`,
+		b:    `<svg xmlns="http://www.w3.org/2000/svg"><path d="L10,20"/></svg>`,
+	}, {
+		desc: "editor metadata",
+		a: `<svg xmlns="http://www.w3.org/2000/svg"` +
+			` xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"` +
+			` xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"` +
+			` inkscape:version="1.0"><sodipodi:namedview pagecolor="#fff"/>` +
+			`<g inkscape:label="Layer 1"/></svg>`,
+		b:         `<svg xmlns="http://www.w3.org/2000/svg"><g/></svg>`,
+		wantEqual: true,
+	}})
+}
+
+func TestJUnit(t *testing.T) {
+	testPreset(t, JUnit(), []presetCase{{
+		desc: "volatile attributes and order",
+		a: `<testsuites time="1.5"><testsuite name="s" hostname="a" timestamp="2015-08-01T10:00:00">
+			<testcase classname="p.A" name="t1" time="0.5"/>
+			<testcase classname="p.B" name="t1" time="1.0"><failure message="x"/></testcase>
+			</testsuite></testsuites>`,
+		b: `<testsuites time="2"><testsuite name="s" hostname="b" timestamp="2016-01-01T00:00:00">
+			<testcase classname="p.B" name="t1" time="0.1"><failure message="x"/></testcase>
+			<testcase classname="p.A" name="t1" time="0.2"/>
+			</testsuite></testsuites>`,
+		wantEqual: true,
+	}, {
+		desc: "different outcome",
+		a:    `<testsuite><testcase classname="p.A" name="t1"/></testsuite>`,
+		b:    `<testsuite><testcase classname="p.A" name="t1"><skipped/></testcase></testsuite>`,
+	}})
+}
+
+func TestRegistered(t *testing.T) {
+	for _, name := range []string{"xmpp", "soap", "soap-body", "feed", "svg", "junit"} {
+		if _, ok := xmltest.LookupPreset(name); !ok {
+			t.Errorf("%s: not registered", name)
+		}
+	}
+	ok, err := xmltest.EqualXML(
+		strings.NewReader(`<testsuite><testcase classname="a" name="b" time="1"/></testsuite>`),
+		strings.NewReader(`<testsuite><testcase classname="a" name="b" time="2"/></testsuite>`),
+		xmltest.Preset("junit"))
+	if err != nil || !ok {
+		t.Errorf("junit: got %v, %v, want true, nil", ok, err)
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+// Progress reports how far normalization has got.
+type Progress struct {
+	// BytesRead counts the bytes of input decoded, or zero if the tokens
+	// are not read by an *xml.Decoder, and BytesWritten the bytes of
+	// output.
+	BytesRead, BytesWritten int64
+	// Tokens counts the normalized tokens written.
+	Tokens int64
+}
+
+// progressInterval returns the number of tokens between calls of
+// OnProgress.
+func (n *Normalizer) progressInterval() int64 {
+	if n.ProgressInterval > 0 {
+		return int64(n.ProgressInterval)
+	}
+	return 10000
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOnProgress(t *testing.T) {
+	in := `<a><b/><c>x</c></a>`
+	var got []Progress
+	n := New(OnProgress(3, func(p Progress) error {
+		got = append(got, p)
+		return nil
+	}))
+	var buf bytes.Buffer
+	if err := n.Normalize(&buf, strings.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	// The tokens are <a>, <b>, </b>, <c>, x, </c> and </a>.
+	if len(got) != 3 {
+		t.Fatalf("got %d reports, want 3", len(got))
+	}
+	for i, want := range []int64{3, 6, 7} {
+		if got[i].Tokens != want {
+			t.Errorf("report %d: got %d tokens, want %d", i, got[i].Tokens, want)
+		}
+	}
+	last := got[len(got)-1]
+	if last.BytesRead != int64(len(in)) {
+		t.Errorf("got %d bytes read, want %d", last.BytesRead, len(in))
+	}
+	if last.BytesWritten != int64(buf.Len()) {
+		t.Errorf("got %d bytes written, want %d", last.BytesWritten, buf.Len())
+	}
+}
+
+func TestOnProgressStops(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	calls := 0
+	n := New(OnProgress(1, func(p Progress) error {
+		calls++
+		return errTimeout
+	}))
+	var buf bytes.Buffer
+	if err := n.Normalize(&buf, strings.NewReader(`<a><b/></a>`)); err != errTimeout {
+		t.Errorf("got error %v, want %v", err, errTimeout)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Record reads all tokens of the XML content of r, as returned by
+// xml.Decoder.Token. The tokens are copies and remain valid.
+func Record(r io.Reader) ([]xml.Token, error) {
+	return recordTokens(xml.NewDecoder(r))
+}
+
+// recordTokens reads all tokens of tr and copies them.
+func recordTokens(tr xml.TokenReader) ([]xml.Token, error) {
+	var toks []xml.Token
+	for {
+		t, err := tr.Token()
+		if t != nil {
+			toks = append(toks, xml.CopyToken(t))
+		}
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return toks, err
+		}
+	}
+}
+
+// Replay returns an xml.TokenReader that returns the tokens toks, and then
+// io.EOF. It returns copies, so consumers may modify the tokens they
+// receive without affecting toks or later replays.
+func Replay(toks []xml.Token) xml.TokenReader {
+	return &replayer{toks: toks}
+}
+
+type replayer struct {
+	toks []xml.Token
+}
+
+func (r *replayer) Token() (xml.Token, error) {
+	if len(r.toks) == 0 {
+		return nil, io.EOF
+	}
+	t := r.toks[0]
+	r.toks = r.toks[1:]
+	return xml.CopyToken(t), nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	toks, err := Record(strings.NewReader(`<root b="2" a="1">x<!--c--></root>`))
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	root := xml.Name{Local: "root"}
+	want := []xml.Token{
+		xml.StartElement{Name: root, Attr: []xml.Attr{
+			{Name: xml.Name{Local: "b"}, Value: "2"},
+			{Name: xml.Name{Local: "a"}, Value: "1"},
+		}},
+		xml.CharData("x"),
+		xml.Comment("c"),
+		xml.EndElement{Name: root},
+	}
+	if !reflect.DeepEqual(toks, want) {
+		t.Fatalf("\ngot  %#v\nwant %#v", toks, want)
+	}
+
+	// Modifying replayed tokens does not affect the recording.
+	tr := Replay(toks)
+	tok, _ := tr.Token()
+	tok.(xml.StartElement).Attr[0].Value = "changed"
+	if !reflect.DeepEqual(toks, want) {
+		t.Errorf("recording modified by consumer")
+	}
+
+	var n Normalizer
+	var b strings.Builder
+	if err := n.NormalizeTokens(&b, Replay(toks)); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if got, want := b.String(), `<root a="1" b="2">x<!--c--></root>`; got != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+
+	if _, err := Record(strings.NewReader(`<root>`)); err == nil {
+		t.Errorf("unclosed element: got nil error, want some")
+	}
+}
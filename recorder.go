@@ -0,0 +1,90 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Recorder is an http.RoundTripper that writes the XML payloads of the
+// requests and responses it transports, as normalized by Normalize, to
+// golden files in Dir. The i-th exchange, counting from one, is written to
+// the files 001.request.xml and 001.response.xml, and so on. Payloads that
+// are empty or do not have an XML content type are not written.
+type Recorder struct {
+	// Dir is the directory of the golden files. It must exist.
+	Dir string
+	// Transport performs the requests. If nil, http.DefaultTransport is
+	// used.
+	Transport http.RoundTripper
+	// Normalizer normalizes the payloads. If nil, the zero Normalizer is
+	// used.
+	Normalizer *Normalizer
+
+	mu  sync.Mutex
+	seq int
+}
+
+// RoundTrip implements http.RoundTripper. It fails if a payload is not
+// well-formed or cannot be written.
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec.mu.Lock()
+	rec.seq++
+	prefix := fmt.Sprintf("%03d", rec.seq)
+	rec.mu.Unlock()
+
+	if req.Body != nil && isXMLMediaType(req.Header.Get("Content-Type")) {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if err := rec.write(prefix+".request.xml", body); err != nil {
+			return nil, err
+		}
+	}
+	t := rec.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	resp, err := t.RoundTrip(req)
+	if err != nil || !isXMLMediaType(resp.Header.Get("Content-Type")) {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err := rec.write(prefix+".response.xml", body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// write normalizes the payload and writes it to the golden file name.
+func (rec *Recorder) write(name string, payload []byte) error {
+	if len(payload) == 0 {
+		return nil
+	}
+	n := rec.Normalizer
+	if n == nil {
+		n = new(Normalizer)
+	}
+	var b bytes.Buffer
+	if err := n.Normalize(&b, bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("xmltest: recording %s: %v", name, err)
+	}
+	return os.WriteFile(filepath.Join(rec.Dir, name), b.Bytes(), 0o666)
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	s := NewServer(nil, `<r  b="2" a="1"/>`)
+	defer s.Close()
+	dir := t.TempDir()
+	client := &http.Client{Transport: &Recorder{Dir: dir}}
+
+	resp, err := client.Post(s.URL, "text/xml", strings.NewReader(`<q><![CDATA[x]]></q>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `<r  b="2" a="1"/>`; got != want {
+		t.Errorf("got response %s, want %s", got, want)
+	}
+	s.AssertRequestEqualXML(t, 0, `<q>x</q>`)
+	if resp, err = client.Post(s.URL, "text/plain", strings.NewReader(`<q/>`)); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := map[string]string{
+		"001.request.xml":  `<q>x</q>`,
+		"001.response.xml": `<r a="1" b="2"></r>`,
+		"002.response.xml": `<r a="1" b="2"></r>`,
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) {
+		t.Errorf("got files %v, want %d", files, len(want))
+	}
+	for name, content := range want {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(b) != content {
+			t.Errorf("%s: got %s, want %s", name, b, content)
+		}
+	}
+	CheckGoldens(t, nil, filepath.Join(dir, "*.xml"))
+}
+
+func TestRecorderMalformed(t *testing.T) {
+	s := NewServer(nil, `<r>`)
+	defer s.Close()
+	client := &http.Client{Transport: &Recorder{Dir: t.TempDir()}}
+	if _, err := client.Get(s.URL); err == nil {
+		t.Errorf("got nil error, want error for malformed response")
+	}
+}
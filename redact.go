@@ -0,0 +1,37 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import "encoding/xml"
+
+// defaultRedaction replaces redacted content if Normalizer.Redaction is
+// empty.
+const defaultRedaction = "REDACTED"
+
+// redaction returns the replacement of redacted content.
+func (n *Normalizer) redaction() string {
+	if n.Redaction == "" {
+		return defaultRedaction
+	}
+	return n.Redaction
+}
+
+// redactAttrs returns t with the values of redacted attributes replaced,
+// so that it can be traced.
+func (n *Normalizer) redactAttrs(t xml.Token) xml.Token {
+	start, ok := t.(xml.StartElement)
+	if !ok || len(n.RedactAttrs) == 0 {
+		return t
+	}
+	attr := make([]xml.Attr, len(start.Attr))
+	for i, a := range start.Attr {
+		if matchName(n.RedactAttrs, a.Name) {
+			a.Value = n.redaction()
+		}
+		attr[i] = a
+	}
+	start.Attr = attr
+	return start
+}
@@ -0,0 +1,66 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+		want string
+	}{{
+		desc: "attributes",
+		n:    Normalizer{RedactAttrs: []xml.Name{{Local: "token"}}},
+		in:   `<r><a token="secret" b="1"/><c token="other"/></r>`,
+		want: `<r><a b="1" token="REDACTED"></a><c token="REDACTED"></c></r>`,
+	}, {
+		desc: "elements",
+		n:    Normalizer{RedactElements: []xml.Name{{Local: "password"}}},
+		in:   `<r><password kind="x">secret<b>more</b><!-- c --></password><password/><a>x</a></r>`,
+		want: `<r><password kind="x">REDACTED</password><password>REDACTED</password><a>x</a></r>`,
+	}, {
+		desc: "nested redacted elements",
+		n:    Normalizer{RedactElements: []xml.Name{{Local: "s"}}},
+		in:   `<r><s><s>secret</s></s></r>`,
+		want: `<r><s>REDACTED</s></r>`,
+	}, {
+		desc: "custom replacement",
+		n:    Normalizer{RedactAttrs: []xml.Name{{Local: "k"}}, RedactElements: []xml.Name{{Local: "s"}}, Redaction: "***"},
+		in:   `<r k="secret"><s>secret</s></r>`,
+		want: `<r k="***"><s>***</s></r>`,
+	}}
+
+	for _, tc := range testCases {
+		var b, trace strings.Builder
+		tc.n.Trace = &trace
+		if err := tc.n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.want)
+		}
+		if strings.Contains(trace.String(), "secret") || strings.Contains(trace.String(), "more") {
+			t.Errorf("%s: trace reveals secret:\n%s", tc.desc, trace.String())
+		}
+	}
+}
+
+func TestRedactDiff(t *testing.T) {
+	n := Normalizer{RedactAttrs: []xml.Name{{Local: "token"}}, RedactElements: []xml.Name{{Local: "pw"}}}
+	diffs, err := n.Diff(strings.NewReader(`<r token="a"><pw>a</pw><x>1</x></r>`), strings.NewReader(`<r token="b"><pw>b</pw><x>2</x></r>`))
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if len(diffs) != 1 || diffs[0].String() != `/r/x/text(): "1" != "2"` {
+		t.Errorf("got %v, want one difference of x", diffs)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import "fmt"
+
+// Rules identifies a version of the normalization rules. The heuristics
+// of the package may evolve in new versions, which change the normalized
+// output of some documents. The zero value selects RulesV1, so new
+// versions only apply if selected, and golden files keep comparing stably.
+//
+// Rules govern which content is kept and how it is rewritten. They do not
+// pin the serialization details of Normalize, such as namespace prefixes.
+type Rules int
+
+const (
+	// RulesV1 are the rules of the original release.
+	RulesV1 Rules = iota
+	// RulesV2 additionally remove whitespace outside of the root element,
+	// and reject a second root element and character data outside of the
+	// root element unless several documents or a fragment are accepted.
+	RulesV2
+)
+
+// RulesLatest selects the latest version of the rules, whichever it is
+// in the release in use.
+const RulesLatest Rules = -1
+
+// latestRules is the version selected by RulesLatest.
+const latestRules = RulesV2
+
+func (r Rules) String() string {
+	switch r {
+	case RulesLatest:
+		return "RulesLatest"
+	case RulesV1:
+		return "RulesV1"
+	case RulesV2:
+		return "RulesV2"
+	}
+	return fmt.Sprintf("Rules(%d)", int(r))
+}
+
+// rules returns the version of the rules that n applies.
+func (n *Normalizer) rules() Rules {
+	if n.Rules == RulesLatest {
+		return latestRules
+	}
+	return n.Rules
+}
@@ -0,0 +1,69 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// XMLCase is a test case for RunXMLTests.
+type XMLCase struct {
+	// Name names the subtest of the case.
+	Name string
+	// Input is the XML content under test.
+	Input string
+	// Want is the expected XML content, compared to Input after
+	// normalization.
+	Want string
+	// WantErr expects normalizing Input to fail. Want is ignored then.
+	WantErr bool
+	// Options configure the Normalizer of the case.
+	Options []Option
+}
+
+// RunXMLTests runs each case as a subtest of t. A case fails if the
+// normalized XML content of its Input differs from that of Want, and the
+// failure reports the differences and the changed lines of their dumps, as
+// written by Normalizer.Dump.
+func RunXMLTests(t *testing.T, cases []XMLCase) {
+	t.Helper()
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Helper()
+			if err := checkXMLCase(c); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func checkXMLCase(c XMLCase) error {
+	n := New(c.Options...)
+	if c.WantErr {
+		if err := n.Normalize(io.Discard, strings.NewReader(c.Input)); err == nil {
+			return fmt.Errorf("xmltest: got nil error, want some")
+		}
+		return nil
+	}
+	return n.differenceError("input differs from want (a: input, b: want)", []byte(c.Input), []byte(c.Want))
+}
+
+// diffError returns an error that lists diffs, or nil if there are none.
+func diffError(msg string, diffs []Difference) error {
+	if len(diffs) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("xmltest: " + msg + ":")
+	for _, d := range diffs {
+		b.WriteString("\n\t" + d.String())
+	}
+	return errors.New(b.String())
+}
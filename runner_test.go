@@ -0,0 +1,58 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import "testing"
+
+func TestRunXMLTests(t *testing.T) {
+	RunXMLTests(t, []XMLCase{{
+		Name:  "attribute order",
+		Input: `<root b="2" a="1"/>`,
+		Want:  `<root a="1" b="2"></root>`,
+	}, {
+		Name:    "options",
+		Input:   `<root><!-- c --><a/></root>`,
+		Want:    `<root><a/></root>`,
+		Options: []Option{OmitComments()},
+	}, {
+		Name:    "error",
+		Input:   `<root></a>`,
+		WantErr: true,
+	}})
+}
+
+func TestCheckXMLCase(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		c       XMLCase
+		wantErr string
+	}{{
+		desc: "differences",
+		c:    XMLCase{Input: `<root a="1"><b/></root>`, Want: `<root a="2"/>`},
+		wantErr: "xmltest: input differs from want (a: input, b: want):\n" +
+			"\t/root/@a: \"1\" != \"2\"\n" +
+			"\t/root/b: only in a: <b>\n" +
+			"tree diff (-a +b):\n" +
+			"\t  element root\n" +
+			"\t-   @a \"1\"\n" +
+			"\t-   element b\n" +
+			"\t+   @a \"2\"",
+	}, {
+		desc:    "missing error",
+		c:       XMLCase{Input: `<root/>`, WantErr: true},
+		wantErr: "xmltest: got nil error, want some",
+	}}
+
+	for _, tc := range testCases {
+		err := checkXMLCase(tc.c)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.wantErr {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+)
+
+// Schema describes the content of elements and their attributes, so that
+// normalization can remove ignorable whitespace, add default attributes
+// and collapse whitespace in values as a validating parser would.
+// ParseSchema and ParseDTD return the Schema of an XML Schema and of a
+// DTD. Names match elements and attributes by local name.
+type Schema struct {
+	// elements holds the global element declarations by local name.
+	elements map[string]*elementDecl
+}
+
+// elementDecl declares an element.
+type elementDecl struct {
+	typ *contentType
+}
+
+// contentType describes the content and attributes of elements. A nil
+// contentType describes elements of any content.
+type contentType struct {
+	// elementOnly reports whether the content consists of elements only,
+	// so that whitespace between them is ignorable.
+	elementOnly bool
+	// space is the whitespace handling of character data.
+	space whiteSpace
+	// attrs holds the attribute declarations by local name, and children
+	// the local element declarations.
+	attrs    map[string]attrDecl
+	children map[string]*elementDecl
+}
+
+// attrDecl declares an attribute.
+type attrDecl struct {
+	space      whiteSpace
+	def        string
+	hasDefault bool
+}
+
+// whiteSpace is the whitespace handling of a value.
+type whiteSpace int
+
+const (
+	spacePreserve whiteSpace = iota
+	// spaceReplace replaces tabs and line breaks by spaces.
+	spaceReplace
+	// spaceCollapse additionally collapses runs of spaces to one and
+	// removes leading and trailing spaces.
+	spaceCollapse
+)
+
+// apply returns s with the whitespace handling ws applied.
+func (ws whiteSpace) apply(s string) string {
+	switch ws {
+	case spaceReplace:
+		return strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, s)
+	case spaceCollapse:
+		return strings.Join(strings.Fields(s), " ")
+	}
+	return s
+}
+
+// child returns the type of the element named local as a child of an
+// element of type parent, which is nil for root elements.
+func (s *Schema) child(parent *contentType, local string) *contentType {
+	if parent != nil {
+		if d, ok := parent.children[local]; ok {
+			return d.typ
+		}
+	}
+	if d, ok := s.elements[local]; ok {
+		return d.typ
+	}
+	return nil
+}
+
+// applyAttrs returns attrs with the defaults of ct added, sorted by name
+// so that their order is stable, and whitespace handling applied to the
+// declared unqualified attributes.
+func (ct *contentType) applyAttrs(attrs []xml.Attr) []xml.Attr {
+	if ct == nil || len(ct.attrs) == 0 {
+		return attrs
+	}
+	out := make([]xml.Attr, 0, len(attrs)+len(ct.attrs))
+	seen := map[string]bool{}
+	for _, a := range attrs {
+		if a.Name.Space == "" {
+			seen[a.Name.Local] = true
+			if d, ok := ct.attrs[a.Name.Local]; ok {
+				a.Value = d.space.apply(a.Value)
+			}
+		}
+		out = append(out, a)
+	}
+	var missing []string
+	for name, d := range ct.attrs {
+		if d.hasDefault && !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		d := ct.attrs[name]
+		out = append(out, xml.Attr{Name: xml.Name{Local: name}, Value: d.space.apply(d.def)})
+	}
+	return out
+}
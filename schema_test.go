@@ -0,0 +1,61 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWhiteSpace(t *testing.T) {
+	testCases := []struct {
+		desc string
+		ws   whiteSpace
+		in   string
+		want string
+	}{{
+		desc: "preserve",
+		ws:   spacePreserve,
+		in:   " a\t b\n",
+		want: " a\t b\n",
+	}, {
+		desc: "replace",
+		ws:   spaceReplace,
+		in:   " a\t b\r\n",
+		want: " a  b  ",
+	}, {
+		desc: "collapse",
+		ws:   spaceCollapse,
+		in:   " a\t b\r\n",
+		want: "a b",
+	}}
+
+	for _, tc := range testCases {
+		if got := tc.ws.apply(tc.in); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestSchemaKeepAttrOrder(t *testing.T) {
+	s, err := ParseDTD(strings.NewReader(`<!ATTLIST a p CDATA "1" t CDATA "5" r CDATA "3" s CDATA "4" q CDATA "2">`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := Normalizer{Schema: s, KeepAttrOrder: true}
+	const want = `<a z="0" p="1" q="2" r="3" s="4" t="5"></a>`
+	for i := 0; i < 20; i++ {
+		var b strings.Builder
+		if err := n.Normalize(&b, strings.NewReader(`<a z="0"/>`)); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+		if equal, err := n.EqualXML(strings.NewReader(`<a/>`), strings.NewReader(`<a/>`)); err != nil || !equal {
+			t.Fatalf("EqualXML: got %v, %v, want true, nil", equal, err)
+		}
+	}
+}
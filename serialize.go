@@ -0,0 +1,347 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Format configures how Normalize writes normalized XML. The zero Format
+// writes all tags on one line, and empty elements as <a></a>.
+type Format struct {
+	// Indent, if not empty, starts each element and comment on a new line,
+	// indented by one Indent per enclosing element. Elements with
+	// character data keep their content on one line. As indentation adds
+	// whitespace to the output, it is meant to be used with
+	// OmitWhitespace.
+	Indent string
+	// SelfClosing instructs to write empty elements as <a/>.
+	SelfClosing bool
+	// FinalNewline instructs to end non-empty output with a newline.
+	FinalNewline bool
+}
+
+var errCommentMarker = errors.New("xmltest: comment contains -->")
+
+// tokenWriter writes normalized tokens. Unlike an xml.Encoder, it derives
+// namespace prefixes from the namespace URIs by a fixed heuristic, see
+// nsPrefix, so that its output does not depend on the Go version.
+type tokenWriter struct {
+	w      *bufio.Writer
+	format Format
+	// ns holds the namespace bindings in scope, and seq numbers prefixes
+	// that would be taken otherwise.
+	ns  []nsBinding
+	seq int
+	// prefixes, if not nil, assigns the prefixes instead.
+	prefixes *prefixTable
+	// open holds the open elements.
+	open []openElement
+	// pending reports whether the last start tag still lacks its '>'.
+	pending bool
+	wrote   bool
+}
+
+type nsBinding struct {
+	prefix, uri string
+}
+
+type openElement struct {
+	// name is the qualified name of the element, and ns the length of
+	// tokenWriter.ns outside of it.
+	name string
+	ns   int
+	// children and text report whether the element has child nodes other
+	// than character data, and character data.
+	children, text bool
+}
+
+func newTokenWriter(w *bufio.Writer, format Format) *tokenWriter {
+	return &tokenWriter{w: w, format: format}
+}
+
+// writeToken writes the normalized token t.
+func (tw *tokenWriter) writeToken(t xml.Token) error {
+	switch t := t.(type) {
+	case xml.StartElement:
+		tw.closeStart()
+		tw.indent()
+		tw.writeStart(t)
+	case xml.EndElement:
+		e := tw.open[len(tw.open)-1]
+		tw.open = tw.open[:len(tw.open)-1]
+		tw.ns = tw.ns[:e.ns]
+		if tw.pending && tw.format.SelfClosing {
+			tw.pending = false
+			tw.w.WriteString("/>")
+			break
+		}
+		tw.closeStart()
+		if e.children && !e.text {
+			tw.newline(len(tw.open))
+		}
+		tw.w.WriteString("</")
+		tw.w.WriteString(e.name)
+		tw.w.WriteByte('>')
+	case xml.CharData:
+		tw.closeStart()
+		if len(tw.open) > 0 {
+			tw.open[len(tw.open)-1].text = true
+		}
+		escapeText(tw.w, t, false)
+	case xml.Comment:
+		if strings.Contains(string(t), "-->") {
+			return errCommentMarker
+		}
+		tw.closeStart()
+		tw.indent()
+		tw.w.WriteString("<!--")
+		tw.w.Write(t)
+		tw.w.WriteString("-->")
+	case xml.Directive:
+		tw.closeStart()
+		tw.indent()
+		tw.w.WriteString("<!")
+		tw.w.Write(t)
+		tw.w.WriteByte('>')
+	case xml.ProcInst:
+		tw.closeStart()
+		tw.indent()
+		tw.w.WriteString("<?")
+		tw.w.WriteString(t.Target)
+		if len(t.Inst) > 0 {
+			tw.w.WriteByte(' ')
+			tw.w.Write(t.Inst)
+		}
+		tw.w.WriteString("?>")
+	}
+	tw.wrote = true
+	return nil
+}
+
+// close completes the output and flushes it.
+func (tw *tokenWriter) close() error {
+	tw.closeStart()
+	if tw.wrote && tw.format.FinalNewline {
+		tw.w.WriteByte('\n')
+	}
+	return tw.w.Flush()
+}
+
+// closeStart completes a pending start tag.
+func (tw *tokenWriter) closeStart() {
+	if tw.pending {
+		tw.pending = false
+		tw.w.WriteByte('>')
+	}
+}
+
+// indent starts a new line for a node that is not character data, if
+// indenting, and records it as a child of its parent.
+func (tw *tokenWriter) indent() {
+	if len(tw.open) == 0 {
+		if tw.wrote {
+			tw.newline(0)
+		}
+		return
+	}
+	parent := &tw.open[len(tw.open)-1]
+	parent.children = true
+	if !parent.text {
+		tw.newline(len(tw.open))
+	}
+}
+
+// newline writes a newline and depth indents, if indenting.
+func (tw *tokenWriter) newline(depth int) {
+	if tw.format.Indent == "" {
+		return
+	}
+	tw.w.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		tw.w.WriteString(tw.format.Indent)
+	}
+}
+
+// writeStart writes the start tag of t up to its '>'. Namespace
+// declarations precede the attributes, most recent first.
+func (tw *tokenWriter) writeStart(t xml.StartElement) {
+	mark := len(tw.ns)
+	name := tw.qualify(t.Name)
+	attrs := make([]string, len(t.Attr))
+	for i, a := range t.Attr {
+		attrs[i] = tw.qualify(a.Name)
+	}
+	tw.w.WriteByte('<')
+	tw.w.WriteString(name)
+	for i := len(tw.ns) - 1; i >= mark; i-- {
+		tw.w.WriteString(" xmlns:")
+		tw.w.WriteString(tw.ns[i].prefix)
+		tw.w.WriteString(`="`)
+		escapeText(tw.w, []byte(tw.ns[i].uri), true)
+		tw.w.WriteByte('"')
+	}
+	for i, a := range t.Attr {
+		tw.w.WriteByte(' ')
+		tw.w.WriteString(attrs[i])
+		tw.w.WriteString(`="`)
+		escapeText(tw.w, []byte(a.Value), true)
+		tw.w.WriteByte('"')
+	}
+	tw.pending = true
+	tw.open = append(tw.open, openElement{name: name, ns: mark})
+}
+
+// qualify returns the qualified name of name, declaring a prefix for its
+// namespace if none is in scope.
+func (tw *tokenWriter) qualify(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case xmlURL:
+		return "xml:" + name.Local
+	}
+	for i := len(tw.ns) - 1; i >= 0; i-- {
+		if tw.ns[i].uri == name.Space {
+			return tw.ns[i].prefix + ":" + name.Local
+		}
+	}
+	prefix := nsPrefix(name.Space)
+	if tw.prefixes != nil {
+		prefix = tw.prefixes.prefix(name.Space)
+	} else if tw.bound(prefix) {
+		for {
+			tw.seq++
+			if p := prefix + "_" + strconv.Itoa(tw.seq); !tw.bound(p) {
+				prefix = p
+				break
+			}
+		}
+	}
+	tw.ns = append(tw.ns, nsBinding{prefix: prefix, uri: name.Space})
+	return prefix + ":" + name.Local
+}
+
+// prefixTable assigns prefixes to namespaces across documents, so that a
+// namespace gets the same prefix in each of them. Prefixes are unique
+// within the table.
+type prefixTable struct {
+	byURI map[string]string
+	used  map[string]bool
+}
+
+func newPrefixTable() *prefixTable {
+	return &prefixTable{byURI: map[string]string{}, used: map[string]bool{}}
+}
+
+// prefix returns the prefix of namespace uri, assigning one from
+// nsPrefix if it has none.
+func (pt *prefixTable) prefix(uri string) string {
+	if p, ok := pt.byURI[uri]; ok {
+		return p
+	}
+	p := nsPrefix(uri)
+	for i := 1; pt.used[p]; i++ {
+		p = nsPrefix(uri) + "_" + strconv.Itoa(i)
+	}
+	pt.byURI[uri], pt.used[p] = p, true
+	return p
+}
+
+// bound reports whether prefix is bound in scope.
+func (tw *tokenWriter) bound(prefix string) bool {
+	for _, b := range tw.ns {
+		if b.prefix == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// nsPrefix returns the preferred prefix of namespace uri, that is the last
+// path segment of uri, or "_" if it is not a valid prefix. Prefixes that
+// start with "xml" are reserved and get a leading "_".
+func nsPrefix(uri string) string {
+	prefix := strings.TrimRight(uri, "/")
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefix = prefix[i+1:]
+	}
+	if !isNCName(prefix) {
+		return "_"
+	}
+	if strings.HasPrefix(strings.ToLower(prefix), "xml") {
+		return "_" + prefix
+	}
+	return prefix
+}
+
+// isNCName reports whether s is a name without colons.
+func isNCName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c == utf8.RuneError || c == ':' {
+			return false
+		}
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c) && c != '-' && c != '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeText writes s to w escaped as in Canonical XML. Character data
+// escapes &, <, > and carriage returns. Attribute values, if attr, escape
+// &, <, double quotes, tabs, newlines and carriage returns. Characters not
+// allowed in XML are replaced by U+FFFD.
+func escapeText(w *bufio.Writer, s []byte, attr bool) {
+	last := 0
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRune(s[i:])
+		i += width
+		var esc string
+		switch {
+		case r == '&':
+			esc = "&amp;"
+		case r == '<':
+			esc = "&lt;"
+		case r == '>' && !attr:
+			esc = "&gt;"
+		case r == '"' && attr:
+			esc = "&quot;"
+		case r == '\t' && attr:
+			esc = "&#x9;"
+		case r == '\n' && attr:
+			esc = "&#xA;"
+		case r == '\r':
+			esc = "&#xD;"
+		case !isInCharacterRange(r) || r == utf8.RuneError && width == 1:
+			esc = "\uFFFD"
+		default:
+			continue
+		}
+		w.Write(s[last : i-width])
+		w.WriteString(esc)
+		last = i
+	}
+	w.Write(s[last:])
+}
+
+// isInCharacterRange reports whether r is allowed in XML documents.
+func isInCharacterRange(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
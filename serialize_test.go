@@ -0,0 +1,172 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+		want string
+	}{{
+		desc: "default",
+		in:   `<a><b/><c>x</c></a>`,
+		want: `<a><b></b><c>x</c></a>`,
+	}, {
+		desc: "self-closing",
+		n:    Normalizer{Format: Format{SelfClosing: true}},
+		in:   `<a><b x="1"></b><c>x</c></a>`,
+		want: `<a><b x="1"/><c>x</c></a>`,
+	}, {
+		desc: "final newline",
+		n:    Normalizer{Format: Format{FinalNewline: true}},
+		in:   `<a/>`,
+		want: "<a></a>\n",
+	}, {
+		desc: "final newline of empty output",
+		n:    Normalizer{Format: Format{FinalNewline: true}},
+		in:   ``,
+		want: ``,
+	}, {
+		desc: "indent",
+		n:    Normalizer{OmitWhitespace: true, Format: Format{Indent: "  ", SelfClosing: true}},
+		in:   "<a>\n<b><c/><!--x--></b>\n<d>text</d>\n</a>",
+		want: "<a>\n  <b>\n    <c/>\n    <!--x-->\n  </b>\n  <d>text</d>\n</a>",
+	}, {
+		desc: "indent keeps mixed content on one line",
+		n:    Normalizer{Format: Format{Indent: "\t"}},
+		in:   `<a><p>x<b>y</b>z</p></a>`,
+		want: "<a>\n\t<p>x<b>y</b>z</p>\n</a>",
+	}, {
+		desc: "indent between roots",
+		n:    Normalizer{AllowMultipleRoots: true, Format: Format{Indent: " ", FinalNewline: true}},
+		in:   `<a/><b/>`,
+		want: "<a></a>\n<b></b>\n",
+	}, {
+		desc: "prefix taken by other namespace",
+		in:   `<a xmlns="urn:x/p"><b xmlns="urn:y/p"/></a>`,
+		want: `<p:a xmlns:p="urn:x/p"><p_1:b xmlns:p_1="urn:y/p"></p_1:b></p:a>`,
+	}, {
+		desc: "prefix out of scope",
+		in:   `<r><a xmlns="urn:x/p"/><b xmlns="urn:y/p"/></r>`,
+		want: `<r><p:a xmlns:p="urn:x/p"></p:a><p:b xmlns:p="urn:y/p"></p:b></r>`,
+	}, {
+		desc: "invalid and reserved prefixes",
+		in:   `<r xmlns:a="urn:1" xmlns:b="http://x/xmlfoo/" a:x="1" b:y="2"/>`,
+		want: `<r xmlns:_="urn:1" xmlns:_xmlfoo="http://x/xmlfoo/" _xmlfoo:y="2" _:x="1"></r>`,
+	}, {
+		desc: "xml prefix",
+		in:   `<r xml:lang="en"/>`,
+		want: `<r xml:lang="en"></r>`,
+	}}
+
+	for _, tc := range testCases {
+		var b strings.Builder
+		if err := tc.n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestWriteCommentMarker(t *testing.T) {
+	var b strings.Builder
+	tw := newTokenWriter(bufio.NewWriter(&b), Format{})
+	if err := tw.writeToken(xml.Comment("a-->b")); err != errCommentMarker {
+		t.Errorf("got err %v, want %v", err, errCommentMarker)
+	}
+}
+
+func TestNormalizeToEncoder(t *testing.T) {
+	var (
+		n Normalizer
+		b strings.Builder
+	)
+	e := xml.NewEncoder(&b)
+	e.Indent("", " ")
+	if err := n.NormalizeToEncoder(e, strings.NewReader(`<a y="2" x="1"><b/></a>`)); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	want := "<a x=\"1\" y=\"2\">\n <b></b>\n</a>"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		in         string
+		text, attr string
+	}{
+		{"ampersand", "&amp;", "&amp;", "&amp;"},
+		{"less than", "&lt;", "&lt;", "&lt;"},
+		{"greater than", "&gt;", "&gt;", ">"},
+		{"double quote", "&quot;", `"`, "&quot;"},
+		{"single quote", "&apos;", "'", "'"},
+		{"tab", "&#9;", "\t", "&#x9;"},
+		{"newline", "&#10;", "\n", "&#xA;"},
+		{"carriage return", "&#13;", "&#xD;", "&#xD;"},
+		{"non-ASCII", "ä&#x20AC;", "ä€", "ä€"},
+		{"plain", "abc", "abc", "abc"},
+	}
+
+	var n Normalizer
+	for _, tc := range testCases {
+		in := `<r a="` + tc.in + `">` + tc.in + `</r>`
+		want := `<r a="` + tc.attr + `">` + tc.text + `</r>`
+		var b strings.Builder
+		if err := n.Normalize(&b, strings.NewReader(in)); err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != want {
+			t.Errorf("%s: got %q, want %q", tc.desc, got, want)
+		}
+	}
+}
+
+func TestEscapeInvalid(t *testing.T) {
+	for _, attr := range []bool{false, true} {
+		var b strings.Builder
+		w := bufio.NewWriter(&b)
+		escapeText(w, []byte("a\x00b\xffc"), attr)
+		w.Flush()
+		if got, want := b.String(), "a\uFFFDb\uFFFDc"; got != want {
+			t.Errorf("attr %v: got %q, want %q", attr, got, want)
+		}
+	}
+}
+
+func TestNormalizePair(t *testing.T) {
+	a := `<r><x xmlns="urn:a/ns"/><y xmlns="urn:b/ns"/></r>`
+	b := `<r><y xmlns="urn:b/ns"/><x xmlns="urn:a/ns"/></r>`
+	var wa, wb strings.Builder
+	if err := new(Normalizer).NormalizePair(&wa, &wb, strings.NewReader(a), strings.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	wantA := `<r><ns:x xmlns:ns="urn:a/ns"></ns:x><ns_1:y xmlns:ns_1="urn:b/ns"></ns_1:y></r>`
+	wantB := `<r><ns_1:y xmlns:ns_1="urn:b/ns"></ns_1:y><ns:x xmlns:ns="urn:a/ns"></ns:x></r>`
+	if wa.String() != wantA || wb.String() != wantB {
+		t.Errorf("got\n%s\n%s\nwant\n%s\n%s", wa.String(), wb.String(), wantA, wantB)
+	}
+
+	err := new(Normalizer).NormalizePair(&wa, &wb, strings.NewReader(a), strings.NewReader(`<r>`))
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Errorf("malformed: got %v, want *SyntaxError", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Server is an HTTP server for client tests that answers requests with
+// XML responses and records the request bodies. Close it when done.
+type Server struct {
+	*httptest.Server
+	n         *Normalizer
+	mu        sync.Mutex
+	responses []string
+	requests  [][]byte
+}
+
+// NewServer starts a Server that answers the i-th request with the i-th
+// response, and all requests beyond the last response with the last
+// response, as application/xml. Without responses it answers with an
+// empty body. AssertRequestEqualXML compares requests as normalized by n;
+// a nil n is the zero Normalizer.
+func NewServer(n *Normalizer, responses ...string) *Server {
+	if n == nil {
+		n = new(Normalizer)
+	}
+	s := &Server{n: n, responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	s.mu.Lock()
+	i := len(s.requests)
+	s.requests = append(s.requests, body)
+	s.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	if len(s.responses) > 0 {
+		if i >= len(s.responses) {
+			i = len(s.responses) - 1
+		}
+		io.WriteString(w, s.responses[i])
+	}
+}
+
+// Requests returns the bodies of the requests received so far, in order.
+func (s *Server) Requests() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.requests...)
+}
+
+// AssertRequestEqualXML reports an error to t unless the server received
+// an i-th request, counting from zero, whose body has the same normalized
+// XML content as want.
+func (s *Server) AssertRequestEqualXML(t *testing.T, i int, want string) {
+	t.Helper()
+	if err := s.requestError(i, want); err != nil {
+		t.Error(err)
+	}
+}
+
+func (s *Server) requestError(i int, want string) error {
+	reqs := s.Requests()
+	if i < 0 || i >= len(reqs) {
+		return fmt.Errorf("xmltest: no request %d, got %d requests", i, len(reqs))
+	}
+	return s.n.differenceError(fmt.Sprintf("request %d differs (a: request, b: want)", i), reqs[i], []byte(want))
+}
@@ -0,0 +1,79 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer(New(OmitWhitespace()), `<r n="1"/>`, `<r n="2"/>`)
+	defer s.Close()
+	var got []string
+	for _, body := range []string{"<q>\n  <a/>\n</q>", `<q><b/></q>`, `<q/>`} {
+		resp, err := http.Post(s.URL, "application/xml", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/xml" {
+			t.Errorf("got content type %q, want application/xml", ct)
+		}
+		b, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(b))
+	}
+	want := []string{`<r n="1"/>`, `<r n="2"/>`, `<r n="2"/>`}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("response %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+	if n := len(s.Requests()); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+
+	s.AssertRequestEqualXML(t, 0, `<q><a></a></q>`)
+	testCases := []struct {
+		desc    string
+		i       int
+		want    string
+		wantErr bool
+	}{
+		{desc: "equal", i: 1, want: `<q><b/></q>`},
+		{desc: "different", i: 1, want: `<q><c/></q>`, wantErr: true},
+		{desc: "out of range", i: 3, want: `<q/>`, wantErr: true},
+		{desc: "negative", i: -1, want: `<q/>`, wantErr: true},
+		{desc: "malformed want", i: 2, want: `<q>`, wantErr: true},
+	}
+	for _, tc := range testCases {
+		err := s.requestError(tc.i, tc.want)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestServerNoResponses(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(b) != 0 {
+		t.Errorf("got body %q, want empty", b)
+	}
+	if err := s.requestError(0, `<a/>`); err == nil {
+		t.Errorf("empty request body: got nil error, want error")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Similarity returns a score between 0 and 1 of how similar the normalized
+// XML contents of a and b are. Equal documents score 1, documents without
+// common content score 0.
+//
+// The score is the Dice coefficient of the features of both documents.
+// Each element, attribute, text and comment is a feature, identified by its
+// value and the names of its enclosing elements. Features compare
+// literally, regardless of any tolerance configured for EqualXML.
+func (n *Normalizer) Similarity(a, b io.Reader) (float64, error) {
+	fa, err := n.features(a)
+	if err != nil {
+		return 0, err
+	}
+	fb, err := n.features(b)
+	if err != nil {
+		return 0, err
+	}
+	total, common := 0, 0
+	for f, ca := range fa {
+		total += ca
+		if cb := fb[f]; cb < ca {
+			common += cb
+		} else {
+			common += ca
+		}
+	}
+	for _, cb := range fb {
+		total += cb
+	}
+	if total == 0 {
+		return 1, nil
+	}
+	return float64(2*common) / float64(total), nil
+}
+
+// features returns the multiset of features of the normalized content of r.
+func (n *Normalizer) features(r io.Reader) (map[string]int, error) {
+	toks, err := n.readTokens(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		path []string
+		fs   = map[string]int{}
+	)
+	for _, t := range toks {
+		switch t := t.(type) {
+		case xml.StartElement:
+			path = append(path, "{"+t.Name.Space+"}"+t.Name.Local)
+			p := strings.Join(path, "/")
+			fs[p]++
+			for _, a := range t.Attr {
+				fs[p+"/@{"+a.Name.Space+"}"+a.Name.Local+"="+a.Value]++
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			fs[strings.Join(path, "/")+"/text()="+string(t)]++
+		case xml.Comment:
+			fs[strings.Join(path, "/")+"/comment()="+string(t)]++
+		}
+	}
+	return fs, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		a, b string
+		want float64
+	}{{
+		desc: "equal",
+		a:    `<root a="1"><foo>bar</foo></root>`,
+		b:    `<root a="1"><foo>bar</foo></root>`,
+		want: 1,
+	}, {
+		desc: "disjoint",
+		a:    `<foo/>`,
+		b:    `<bar/>`,
+		want: 0,
+	}, {
+		desc: "one of four features differs",
+		a:    `<root a="1"><foo>bar</foo></root>`,
+		b:    `<root a="1"><foo>baz</foo></root>`,
+		want: 0.75,
+	}, {
+		desc: "normalization applies",
+		n:    Normalizer{OmitWhitespace: true},
+		a:    `<root> <foo/> </root>`,
+		b:    `<root><foo/></root>`,
+		want: 1,
+	}}
+
+	for _, tc := range testCases {
+		got, err := tc.n.Similarity(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.want)
+		}
+	}
+}
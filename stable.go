@@ -0,0 +1,128 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// selfTestCases pin the output of Normalize. The namespace prefixes and
+// escaping are produced by this package, but parsing is left to
+// encoding/xml, so a change in either shows up here. Changing a case
+// breaks golden files written with earlier versions.
+var selfTestCases = []struct {
+	n        Normalizer
+	in, want string
+}{
+	{in: `<a/>`, want: `<a></a>`},
+	{in: `<a xmlns="urn:x/s"/>`, want: `<s:a xmlns:s="urn:x/s"></s:a>`},
+	{in: `<p:a xmlns:p="http://x/s/"><b/></p:a>`, want: `<s:a xmlns:s="http://x/s/"><b></b></s:a>`},
+	{in: `<a xmlns="s"><b xmlns="t/s"/></a>`, want: `<s:a xmlns:s="s"><s_1:b xmlns:s_1="t/s"></s_1:b></s:a>`},
+	{in: `<a xmlns:p="p" xmlns:q="q" q:y="2" p:x="1"/>`, want: `<a xmlns:q="q" xmlns:p="p" p:x="1" q:y="2"></a>`},
+	{in: `<a xmlns:p="1" xmlns:q="xmlq" p:x="" q:y=""/>`, want: `<a xmlns:_xmlq="xmlq" xmlns:_="1" _:x="" _xmlq:y=""></a>`},
+	{in: `<a xml:lang="en"/>`, want: `<a xml:lang="en"></a>`},
+	{in: `<a c="3" b="2" a="1"/>`, want: `<a a="1" b="2" c="3"></a>`},
+	{in: "<a v=\"&quot;'&lt;>&amp;&#9;&#10;&#13;\"/>", want: "<a v=\"&quot;'&lt;>&amp;&#x9;&#xA;&#xD;\"></a>"},
+	{in: "<a>\"'&lt;&gt;&amp;\t\n&#13;</a>", want: "<a>\"'&lt;&gt;&amp;\t\n&#xD;</a>"},
+	{in: `<a><![CDATA[<b>]]></a>`, want: `<a>&lt;b&gt;</a>`},
+	{in: "<?xml version='1.0'?>\n<!DOCTYPE a>\n<a><?pi x?><!-- c --></a>\n", want: "\n\n<a><!-- c --></a>\n"},
+	{n: Normalizer{Rules: RulesV2}, in: "<?xml version='1.0'?>\n<a/>\n", want: `<a></a>`},
+	{in: "<a>\n <b/>\n</a>", want: "<a>\n <b></b>\n</a>"},
+	{
+		n:    Normalizer{OmitWhitespace: true, XMLDeclaration: true, Format: Format{Indent: "\t", SelfClosing: true, FinalNewline: true}},
+		in:   "<a>\n <b/><c>x</c>\n</a>",
+		want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<a>\n\t<b/>\n\t<c>x</c>\n</a>\n",
+	},
+}
+
+// SelfTest verifies that Normalize writes the output that this package
+// guarantees across Go versions, and reports the deviations otherwise. Run
+// it after a Go upgrade to make sure that golden files written by Normalize
+// remain valid; see also CheckGoldens.
+func SelfTest() error {
+	var msgs []string
+	for _, tc := range selfTestCases {
+		var b bytes.Buffer
+		if err := tc.n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			msgs = append(msgs, fmt.Sprintf("%q: %v", tc.in, err))
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			msgs = append(msgs, fmt.Sprintf("%q: got %q, want %q", tc.in, got, tc.want))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New("xmltest: self-test failed:\n\t" + strings.Join(msgs, "\n\t"))
+}
+
+// CheckGoldens reports an error to t for each golden file matching the
+// pattern that is not the output of n for its own content, as written by
+// Normalize with n. A golden file that changes under the current Go
+// version would fail byte-wise comparisons. A nil n is the zero
+// Normalizer. CheckGoldens fails if no file matches.
+func CheckGoldens(t testing.TB, n *Normalizer, pattern string) {
+	t.Helper()
+	if n == nil {
+		n = new(Normalizer)
+	}
+	for _, err := range n.goldenViolations(pattern) {
+		t.Error(err)
+	}
+}
+
+func (n *Normalizer) goldenViolations(pattern string) []error {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return []error{err}
+	}
+	if len(files) == 0 {
+		return []error{fmt.Errorf("xmltest: no files match %s", pattern)}
+	}
+	var errs []error
+	for _, f := range files {
+		golden, err := os.ReadFile(f)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var b bytes.Buffer
+		if err := n.Normalize(&b, bytes.NewReader(golden)); err != nil {
+			errs = append(errs, fmt.Errorf("xmltest: %s: %v", f, err))
+			continue
+		}
+		if line, got, want, ok := firstDifference(b.Bytes(), golden); ok {
+			errs = append(errs, fmt.Errorf("xmltest: %s is no longer normalized output, line %d:\n\tgot  %q\n\twant %q", f, line, got, want))
+		}
+	}
+	return errs
+}
+
+// firstDifference returns the number and contents of the first line in
+// which a and b differ, if they differ.
+func firstDifference(a, b []byte) (line int, la, lb string, ok bool) {
+	if bytes.Equal(a, b) {
+		return 0, "", "", false
+	}
+	as, bs := strings.SplitAfter(string(a), "\n"), strings.SplitAfter(string(b), "\n")
+	for i := 0; ; i++ {
+		if i >= len(as) || i >= len(bs) || as[i] != bs[i] {
+			if i < len(as) {
+				la = as[i]
+			}
+			if i < len(bs) {
+				lb = bs[i]
+			}
+			return i + 1, la, lb, true
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoldenViolations(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"ok.xml":      `<s:a xmlns:s="s"><b x="1"></b></s:a>`,
+		"prefix.xml":  `<a xmlns="s"><b x="1"></b></a>`,
+		"escape.xml":  "<a>\n<b v=\"&#34;\"></b></a>",
+		"invalid.xml": `<a>`,
+	})
+	var n Normalizer
+	var got []string
+	for _, err := range n.goldenViolations(filepath.Join(dir, "*.xml")) {
+		got = append(got, strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""))
+	}
+	want := []string{
+		"xmltest: escape.xml is no longer normalized output, line 2:\n\tgot  \"<b v=\\\"&quot;\\\"></b></a>\"\n\twant \"<b v=\\\"&#34;\\\"></b></a>\"",
+		"xmltest: invalid.xml: xmltest: syntax error at line 1, column 4: unexpected EOF",
+		"xmltest: prefix.xml is no longer normalized output, line 1:\n\tgot  \"<s:a xmlns:s=\\\"s\\\"><s:b x=\\\"1\\\"></s:b></s:a>\"\n\twant \"<a xmlns=\\\"s\\\"><b x=\\\"1\\\"></b></a>\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("\ngot  %q\nwant %q", got, want)
+	}
+	if errs := n.goldenViolations(filepath.Join(dir, "*.none")); len(errs) != 1 {
+		t.Errorf("no match: got %v, want one error", errs)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// DocStats summarizes the structure of a normalized document. Names are
+// in Clark notation.
+type DocStats struct {
+	// Elements and Attributes count elements and attributes by name.
+	Elements   map[string]int
+	Attributes map[string]int
+	// MaxDepth is the nesting depth of the deepest element, 1 for a
+	// document of a single element.
+	MaxDepth int
+	// TextBytes counts the bytes of character data.
+	TextBytes int
+	// Namespaces counts the element and attribute names in each namespace
+	// by URI.
+	Namespaces map[string]int
+}
+
+// Stats returns the statistics of the normalized document r, so that
+// tests can cheaply assert structural properties such as a maximum depth
+// or the absence of an element. The document is streamed rather than read
+// into memory, unless elements are rearranged.
+func (n *Normalizer) Stats(r io.Reader) (DocStats, error) {
+	s := DocStats{
+		Elements:   map[string]int{},
+		Attributes: map[string]int{},
+		Namespaces: map[string]int{},
+	}
+	r, err := n.decompress(r)
+	if err != nil {
+		return s, err
+	}
+	d, release := n.newDecoder(r)
+	defer release()
+	tn := n.getTokenNormalizer(d)
+	defer putTokenNormalizer(tn)
+	depth := 0
+	for {
+		t, err := tn.Token()
+		if err == io.EOF {
+			return s, nil
+		}
+		if err != nil {
+			return s, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if depth++; depth > s.MaxDepth {
+				s.MaxDepth = depth
+			}
+			s.Elements[clarkName(t.Name)]++
+			if t.Name.Space != "" {
+				s.Namespaces[t.Name.Space]++
+			}
+			for _, a := range t.Attr {
+				s.Attributes[clarkName(a.Name)]++
+				if a.Name.Space != "" {
+					s.Namespaces[a.Name.Space]++
+				}
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			s.TextBytes += len(t)
+		}
+	}
+}
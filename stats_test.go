@@ -0,0 +1,58 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		n       Normalizer
+		in      string
+		want    DocStats
+		wantErr bool
+	}{{
+		desc: "counts",
+		in: `<r xmlns:p="urn:p" a="1"><p:x p:b="2">hé</p:x>` +
+			`<y><y><z/></y></y><!-- comment --></r>`,
+		want: DocStats{
+			Elements:   map[string]int{"r": 1, "{urn:p}x": 1, "y": 2, "z": 1},
+			Attributes: map[string]int{"a": 1, "{urn:p}b": 1},
+			MaxDepth:   4,
+			TextBytes:  3,
+			Namespaces: map[string]int{"urn:p": 2},
+		},
+	}, {
+		desc: "normalized document",
+		n:    Normalizer{OmitWhitespace: true, IgnoreElements: []xml.Name{{Local: "y"}}},
+		in:   "<r>\n  <y><z/></y>\n</r>",
+		want: DocStats{
+			Elements:   map[string]int{"r": 1},
+			Attributes: map[string]int{},
+			MaxDepth:   1,
+			Namespaces: map[string]int{},
+		},
+	}, {
+		desc:    "malformed",
+		in:      `<r>`,
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		got, err := tc.n.Stats(strings.NewReader(tc.in))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.desc, got, tc.want)
+		}
+	}
+}
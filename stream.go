@@ -0,0 +1,136 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// normalizingWriter is the io.WriteCloser of NewNormalizingWriter.
+type normalizingWriter struct {
+	pw     *io.PipeWriter
+	done   chan error
+	closed bool
+	err    error
+}
+
+// NewNormalizingWriter returns a writer that normalizes the XML written to
+// it as it arrives and writes the normalized XML content to w, so that an
+// XML producer under test can write to the Normalizer directly. Only
+// tokens are buffered, unless elements are rearranged. Writes fail with
+// the error of normalization, such as a *SyntaxError, once it fails.
+// Close must be called to complete the output, and returns the error of
+// normalization, if any.
+func (n *Normalizer) NewNormalizingWriter(w io.Writer) io.WriteCloser {
+	pr, pw := io.Pipe()
+	nw := &normalizingWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := n.Normalize(w, pr)
+		// Fail pending and further writes.
+		pr.CloseWithError(err)
+		nw.done <- err
+	}()
+	return nw
+}
+
+func (nw *normalizingWriter) Write(p []byte) (int, error) {
+	return nw.pw.Write(p)
+}
+
+func (nw *normalizingWriter) Close() error {
+	if !nw.closed {
+		nw.closed = true
+		nw.pw.Close()
+		nw.err = <-nw.done
+	}
+	return nw.err
+}
+
+// normalizingReader is the io.Reader of NewNormalizingReader.
+type normalizingReader struct {
+	n   *Normalizer
+	r   io.Reader
+	buf bytes.Buffer
+	// bw and out write to buf once the input is opened, and release
+	// releases the pooled decoder and TokenNormalizer.
+	bw      *bufio.Writer
+	out     *output
+	release func()
+	// limit truncates the output if MaxOutputBytes is set.
+	limit *truncatingWriter
+	err   error
+}
+
+// NewNormalizingReader returns a reader of the normalized XML content of
+// r, which normalizes r lazily as its output is read, so that the
+// Normalizer can be used in pipelines that consume readers. Only tokens
+// are buffered, unless elements are rearranged or CheckIdempotent is set.
+// Normalization errors are returned by Read once the output that precedes
+// them is read.
+func (n *Normalizer) NewNormalizingReader(r io.Reader) io.Reader {
+	return &normalizingReader{n: n, r: r}
+}
+
+func (nr *normalizingReader) Read(p []byte) (int, error) {
+	for nr.buf.Len() == 0 && nr.err == nil {
+		nr.fill()
+	}
+	if nr.buf.Len() > 0 {
+		return nr.buf.Read(p)
+	}
+	return 0, nr.err
+}
+
+// fill adds the next output to the buffer, or sets the error at the end of
+// the output.
+func (nr *normalizingReader) fill() {
+	if nr.out == nil {
+		if nr.n.CheckIdempotent {
+			// Both passes need the whole document.
+			nr.err = nr.n.Normalize(&nr.buf, nr.r)
+			if nr.err == nil {
+				nr.err = io.EOF
+			}
+			return
+		}
+		r, err := nr.n.decompress(nr.r)
+		if err != nil {
+			nr.err = err
+			return
+		}
+		d, releaseDecoder := nr.n.newDecoder(r)
+		tn := nr.n.getTokenNormalizer(d)
+		nr.release = func() {
+			putTokenNormalizer(tn)
+			releaseDecoder()
+		}
+		var w io.Writer = &nr.buf
+		if nr.n.MaxOutputBytes > 0 {
+			nr.limit = &truncatingWriter{w: w, left: nr.n.MaxOutputBytes}
+			w = nr.limit
+		}
+		ew := &errWriter{w: w}
+		nr.bw = bufio.NewWriter(ew)
+		nr.out = newOutput(tn, newTokenWriter(nr.bw, nr.n.Format), ew)
+	}
+	err := nr.out.next()
+	if err == io.EOF {
+		err = nr.out.close()
+		if err == nil {
+			err = io.EOF
+		}
+	} else if err == nil {
+		err = nr.bw.Flush()
+	}
+	if nr.limit != nil && nr.limit.truncated {
+		err = io.EOF
+	}
+	if err != nil {
+		nr.err = err
+		nr.release()
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNormalizingWriter(t *testing.T) {
+	var b strings.Builder
+	w := New(OmitWhitespace()).NewNormalizingWriter(&b)
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	v := struct {
+		XMLName xml.Name `xml:"order"`
+		ID      string   `xml:"id,attr"`
+		Items   []string `xml:"item"`
+	}{ID: "1", Items: []string{"a", "b"}}
+	if err := e.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: got %v, want nil", err)
+	}
+	want := `<order id="1"><item>a</item><item>b</item></order>`
+	if got := b.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestNormalizingWriterErrors(t *testing.T) {
+	var b strings.Builder
+	w := new(Normalizer).NewNormalizingWriter(&b)
+	var serr *SyntaxError
+	if _, err := w.Write([]byte(`<a></b>`)); err != nil && !errors.As(err, &serr) {
+		t.Errorf("Write: got %v, want nil or *SyntaxError", err)
+	}
+	if err := w.Close(); !errors.As(err, &serr) {
+		t.Errorf("Close: got %v, want *SyntaxError", err)
+	}
+
+	w = New(WithRules(RulesV2)).NewNormalizingWriter(&b)
+	w.Write([]byte(`<a/><b/>`))
+	if err := w.Close(); !errors.As(err, &serr) {
+		t.Errorf("Close: got %v, want *SyntaxError", err)
+	}
+}
+
+func TestNormalizingReader(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		n       Normalizer
+		in      string
+		want    string
+		wantErr bool
+	}{{
+		desc: "normalized",
+		in:   `<a  y="2" x="1"><b/></a>`,
+		want: `<a x="1" y="2"><b></b></a>`,
+	}, {
+		desc: "rearranged",
+		n:    Normalizer{SortElements: true, Format: Format{FinalNewline: true}},
+		in:   `<a><c/><b/></a>`,
+		want: "<a><b></b><c></c></a>\n",
+	}, {
+		desc: "checked",
+		n:    Normalizer{CheckIdempotent: true},
+		in:   `<a/>`,
+		want: `<a></a>`,
+	}, {
+		desc:    "malformed",
+		in:      `<a><b></a>`,
+		want:    `<a><b`,
+		wantErr: true,
+	}, {
+		desc: "empty",
+	}}
+
+	for _, tc := range testCases {
+		got, err := io.ReadAll(tc.n.NewNormalizingReader(strings.NewReader(tc.in)))
+		var serr *SyntaxError
+		if tc.wantErr != errors.As(err, &serr) || !tc.wantErr && err != nil {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizingReaderIsLazy(t *testing.T) {
+	// The reader fails the test if read beyond the first element.
+	r := io.MultiReader(strings.NewReader(`<a><b/>`), iotest.ErrReader(errors.New("read too far")))
+	nr := new(Normalizer).NewNormalizingReader(r)
+	buf := make([]byte, 5)
+	if n, err := io.ReadFull(nr, buf); err != nil || string(buf[:n]) != "<a><b" {
+		t.Errorf("got %q, %v, want %q, nil", buf[:n], err, "<a><b")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// templateFuncs are the functions available to templates of
+// NormalizeTemplate.
+var templateFuncs = template.FuncMap{
+	"xml": xmlEscape,
+}
+
+// NormalizeTemplate executes the text/template tmpl with data and writes
+// the normalized XML content of the result to w, so that expected
+// documents can be parameterized by test case values. Values are inserted
+// verbatim; the template function xml escapes a value for use in
+// character data or attribute values, as in {{xml .Name}}.
+func (n *Normalizer) NormalizeTemplate(w io.Writer, tmpl string, data interface{}) error {
+	t, err := template.New("xmltest").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := t.Execute(buf, data); err != nil {
+		return err
+	}
+	return n.Normalize(w, buf)
+}
+
+// xmlEscape returns the XML escaped text of v.
+func xmlEscape(v interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := xml.EscapeText(&b, []byte(fmt.Sprint(v))); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTemplate(t *testing.T) {
+	type item struct {
+		Name  string
+		Count int
+	}
+	testCases := []struct {
+		desc    string
+		tmpl    string
+		data    interface{}
+		want    string
+		wantErr bool
+	}{{
+		desc: "values",
+		tmpl: `<order   id="{{.Name}}">{{range .Items}}<item n="{{.Count}}">{{.Name}}</item>{{end}}</order>`,
+		data: map[string]interface{}{
+			"Name":  "o1",
+			"Items": []item{{"a", 1}, {"b", 2}},
+		},
+		want: `<order id="o1"><item n="1">a</item><item n="2">b</item></order>`,
+	}, {
+		desc: "escaped values",
+		tmpl: `<p title="{{xml .}}">{{xml .}}</p>`,
+		data: `"<&>"`,
+		want: `<p title="&quot;&lt;&amp;>&quot;">"&lt;&amp;&gt;"</p>`,
+	}, {
+		desc:    "invalid template",
+		tmpl:    `<p>{{.</p>`,
+		wantErr: true,
+	}, {
+		desc:    "failing template",
+		tmpl:    `<p>{{.Missing.Field}}</p>`,
+		data:    struct{}{},
+		wantErr: true,
+	}, {
+		desc:    "malformed result",
+		tmpl:    `<p>{{.}}`,
+		data:    "x",
+		wantErr: true,
+	}}
+
+	var n Normalizer
+	for _, tc := range testCases {
+		var b strings.Builder
+		err := n.NormalizeTemplate(&b, tc.tmpl, tc.data)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	inputSuffix    = ".input.xml"
+	expectedSuffix = ".expected.xml"
+)
+
+// RunTestdata runs a subtest for each pair of files foo.input.xml and
+// foo.expected.xml in dir, named foo. The subtest passes fn the content of
+// the input file and compares its result with the content of the expected
+// file, as normalized by n. A nil fn passes the input through unchanged,
+// and a nil n is the zero Normalizer. RunTestdata fails if dir contains no
+// input files.
+func RunTestdata(t *testing.T, dir string, n *Normalizer, fn func(input []byte) ([]byte, error)) {
+	t.Helper()
+	names, err := testdataNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatalf("xmltest: no %s files in %s", inputSuffix, dir)
+	}
+	if n == nil {
+		n = new(Normalizer)
+	}
+	for _, name := range names {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			if err := n.checkTestdata(dir, name, fn); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// testdataNames returns the sorted names of the input files in dir.
+func testdataNames(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+inputSuffix))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(f), inputSuffix))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (n *Normalizer) checkTestdata(dir, name string, fn func([]byte) ([]byte, error)) error {
+	input, err := os.ReadFile(filepath.Join(dir, name+inputSuffix))
+	if err != nil {
+		return err
+	}
+	expected, err := os.ReadFile(filepath.Join(dir, name+expectedSuffix))
+	if err != nil {
+		return err
+	}
+	got := input
+	if fn != nil {
+		if got, err = fn(input); err != nil {
+			return fmt.Errorf("xmltest: %s: %v", name, err)
+		}
+	}
+	return n.differenceError("output differs from "+name+expectedSuffix+" (a: output, b: expected)", got, expected)
+}
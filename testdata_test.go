@@ -0,0 +1,48 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRunTestdata(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"attrs.input.xml":    `<root b="2" a="1"/>`,
+		"attrs.expected.xml": `<root a="1" b="2"></root>`,
+		"upper.input.xml":    `<root>x</root>`,
+		"upper.expected.xml": `<root>X</root>`,
+		"other.xml":          `<ignored/>`,
+	})
+	RunTestdata(t, dir, nil, func(in []byte) ([]byte, error) {
+		return bytes.Replace(in, []byte(">x<"), []byte(">X<"), -1), nil
+	})
+}
+
+func TestCheckTestdata(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"differ.input.xml":    `<root>x</root>`,
+		"differ.expected.xml": `<root>y</root>`,
+		"missing.input.xml":   `<root/>`,
+	})
+	names, err := testdataNames(dir)
+	if err != nil || len(names) != 2 || names[0] != "differ" || names[1] != "missing" {
+		t.Fatalf("got names %q, %v, want [differ missing]", names, err)
+	}
+	var n Normalizer
+	want := "xmltest: output differs from differ.expected.xml (a: output, b: expected):\n" +
+		"\t/root/text(): \"x\" != \"y\"\n" +
+		"tree diff (-a +b):\n" +
+		"\t  element root\n" +
+		"\t-   text \"x\"\n" +
+		"\t+   text \"y\""
+	if err := n.checkTestdata(dir, "differ", nil); err == nil || err.Error() != want {
+		t.Errorf("differ: got err %v, want %q", err, want)
+	}
+	if err := n.checkTestdata(dir, "missing", nil); err == nil {
+		t.Errorf("missing: got nil error, want some")
+	}
+}
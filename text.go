@@ -0,0 +1,82 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// ColorMode configures whether DiffText colors its output.
+type ColorMode int
+
+const (
+	// ColorAuto colors the output if it is written to a terminal and the
+	// NO_COLOR environment variable is not set.
+	ColorAuto ColorMode = iota
+	// ColorNever never colors the output.
+	ColorNever
+	// ColorAlways always colors the output.
+	ColorAlways
+)
+
+// ANSI escape sequences for colored output.
+const (
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// DiffText writes the differences between the normalized XML contents of
+// a and b to w, one per line. Lines of content only in a start with "-",
+// content only in b with "+", and changed content with "~". If colored,
+// these lines are red, green and yellow, respectively.
+func (n *Normalizer) DiffText(w io.Writer, a, b io.Reader) error {
+	diffs, err := n.Diff(a, b)
+	if err != nil {
+		return err
+	}
+	color := useColor(n.Color, w)
+	bw := bufio.NewWriter(w)
+	for _, d := range diffs {
+		prefix, ansi := "~ ", ansiYellow
+		switch {
+		case d.A == "":
+			prefix, ansi = "+ ", ansiGreen
+		case d.B == "":
+			prefix, ansi = "- ", ansiRed
+		}
+		if color {
+			bw.WriteString(ansi)
+		}
+		bw.WriteString(prefix + d.String())
+		if color {
+			bw.WriteString(ansiReset)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// useColor reports whether output to w is colored in mode m.
+func useColor(m ColorMode, w io.Writer) bool {
+	switch m {
+	case ColorNever:
+		return false
+	case ColorAlways:
+		return true
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
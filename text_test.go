@@ -0,0 +1,44 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffText(t *testing.T) {
+	a := `<root a="1" b="2"><foo/></root>`
+	b := `<root a="2"><foo/><bar/></root>`
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		want string
+	}{{
+		desc: "automatic color is off for non-terminals",
+		want: "" +
+			"~ /root/@a: \"1\" != \"2\"\n" +
+			"- /root/@b: only in a: \"2\"\n" +
+			"+ /root/bar: only in b: <bar>\n",
+	}, {
+		desc: "color",
+		n:    Normalizer{Color: ColorAlways},
+		want: "" +
+			"\x1b[33m~ /root/@a: \"1\" != \"2\"\x1b[0m\n" +
+			"\x1b[31m- /root/@b: only in a: \"2\"\x1b[0m\n" +
+			"\x1b[32m+ /root/bar: only in b: <bar>\x1b[0m\n",
+	}}
+
+	for _, tc := range testCases {
+		var buf strings.Builder
+		if err := tc.n.DiffText(&buf, strings.NewReader(a), strings.NewReader(b)); err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got, tc.want)
+		}
+	}
+}
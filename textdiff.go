@@ -0,0 +1,160 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// lineOp is an edit operation of a line diff.
+type lineOp struct {
+	// kind is one of ' ' for equal lines, '-' for lines only in a, and '+'
+	// for lines only in b.
+	kind byte
+	line string
+}
+
+// diffLines returns the edit script that transforms the lines a into b,
+// based on their longest common subsequence. It uses Myers's linear space
+// refinement, so large inputs need memory proportional to their length.
+func diffLines(a, b []string) []lineOp {
+	ops := appendDiff(nil, a, b)
+	// Within each run of changes, list the lines of a before those of b.
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].kind != ' ' {
+			j++
+		}
+		sort.SliceStable(ops[i:j], func(x, y int) bool {
+			return ops[i+x].kind == '-' && ops[i+y].kind == '+'
+		})
+		i = j
+	}
+	return ops
+}
+
+// appendDiff appends the edit script of a and b to ops. After common
+// prefixes and suffixes are removed, the middle snake of an optimal path
+// splits the remaining lines into two halves with fewer differences.
+func appendDiff(ops []lineOp, a, b []string) []lineOp {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, lineOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	var suffix int
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	tail := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+	switch {
+	case len(a) == 0:
+		for _, l := range b {
+			ops = append(ops, lineOp{'+', l})
+		}
+	case len(b) == 0:
+		for _, l := range a {
+			ops = append(ops, lineOp{'-', l})
+		}
+	default:
+		x0, y0, x1, y1 := middleSnake(a, b)
+		ops = appendDiff(ops, a[:x0], b[:y0])
+		for _, l := range a[x0:x1] {
+			ops = append(ops, lineOp{' ', l})
+		}
+		ops = appendDiff(ops, a[x1:], b[y1:])
+	}
+	for _, l := range tail {
+		ops = append(ops, lineOp{' ', l})
+	}
+	return ops
+}
+
+// middleSnake returns the start (x0, y0) and end (x1, y1) of the middle
+// snake of an optimal path from the start of a and b to their ends, found by
+// searching forward from the start and backward from the ends until the
+// searches overlap. a and b must differ in their first and last lines.
+func middleSnake(a, b []string) (x0, y0, x1, y1 int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	max := (n + m + 1) / 2
+	// fwd[off+k] is the furthest x reached on diagonal x-y = k by the
+	// forward search; bwd[off+k] the furthest distance from the ends
+	// reached on the diagonal k of the reversed lines by the backward search.
+	off := max + 1
+	fwd := make([]int, 2*max+3)
+	bwd := make([]int, 2*max+3)
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && fwd[off+k-1] < fwd[off+k+1] {
+				x = fwd[off+k+1]
+			} else {
+				x = fwd[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			fwd[off+k] = x
+			if c := delta - k; odd && c >= -(d-1) && c <= d-1 && x+bwd[off+c] >= n {
+				return sx, sy, x, y
+			}
+		}
+		for c := -d; c <= d; c += 2 {
+			var x int
+			if c == -d || c != d && bwd[off+c-1] < bwd[off+c+1] {
+				x = bwd[off+c+1]
+			} else {
+				x = bwd[off+c-1] + 1
+			}
+			y := x - c
+			sx, sy := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x, y = x+1, y+1
+			}
+			bwd[off+c] = x
+			if k := delta - c; !odd && k >= -d && k <= d && x+fwd[off+k] >= n {
+				return n - x, m - y, n - sx, m - sy
+			}
+		}
+	}
+	panic("xmltest: no middle snake")
+}
+
+// indentedLines returns the normalized XML content of r, indented with
+// one element per line and prefixes assigned from pt. The indentation may
+// change character data, so the result is only meant for display.
+func (n *Normalizer) indentedLines(r io.Reader, pt *prefixTable) ([]string, error) {
+	toks, err := n.readTokens(r)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	bw := bufio.NewWriter(&b)
+	tw := newTokenWriter(bw, Format{Indent: "  "})
+	tw.prefixes = pt
+	for _, t := range toks {
+		if err := tw.writeToken(t); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.close(); err != nil {
+		return nil, err
+	}
+	if b.Len() == 0 {
+		return nil, nil
+	}
+	return strings.Split(b.String(), "\n"), nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "c", "d", "e"}
+	want := []lineOp{{' ', "a"}, {'-', "b"}, {'+', "x"}, {' ', "c"}, {' ', "d"}, {'+', "e"}}
+	if got := diffLines(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %q\nwant %q", got, want)
+	}
+}
+
+// lcsLen returns the length of the longest common subsequence of a and b.
+func lcsLen(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLinesMinimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		l := make([]string, rnd.Intn(12))
+		if len(l) == 0 {
+			return nil
+		}
+		for i := range l {
+			l[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return l
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := lines(), lines()
+		var gotA, gotB []string
+		var equal int
+		for _, op := range diffLines(a, b) {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind == ' ' {
+				equal++
+			}
+		}
+		if !reflect.DeepEqual(gotA, a) || !reflect.DeepEqual(gotB, b) {
+			t.Fatalf("%q, %q: script does not transform a into b", a, b)
+		}
+		if want := lcsLen(a, b); equal != want {
+			t.Fatalf("%q, %q: got %d equal lines, want %d", a, b, equal, want)
+		}
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	// A quadratic table of these lines would need gigabytes.
+	const size = 100000
+	a := make([]string, size)
+	b := make([]string, size)
+	for i := range a {
+		a[i] = strconv.Itoa(i)
+		b[i] = a[i]
+		if i%1000 == 0 {
+			b[i] = "x"
+		}
+	}
+	var changed int
+	for _, op := range diffLines(a, b) {
+		if op.kind != ' ' {
+			changed++
+		}
+	}
+	if want := 2 * size / 1000; changed != want {
+		t.Errorf("got %d changed lines, want %d", changed, want)
+	}
+}
@@ -0,0 +1,601 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TokenNormalizer reads XML tokens from an underlying xml.TokenReader and
+// returns their normalized form. It implements xml.TokenReader, so it can
+// be composed with other token processing pipelines, for example by
+// passing it to xml.NewTokenDecoder.
+//
+// Adjacent character data is returned as a single xml.CharData token.
+// Returned tokens are copies and remain valid after subsequent calls to
+// Token.
+type TokenNormalizer struct {
+	n *Normalizer
+	d *xml.Decoder
+	// queue holds the tokens to return from index head on.
+	queue []xml.Token
+	head  int
+	// text buffers character data. Its storage is reused after a flush.
+	text []byte
+	err  error
+	// path holds the normalized names of the open elements.
+	path []xml.Name
+	// skip counts the open elements of a subtree that is dropped.
+	skip int
+	// tree buffers the tokens of a top-level element if the Normalizer
+	// rearranges elements, and depth counts its open elements.
+	tree  []xml.Token
+	depth int
+	// open counts the open elements of the input, and roots its root
+	// elements.
+	open, roots int
+	// unwrapped counts the open elements of the input that are unwrapped.
+	unwrapped int
+	// ns holds the namespace declarations of the open elements of the
+	// input, if QName attributes are resolved.
+	ns []map[string]string
+	// bases holds the base URIs in scope of the open elements of the
+	// input, if URI attributes are resolved.
+	bases []string
+	// expects holds the compiled ExpectTexts, once compiled, and texts the
+	// character data of the open elements if they are checked.
+	expects  []expectation
+	compiled bool
+	texts    [][]byte
+	// decimals and base64s hold the compiled DecimalCommaPaths and
+	// Base64Paths.
+	decimals []pathPattern
+	base64s  []pathPattern
+	// types holds the schema types of the open elements, if the Normalizer
+	// has a Schema.
+	types []*contentType
+	// redact counts the open elements of a subtree whose content is
+	// redacted.
+	redact int
+	// standalone holds the standalone parameter of the XML declaration of
+	// the input.
+	standalone string
+}
+
+var (
+	errMultipleRoots = errors.New("multiple root elements")
+	errTopLevelText  = errors.New("character data outside of root element")
+)
+
+// NewTokenNormalizer returns a TokenNormalizer that normalizes the tokens
+// of tr according to the rules of n. The tokens of tr may carry either
+// namespace prefixes or namespace URIs in their names.
+func (n *Normalizer) NewTokenNormalizer(tr xml.TokenReader) *TokenNormalizer {
+	return &TokenNormalizer{n: n, d: xml.NewTokenDecoder(tr)}
+}
+
+// Token returns the next normalized token. At the end of the input it
+// returns nil, io.EOF.
+func (tn *TokenNormalizer) Token() (xml.Token, error) {
+	for tn.head == len(tn.queue) {
+		if tn.err != nil {
+			return nil, tn.err
+		}
+		tn.queue, tn.head = tn.queue[:0], 0
+		tn.read()
+	}
+	t := tn.queue[tn.head]
+	tn.queue[tn.head] = nil
+	tn.head++
+	return t, nil
+}
+
+// read reads the next token from the underlying reader and queues its
+// normalized form, if any. Character data is buffered until a token that
+// is not dropped by normalization follows it.
+func (tn *TokenNormalizer) read() {
+	if !tn.compiled {
+		tn.compiled = true
+		if len(tn.n.Expect) > 0 {
+			if tn.expects, tn.err = tn.n.compileExpect(); tn.err != nil {
+				return
+			}
+		}
+		if len(tn.n.DecimalCommaPaths) > 0 {
+			if tn.decimals, tn.err = compilePaths(tn.n.DecimalCommaPaths); tn.err != nil {
+				return
+			}
+		}
+		if len(tn.n.Base64Paths) > 0 {
+			if tn.base64s, tn.err = compilePaths(tn.n.Base64Paths); tn.err != nil {
+				return
+			}
+		}
+	}
+	t, err := tn.d.Token()
+	if err != nil {
+		if tn.open > 0 && tn.open == tn.unwrapped && isUnexpectedEOF(err) {
+			// Unwrapped elements need not be closed.
+			err = io.EOF
+		}
+		tn.flushText()
+		var serr *xml.SyntaxError
+		if errors.As(err, &serr) {
+			err = tn.syntaxError(err)
+		}
+		tn.err = err
+		return
+	}
+	switch t := t.(type) {
+	case xml.StartElement:
+		if tn.n.MaxDepth > 0 && tn.open >= tn.n.MaxDepth {
+			tn.flushText()
+			tn.err = &LimitError{Limit: "MaxDepth", Max: tn.n.MaxDepth}
+			return
+		}
+		if len(tn.n.QNameAttrs) > 0 {
+			tn.ns = append(tn.ns, namespaceDecls(t))
+		}
+		if len(tn.n.ResolveURIAttrs) > 0 {
+			tn.pushBase(t)
+		}
+		if tn.open == 0 {
+			tn.roots++
+			if tn.roots > 1 && !tn.n.AllowMultipleRoots && !tn.n.Fragment && tn.n.rules() >= RulesV2 {
+				tn.flushText()
+				tn.err = tn.syntaxError(errMultipleRoots)
+				return
+			}
+		}
+		tn.open++
+		if matchName(tn.n.Unwrap, t.Name) {
+			tn.unwrapped++
+		}
+	case xml.EndElement:
+		if len(tn.n.QNameAttrs) > 0 {
+			tn.ns = tn.ns[:len(tn.ns)-1]
+		}
+		if len(tn.n.ResolveURIAttrs) > 0 {
+			tn.bases = tn.bases[:len(tn.bases)-1]
+		}
+		tn.open--
+		if matchName(tn.n.Unwrap, t.Name) {
+			tn.unwrapped--
+		}
+	case xml.CharData:
+		if tn.open == 0 && !tn.n.Fragment && tn.n.rules() >= RulesV2 {
+			if len(bytes.Trim(t, " \t\r\n\ufeff")) > 0 {
+				tn.flushText()
+				tn.err = tn.syntaxError(errTopLevelText)
+				return
+			}
+			tn.trace(tn.path, t, nil)
+			return
+		}
+	}
+	if t, ok := t.(xml.CharData); ok {
+		switch {
+		case tn.redact > 0:
+			// Redacted content is not traced.
+		case tn.skip == 0:
+			tn.text = append(tn.text, t...)
+		default:
+			tn.trace(tn.path, t, nil)
+		}
+		return
+	}
+	path := tn.path
+	redacted := tn.redact > 0
+	nt := tn.normalize(t)
+	if _, ok := t.(xml.EndElement); ok {
+		path = tn.path
+	}
+	if !redacted || tn.redact == 0 {
+		tn.trace(path, tn.n.redactAttrs(t), nt)
+	}
+	if nt != nil {
+		tn.flushText()
+		tn.emit(nt)
+		if _, ok := nt.(xml.StartElement); ok && tn.redact == 1 {
+			tn.text = append(tn.text, tn.n.redaction()...)
+		}
+	}
+}
+
+// emit queues the normalized token t. If elements are rearranged, the
+// tokens of each top-level element are buffered until its end.
+func (tn *TokenNormalizer) emit(t xml.Token) {
+	if !tn.n.rearranges() {
+		tn.queue = append(tn.queue, t)
+		return
+	}
+	tn.tree = append(tn.tree, t)
+	switch t.(type) {
+	case xml.StartElement:
+		tn.depth++
+	case xml.EndElement:
+		tn.depth--
+	}
+	if tn.depth == 0 {
+		nodes := buildTree(tn.tree)
+		tn.tree = tn.tree[:0]
+		tn.n.arrange(nodes)
+		for _, nd := range nodes {
+			tn.queue = nd.appendTokens(tn.queue)
+		}
+	}
+}
+
+// flushText queues the buffered character data.
+func (tn *TokenNormalizer) flushText() {
+	if len(tn.text) == 0 {
+		return
+	}
+	text := tn.text
+	tn.text = tn.text[:0]
+	ct := tn.schemaType()
+	if tn.n.StructureOnly || (tn.n.OmitWhitespace || ct != nil && ct.elementOnly) && len(bytes.TrimSpace(text)) == 0 {
+		if tn.n.Trace != nil {
+			tn.trace(tn.path, xml.CharData(text), nil)
+		}
+		return
+	}
+	// Copy the text out of the reused buffer before it is handed out, and
+	// convert it to a token only once.
+	text = append(make([]byte, 0, len(text)), text...)
+	in := xml.Token(xml.CharData(text))
+	t := in
+	if ct != nil && ct.space != spacePreserve {
+		if text = []byte(ct.space.apply(string(text))); len(text) == 0 {
+			tn.trace(tn.path, in, nil)
+			return
+		}
+		t = xml.CharData(text)
+	}
+	if matchText(tn.base64s, tn.path) {
+		if b, ok := tn.n.canonicalBase64(string(text)); ok {
+			text = []byte(b)
+			t = xml.CharData(text)
+		}
+	}
+	if matchText(tn.decimals, tn.path) {
+		if d, ok := pointDecimal(string(text)); ok {
+			text = []byte(d)
+			t = xml.CharData(text)
+		}
+	}
+	if tn.n.NormalizeBooleans {
+		if b, ok := canonicalBoolean(string(text)); ok {
+			text = []byte(b)
+			t = xml.CharData(text)
+		}
+	}
+	if tn.n.TextTransform != nil {
+		t = xml.CharData(tn.n.TextTransform(tn.path, string(text)))
+	}
+	t, ok := tn.filter(t)
+	if !ok {
+		t = nil
+	}
+	tn.trace(tn.path, in, t)
+	if ok {
+		if len(tn.texts) > 0 {
+			top := len(tn.texts) - 1
+			tn.texts[top] = append(tn.texts[top], t.(xml.CharData)...)
+		}
+		tn.emit(t)
+	}
+}
+
+// normalize returns the normalized copy of t, or nil if t is dropped.
+func (tn *TokenNormalizer) normalize(t xml.Token) xml.Token {
+	if tn.skip > 0 {
+		switch t.(type) {
+		case xml.StartElement:
+			tn.skip++
+		case xml.EndElement:
+			tn.skip--
+		}
+		return nil
+	}
+	if tn.redact > 0 {
+		switch t.(type) {
+		case xml.StartElement:
+			tn.redact++
+			return nil
+		case xml.EndElement:
+			if tn.redact--; tn.redact > 0 {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	switch val := t.(type) {
+	case xml.Directive:
+		return nil
+	case xml.ProcInst:
+		if val.Target == "xml" {
+			tn.standalone = procInstParam(string(val.Inst), "standalone")
+		}
+		return nil
+	case xml.Comment:
+		if tn.n.OmitComments || tn.n.StructureOnly {
+			return nil
+		}
+	case xml.StartElement:
+		if matchName(tn.n.Unwrap, val.Name) {
+			return nil
+		}
+		if matchName(tn.n.IgnoreElements, val.Name) || tn.n.omitNamespace(val.Name.Space) {
+			tn.skip = 1
+			if tn.n.MarkIgnored && matchName(tn.n.IgnoreElements, val.Name) {
+				tn.flushText()
+				name := xml.Name{Space: Namespace, Local: "ignored"}
+				tn.emit(xml.StartElement{Name: name, Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: clarkName(val.Name)}}})
+				tn.emit(xml.EndElement{Name: name})
+			}
+			return nil
+		}
+		var ct *contentType
+		if tn.n.Schema != nil {
+			ct = tn.n.Schema.child(tn.schemaType(), val.Name.Local)
+			val.Attr = ct.applyAttrs(val.Attr)
+		}
+		// Build the attributes in a new slice rather than copying the token
+		// first, as most of them are kept.
+		start := xml.StartElement{Name: tn.n.rename(val.Name)}
+		if tn.n.CaseInsensitiveNames {
+			start.Name.Local = strings.ToLower(start.Name.Local)
+		}
+		attr := make([]xml.Attr, 0, len(val.Attr))
+		for _, a := range val.Attr {
+			if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" || matchName(tn.n.IgnoreAttrs, a.Name) || tn.n.omitNamespace(a.Name.Space) {
+				continue
+			}
+			if matchName(tn.n.RedactAttrs, a.Name) {
+				a.Value = tn.n.redaction()
+			}
+			if len(tn.n.ResolveURIAttrs) > 0 {
+				if isBaseAttr(a.Name) {
+					continue
+				}
+				if matchName(tn.n.ResolveURIAttrs, a.Name) {
+					a.Value = resolveURI(tn.base(), a.Value)
+				}
+			}
+			if matchName(tn.n.CollapseAttrs, a.Name) {
+				a.Value = strings.Join(strings.Fields(a.Value), " ")
+			}
+			if matchName(tn.n.QNameAttrs, a.Name) {
+				a.Value = tn.resolveQName(a.Value)
+			}
+			if (tn.n.NormalizeLang || tn.n.IgnoreLangSubtags) && isLangAttr(a.Name) {
+				a.Value = canonicalLang(a.Value, tn.n.IgnoreLangSubtags)
+			}
+			a.Name = tn.n.rename(a.Name)
+			if tn.n.CaseInsensitiveNames {
+				a.Name.Local = strings.ToLower(a.Name.Local)
+			}
+			if tn.n.NormalizeBooleans {
+				if b, ok := canonicalBoolean(a.Value); ok {
+					a.Value = b
+				}
+			}
+			if tn.n.AttrTransform != nil {
+				a = tn.n.AttrTransform(start.Name, a)
+			}
+			if tn.n.StructureOnly {
+				a.Value = ""
+			}
+			attr = append(attr, a)
+		}
+		start.Attr = attr
+		t, ok := tn.filter(start)
+		if !ok {
+			tn.skip = 1
+			return nil
+		}
+		// Sorting start.Attr in place also sorts the attributes of t.
+		start = t.(xml.StartElement)
+		if !tn.n.KeepAttrOrder {
+			sortAttrs(start.Attr, tn.n.LessAttr)
+		}
+		tn.flushText()
+		tn.path = append(tn.path, start.Name)
+		rewriteAttrs(tn.decimals, tn.path, start.Attr, pointDecimal)
+		rewriteAttrs(tn.base64s, tn.path, start.Attr, tn.n.canonicalBase64)
+		if tn.n.Schema != nil {
+			tn.types = append(tn.types, ct)
+		}
+		if len(tn.expects) > 0 {
+			tn.texts = append(tn.texts, nil)
+			if err := tn.checkAttrs(start); err != nil && tn.err == nil {
+				tn.err = err
+			}
+		}
+		if matchName(tn.n.RedactElements, val.Name) {
+			tn.redact = 1
+		}
+		return t
+	case xml.EndElement:
+		if matchName(tn.n.Unwrap, val.Name) {
+			return nil
+		}
+		tn.flushText()
+		if len(tn.expects) > 0 {
+			if err := tn.checkText(); err != nil && tn.err == nil {
+				tn.err = err
+			}
+			tn.texts = tn.texts[:len(tn.texts)-1]
+		}
+		if tn.n.Schema != nil {
+			tn.types = tn.types[:len(tn.types)-1]
+		}
+		name := tn.path[len(tn.path)-1]
+		tn.path = tn.path[:len(tn.path)-1]
+		return xml.EndElement{Name: name}
+	}
+	t, ok := tn.filter(xml.CopyToken(t))
+	if !ok {
+		return nil
+	}
+	return t
+}
+
+// schemaType returns the schema type of the innermost open element, or nil
+// if there is none or it is not declared.
+func (tn *TokenNormalizer) schemaType() *contentType {
+	if len(tn.types) == 0 {
+		return nil
+	}
+	return tn.types[len(tn.types)-1]
+}
+
+// filter applies the token filters of the Normalizer to t.
+func (tn *TokenNormalizer) filter(t xml.Token) (xml.Token, bool) {
+	for _, f := range tn.n.Filters {
+		var ok bool
+		if t, ok = f(tn.path, t); !ok {
+			return nil, false
+		}
+	}
+	return t, true
+}
+
+// namespaceDecls returns the namespace declarations of start by prefix,
+// with the empty prefix for the default namespace.
+func namespaceDecls(start xml.StartElement) map[string]string {
+	var decls map[string]string
+	for _, a := range start.Attr {
+		prefix := ""
+		switch {
+		case a.Name.Space == "xmlns":
+			prefix = a.Name.Local
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+		default:
+			continue
+		}
+		if decls == nil {
+			decls = map[string]string{}
+		}
+		decls[prefix] = a.Value
+	}
+	return decls
+}
+
+// resolveQName returns the QName s in the form {uri}local, or s itself if
+// its prefix is not declared.
+func (tn *TokenNormalizer) resolveQName(s string) string {
+	s = strings.TrimSpace(s)
+	prefix, local := "", s
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		prefix, local = s[:i], s[i+1:]
+	}
+	for i := len(tn.ns) - 1; i >= 0; i-- {
+		if uri, ok := tn.ns[i][prefix]; ok {
+			return "{" + uri + "}" + local
+		}
+	}
+	if prefix == "" {
+		return local
+	}
+	return s
+}
+
+// omitNamespace reports whether names in namespace space are removed.
+func (n *Normalizer) omitNamespace(space string) bool {
+	for _, s := range n.OmitNamespaces {
+		if s == space {
+			return space != ""
+		}
+	}
+	return false
+}
+
+// rename returns the name that n.Rename maps name to, or name itself.
+func (n *Normalizer) rename(name xml.Name) xml.Name {
+	if to, ok := n.Rename[name]; ok {
+		return to
+	}
+	if to, ok := n.Rename[xml.Name{Local: name.Local}]; ok {
+		if to.Space == "" {
+			to.Space = name.Space
+		}
+		return to
+	}
+	return name
+}
+
+// matchName reports whether name matches one of names. A name with an
+// empty Space matches names in any namespace.
+func matchName(names []xml.Name, name xml.Name) bool {
+	for _, m := range names {
+		if m.Local == name.Local && (m.Space == "" || m.Space == name.Space) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnexpectedEOF reports whether err is the syntax error of a decoder
+// that reached the end of the input inside an element.
+func isUnexpectedEOF(err error) bool {
+	var serr *xml.SyntaxError
+	return errors.As(err, &serr) && serr.Msg == "unexpected EOF"
+}
+
+// canonicalBoolean returns the canonical form of s if it is a lexical
+// representation of an XSD boolean.
+func canonicalBoolean(s string) (string, bool) {
+	switch strings.TrimSpace(s) {
+	case "true", "1":
+		return "true", true
+	case "false", "0":
+		return "false", true
+	}
+	return "", false
+}
+
+// sortAttrs sorts attrs by less, if not nil, and else or where less
+// orders them neither way by name. Short lists, which are the common case,
+// are sorted in place by insertion without allocating.
+func sortAttrs(attrs []xml.Attr, less func(a, b xml.Attr) bool) {
+	s := attrSorter{attrs, less}
+	if len(attrs) > 12 {
+		sort.Sort(s)
+		return
+	}
+	for i := 1; i < len(attrs); i++ {
+		for j := i; j > 0 && s.Less(j, j-1); j-- {
+			s.Swap(j, j-1)
+		}
+	}
+}
+
+type attrSorter struct {
+	attrs []xml.Attr
+	less  func(a, b xml.Attr) bool
+}
+
+func (s attrSorter) Len() int      { return len(s.attrs) }
+func (s attrSorter) Swap(i, j int) { s.attrs[i], s.attrs[j] = s.attrs[j], s.attrs[i] }
+func (s attrSorter) Less(i, j int) bool {
+	a, b := s.attrs[i], s.attrs[j]
+	if s.less != nil {
+		if s.less(a, b) {
+			return true
+		}
+		if s.less(b, a) {
+			return false
+		}
+	}
+	return lessName(a.Name, b.Name)
+}
@@ -0,0 +1,84 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenNormalizer(t *testing.T) {
+	root := xml.Name{Local: "root"}
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+		want []xml.Token
+	}{{
+		desc: "sort attributes and drop namespace declarations",
+		in:   `<root xmlns:i="ignored" b="2" a="1"/>`,
+		want: []xml.Token{
+			xml.StartElement{Name: root, Attr: []xml.Attr{
+				{Name: xml.Name{Local: "a"}, Value: "1"},
+				{Name: xml.Name{Local: "b"}, Value: "2"},
+			}},
+			xml.EndElement{Name: root},
+		},
+	}, {
+		desc: "coalesce character data",
+		in:   `<root>a<![CDATA[b]]>c</root>`,
+		want: []xml.Token{
+			xml.StartElement{Name: root, Attr: []xml.Attr{}},
+			xml.CharData("abc"),
+			xml.EndElement{Name: root},
+		},
+	}, {
+		desc: "coalesce character data around omitted comments",
+		n:    Normalizer{OmitComments: true, OmitWhitespace: true},
+		in:   "<root> <!-- c --> <?pi?>\n</root>",
+		want: []xml.Token{
+			xml.StartElement{Name: root, Attr: []xml.Attr{}},
+			xml.EndElement{Name: root},
+		},
+	}}
+
+	for _, tc := range testCases {
+		tn := tc.n.NewTokenNormalizer(xml.NewDecoder(strings.NewReader(tc.in)))
+		var got []xml.Token
+		for {
+			tok, err := tn.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: got err %v, want nil", tc.desc, err)
+			}
+			got = append(got, tok)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\ngot  %#v\nwant %#v", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestTokenNormalizerCompose(t *testing.T) {
+	// A TokenNormalizer can be fed into another xml.Decoder.
+	var n Normalizer
+	tn := n.NewTokenNormalizer(xml.NewDecoder(strings.NewReader(`<root b="2" a="1"><foo/></root>`)))
+	var v struct {
+		A   string `xml:"a,attr"`
+		B   string `xml:"b,attr"`
+		Foo string `xml:"foo"`
+	}
+	if err := xml.NewTokenDecoder(tn).Decode(&v); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if v.A != "1" || v.B != "2" {
+		t.Errorf("got %+v, want A=1 B=2", v)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// trace writes the normalization decision for the input token in to the
+// trace writer of the Normalizer. The token out is its normalized form, or
+// nil if in was dropped.
+func (tn *TokenNormalizer) trace(path []xml.Name, in, out xml.Token) {
+	if tn.n.Trace == nil {
+		return
+	}
+	p := formatPath(path)
+	fin := formatToken(in)
+	switch {
+	case out == nil:
+		fmt.Fprintf(tn.n.Trace, "%s: dropped %s\n", p, fin)
+	case formatToken(out) != fin:
+		fmt.Fprintf(tn.n.Trace, "%s: rewritten %s to %s\n", p, fin, formatToken(out))
+	default:
+		fmt.Fprintf(tn.n.Trace, "%s: kept %s\n", p, fin)
+	}
+}
+
+// formatPath formats the element names of path in Clark notation.
+func formatPath(path []xml.Name) string {
+	if len(path) == 0 {
+		return "/"
+	}
+	var b strings.Builder
+	for _, name := range path {
+		b.WriteString("/" + clarkName(name))
+	}
+	return b.String()
+}
+
+// clarkName formats name in Clark notation.
+func clarkName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+// formatToken formats t for tracing.
+func formatToken(t xml.Token) string {
+	switch t := t.(type) {
+	case xml.StartElement:
+		var b strings.Builder
+		b.WriteString("<" + clarkName(t.Name))
+		for _, a := range t.Attr {
+			fmt.Fprintf(&b, " %s=%q", clarkName(a.Name), a.Value)
+		}
+		b.WriteString(">")
+		return b.String()
+	case xml.EndElement:
+		return "</" + clarkName(t.Name) + ">"
+	case xml.CharData:
+		return fmt.Sprintf("text %q", t)
+	case xml.Comment:
+		return "<!--" + string(t) + "-->"
+	case xml.ProcInst:
+		return "<?" + t.Target + " " + string(t.Inst) + "?>"
+	case xml.Directive:
+		return "<!" + string(t) + ">"
+	}
+	return fmt.Sprintf("%v", t)
+}
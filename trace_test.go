@@ -0,0 +1,33 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var b strings.Builder
+	n := Normalizer{OmitComments: true, OmitWhitespace: true, Trace: &b}
+	in := "<?pi x?><root b=\"2\" a=\"1\">\n  <!-- c --><a>x</a>\n</root>"
+	if err := n.Normalize(io.Discard, strings.NewReader(in)); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	want := `/: dropped <?pi x?>
+/: rewritten <root b="2" a="1"> to <root a="1" b="2">
+/root: dropped <!-- c -->
+/root: dropped text "\n  "
+/root: kept <a>
+/root/a: kept text "x"
+/root: kept </a>
+/root: dropped text "\n"
+/: kept </root>
+`
+	if got := b.String(); got != want {
+		t.Errorf("\ngot\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+)
+
+// node is a node of a normalized XML document tree. Its token is an
+// xml.StartElement for elements, or an xml.CharData or xml.Comment.
+type node struct {
+	tok      xml.Token
+	children []*node
+}
+
+// buildTree builds the document trees of the balanced token stream toks.
+func buildTree(toks []xml.Token) []*node {
+	var (
+		roots []*node
+		stack []*node
+	)
+	for _, t := range toks {
+		if _, ok := t.(xml.EndElement); ok {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		nd := &node{tok: t}
+		if len(stack) == 0 {
+			roots = append(roots, nd)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, nd)
+		}
+		if _, ok := t.(xml.StartElement); ok {
+			stack = append(stack, nd)
+		}
+	}
+	return roots
+}
+
+// appendTokens appends the token stream of nd to toks.
+func (nd *node) appendTokens(toks []xml.Token) []xml.Token {
+	toks = append(toks, nd.tok)
+	start, ok := nd.tok.(xml.StartElement)
+	if !ok {
+		return toks
+	}
+	for _, c := range nd.children {
+		toks = c.appendTokens(toks)
+	}
+	return append(toks, xml.EndElement{Name: start.Name})
+}
+
+// isElement reports whether nd is an element node.
+func (nd *node) isElement() bool {
+	_, ok := nd.tok.(xml.StartElement)
+	return ok
+}
+
+// hasElementChild reports whether nd has a child element.
+func hasElementChild(nd *node) bool {
+	for _, c := range nd.children {
+		if c.isElement() {
+			return true
+		}
+	}
+	return false
+}
+
+// name returns the element name of nd, or the zero name for other nodes.
+func (nd *node) name() xml.Name {
+	start, _ := nd.tok.(xml.StartElement)
+	return start.Name
+}
+
+// key returns a string that identifies the content of nd.
+func (nd *node) key() string {
+	var b strings.Builder
+	nd.writeKey(&b)
+	return b.String()
+}
+
+func (nd *node) writeKey(b *strings.Builder) {
+	switch t := nd.tok.(type) {
+	case xml.StartElement:
+		b.WriteString("<{" + t.Name.Space + "}" + t.Name.Local)
+		for _, a := range t.Attr {
+			b.WriteString(" {" + a.Name.Space + "}" + a.Name.Local + "=")
+			xml.EscapeText(b, []byte(a.Value))
+		}
+		b.WriteString(">")
+		for _, c := range nd.children {
+			c.writeKey(b)
+		}
+		b.WriteString("</>")
+	case xml.CharData:
+		xml.EscapeText(b, t)
+	case xml.Comment:
+		b.WriteString("<!--")
+		b.Write(t)
+		b.WriteString("-->")
+	}
+}
+
+// rearranges reports whether n changes the order of elements.
+func (n *Normalizer) rearranges() bool {
+	return n.SortElements || len(n.MatchChildren) > 0 || len(n.UnorderedUnder) > 0 || n.MixedContent != MixedContentExact
+}
+
+// arrange recursively brings the elements of nodes and their descendants
+// into the order configured by n.
+func (n *Normalizer) arrange(nodes []*node) {
+	if n.MixedContent != MixedContentExact {
+		n.mixContent(nodes)
+	}
+	if n.SortElements && len(n.OrderedUnder) == 0 {
+		sortElements(nodes)
+		return
+	}
+	n.arrangeChildren(nodes)
+	if n.SortElements {
+		sortSiblings(nodes)
+	}
+}
+
+// arrangeChildren recursively brings the children of the elements of nodes
+// into the order configured by n.
+func (n *Normalizer) arrangeChildren(nodes []*node) {
+	for _, nd := range nodes {
+		if !nd.isElement() {
+			continue
+		}
+		n.arrangeChildren(nd.children)
+		switch name := nd.name(); {
+		case matchName(n.OrderedUnder, name):
+		case n.SortElements || matchName(n.UnorderedUnder, name):
+			sortSiblings(nd.children)
+		default:
+			if m := n.matchChildren(name); m != nil {
+				m.sort(nd.children)
+			}
+		}
+	}
+}
+
+// MixedContent configures how character data in elements with child
+// elements is compared.
+type MixedContent int
+
+const (
+	// MixedContentExact keeps character data at its position between the
+	// child elements.
+	MixedContentExact MixedContent = iota
+	// MixedContentCollapse concatenates the character data and moves it
+	// before the child elements, so that only its content matters.
+	MixedContentCollapse
+	// MixedContentIgnore removes the character data.
+	MixedContentIgnore
+)
+
+// mixContent recursively applies n.MixedContent to the elements of nodes
+// and their descendants.
+func (n *Normalizer) mixContent(nodes []*node) {
+	for _, nd := range nodes {
+		if !nd.isElement() {
+			continue
+		}
+		n.mixContent(nd.children)
+		if !hasElementChild(nd) {
+			continue
+		}
+		var text []byte
+		kept := nd.children[:0]
+		for _, c := range nd.children {
+			if t, ok := c.tok.(xml.CharData); ok {
+				text = append(text, t...)
+			} else {
+				kept = append(kept, c)
+			}
+		}
+		if n.MixedContent == MixedContentCollapse && len(text) > 0 {
+			kept = append([]*node{{tok: xml.CharData(text)}}, kept...)
+		}
+		nd.children = kept
+	}
+}
+
+// sortElements recursively sorts the sibling elements of nodes and their
+// descendants. Other nodes keep their position.
+func sortElements(nodes []*node) {
+	for _, nd := range nodes {
+		sortElements(nd.children)
+	}
+	sortSiblings(nodes)
+}
+
+// sortSiblings sorts the elements of nodes by name and content. Other
+// nodes keep their position.
+func sortSiblings(nodes []*node) {
+	var (
+		slots []int
+		elems []*node
+		keys  = map[*node]string{}
+	)
+	for i, nd := range nodes {
+		if nd.isElement() {
+			slots = append(slots, i)
+			elems = append(elems, nd)
+			keys[nd] = nd.key()
+		}
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		a, b := elems[i].name(), elems[j].name()
+		if a.Space != b.Space {
+			return a.Space < b.Space
+		}
+		if a.Local != b.Local {
+			return a.Local < b.Local
+		}
+		return keys[elems[i]] < keys[elems[j]]
+	})
+	for i, slot := range slots {
+		nodes[slot] = elems[i]
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errTruncated stops normalization once the output is truncated.
+var errTruncated = errors.New("xmltest: output truncated")
+
+// truncatingWriter writes at most left bytes to w, followed by a
+// truncation marker, and then fails with errTruncated.
+type truncatingWriter struct {
+	w         io.Writer
+	left      int
+	written   int
+	truncated bool
+	// err is the error of writing the marker, if any.
+	err error
+}
+
+func (tw *truncatingWriter) Write(p []byte) (int, error) {
+	if tw.truncated {
+		return 0, errTruncated
+	}
+	if len(p) <= tw.left {
+		n, err := tw.w.Write(p)
+		tw.left -= n
+		tw.written += n
+		return n, err
+	}
+	n, err := tw.w.Write(p[:tw.left])
+	tw.written += n
+	if err != nil {
+		return n, err
+	}
+	tw.truncated = true
+	if _, err := fmt.Fprintf(tw.w, "\n... (truncated after %d bytes)\n", tw.written); err != nil {
+		tw.err = &WriteError{Err: err}
+	}
+	return n, errTruncated
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMaxOutputBytes(t *testing.T) {
+	testCases := []struct {
+		desc string
+		max  int
+		in   string
+		want string
+	}{{
+		desc: "truncated",
+		max:  10,
+		in:   `<a><b>text</b></a>`,
+		want: "<a><b>text\n... (truncated after 10 bytes)\n",
+	}, {
+		desc: "at limit",
+		max:  18,
+		in:   `<a><b>text</b></a>`,
+		want: `<a><b>text</b></a>`,
+	}, {
+		desc: "unlimited",
+		in:   `<a><b>text</b></a>`,
+		want: `<a><b>text</b></a>`,
+	}}
+
+	for _, tc := range testCases {
+		n := New(MaxOutputBytes(tc.max))
+		var buf bytes.Buffer
+		if err := n.Normalize(&buf, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: Normalize: %v", tc.desc, err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.desc, got, tc.want)
+		}
+		got, err := io.ReadAll(n.NewNormalizingReader(strings.NewReader(tc.in)))
+		if err != nil {
+			t.Errorf("%s: NewNormalizingReader: %v", tc.desc, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: reader: got %q, want %q", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestMaxOutputBytesStops(t *testing.T) {
+	// The syntax error follows the truncated output.
+	n := New(MaxOutputBytes(3))
+	var buf bytes.Buffer
+	in := `<a>` + strings.Repeat("<b/>", 10000) + `</c>`
+	if err := n.Normalize(&buf, strings.NewReader(in)); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
+
+func TestHashIgnoresMaxOutputBytes(t *testing.T) {
+	a, err := New(MaxOutputBytes(3)).Hash(strings.NewReader(`<a><b/></a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New().Hash(strings.NewReader(`<a><b/></a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("got different hashes with MaxOutputBytes")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+)
+
+// Values returns the values of the normalized document r by their logical
+// path, the names of their element and its ancestors without positions.
+// The character data of an element, except whitespace, is listed under
+// its path, such as /order/item, and attribute values under the path of
+// the attribute, such as /order/item/@id. Values are listed in document
+// order, and names are in Clark notation.
+func (n *Normalizer) Values(r io.Reader) (map[string][]string, error) {
+	r, err := n.decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	d, release := n.newDecoder(r)
+	defer release()
+	tn := n.getTokenNormalizer(d)
+	defer putTokenNormalizer(tn)
+	vals := map[string][]string{}
+	var path []xml.Name
+	for {
+		t, err := tn.Token()
+		if err == io.EOF {
+			return vals, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name)
+			p := formatPath(path)
+			for _, a := range t.Attr {
+				k := p + "/@" + clarkName(a.Name)
+				vals[k] = append(vals[k], a.Value)
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				p := formatPath(path)
+				vals[p] = append(vals[p], string(t))
+			}
+		}
+	}
+}
+
+// EqualValues reports whether the documents a and b have the same values
+// under the same logical paths, as returned by Values, regardless of the
+// position of their elements, so that documents compare equal if only
+// their structure is reshuffled.
+func (n *Normalizer) EqualValues(a, b io.Reader) (bool, error) {
+	va, err := n.Values(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := n.Values(b)
+	if err != nil {
+		return false, err
+	}
+	if len(va) != len(vb) {
+		return false, nil
+	}
+	for p, as := range va {
+		bs := vb[p]
+		if len(as) != len(bs) {
+			return false, nil
+		}
+		sort.Strings(as)
+		sort.Strings(bs)
+		for i := range as {
+			if as[i] != bs[i] {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	in := `<order xmlns:p="urn:p" id="1">` + "\n" +
+		`<item p:sku="a"><name>x</name></item>` + "\n" +
+		`<item p:sku="b"><name>y</name>z</item></order>`
+	want := map[string][]string{
+		"/order/@id":              {"1"},
+		"/order/item/@{urn:p}sku": {"a", "b"},
+		"/order/item/name":        {"x", "y"},
+		"/order/item":             {"z"},
+	}
+	got, err := new(Normalizer).Values(strings.NewReader(in))
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestEqualValues(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		n         Normalizer
+		a, b      string
+		wantEqual bool
+		wantErr   bool
+	}{{
+		desc:      "reshuffled elements",
+		a:         `<r><i><v>1</v><w>2</w></i><i><v>3</v></i></r>`,
+		b:         `<r><i><v>3</v><w>2</w></i><i><v>1</v></i></r>`,
+		wantEqual: true,
+	}, {
+		desc: "value under other path",
+		a:    `<r><i><v>1</v></i></r>`,
+		b:    `<r><v>1</v></r>`,
+	}, {
+		desc: "differing count",
+		a:    `<r><v>1</v><v>1</v></r>`,
+		b:    `<r><v>1</v></r>`,
+	}, {
+		desc: "differing value",
+		a:    `<r a="1"/>`,
+		b:    `<r a="2"/>`,
+	}, {
+		desc:      "normalized values",
+		n:         Normalizer{NormalizeBooleans: true},
+		a:         `<r a="1"><b>true</b></r>`,
+		b:         `<r a="true"><b>1</b></r>`,
+		wantEqual: true,
+	}, {
+		desc:    "malformed",
+		a:       `<r>`,
+		b:       `<r/>`,
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		eq, err := tc.n.EqualValues(strings.NewReader(tc.a), strings.NewReader(tc.b))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.desc, err, tc.wantErr)
+			continue
+		}
+		if eq != tc.wantEqual {
+			t.Errorf("%s: got %v, want %v", tc.desc, eq, tc.wantEqual)
+		}
+	}
+}
@@ -3,102 +3,583 @@
 // license that can be found in the LICENSE file.
 
 // Package xmltest provides utilities for XML testing.
+//
+// The normalization rules are versioned by Rules. The zero value selects
+// RulesV1, the rules of the original release, so later versions only
+// apply if selected. RulesV2 removes whitespace outside of the root
+// element, and rejects input with a second root element or with character
+// data outside of the root element as malformed, unless
+// Normalizer.AllowMultipleRoots or Normalizer.Fragment is set.
 package xmltest
 
 import (
 	"bytes"
 	"encoding/xml"
 	"io"
-	"sort"
+	"os"
+	"time"
 )
 
-// Normalizer normalizes XML.
+// Namespace is the namespace of the elements that normalization inserts,
+// such as the placeholders of ignored elements.
+const Namespace = "https://github.com/rsto/xmltest"
+
+// Normalizer normalizes XML. Its fields may be set directly, or by the
+// Options passed to New.
+//
+// A Normalizer is reusable and may be used by multiple goroutines at once,
+// for example shared by parallel subtests, as long as its fields are not
+// modified after first use. Its Cache, Trace writer, hooks, transforms and
+// filters may then be called concurrently and must be safe for that.
+// Buffers are pooled internally across calls.
 type Normalizer struct {
+	// Rules selects the version of the normalization rules. The zero
+	// value selects RulesV1, the rules of the original release.
+	Rules Rules
 	// OmitWhitespace instructs to ignore whitespace between element tags.
 	OmitWhitespace bool
 	// OmitComments instructs to ignore XML comments.
 	OmitComments bool
+	// StructureOnly instructs to compare only element names, their
+	// nesting and attribute names, by removing character data and
+	// comments and emptying attribute values, for example to compare the
+	// shape of documents whose values differ per environment.
+	StructureOnly bool
+	// AllowMultipleRoots instructs to accept input with several root
+	// elements, such as concatenated documents or a stream of stanzas.
+	// Each root element is normalized on its own, and documents are
+	// compared root by root in order. The default rules accept such input
+	// anyway; only RulesV2 rejects a second root element without it.
+	AllowMultipleRoots bool
+	// Fragment instructs to accept XML fragments, that is any sequence of
+	// elements and character data such as <a/>text<b/>. It implies
+	// AllowMultipleRoots.
+	Fragment bool
+	// MaxDepth, if positive, limits the nesting depth of elements. Deeper
+	// input fails with a *LimitError.
+	MaxDepth int
+	// Unwrap lists elements whose start and end tags are removed while
+	// their content is kept, such as the stream wrapper of XMPP. The input
+	// may end while unwrapped elements are open. A name with an empty Space
+	// matches elements in any namespace.
+	Unwrap []xml.Name
+	// IgnoreAttrs lists attributes to remove. A name with an empty Space
+	// matches attributes in any namespace.
+	IgnoreAttrs []xml.Name
+	// IgnoreElements lists elements to remove including their content. A
+	// name with an empty Space matches elements in any namespace.
+	IgnoreElements []xml.Name
+	// MarkIgnored instructs to replace each element removed by
+	// IgnoreElements with an empty placeholder element named ignored in
+	// the namespace Namespace, with the name of the removed element in its
+	// name attribute, so that differences still show that it was present.
+	MarkIgnored bool
+	// RedactAttrs lists attributes whose values are replaced by
+	// Redaction, and RedactElements elements whose content is replaced by
+	// it, so that secrets such as credentials and tokens appear neither in
+	// the output nor in differences or traces. A name with an empty Space
+	// matches any namespace.
+	RedactAttrs    []xml.Name
+	RedactElements []xml.Name
+	// Redaction replaces redacted content. The zero value selects
+	// "REDACTED".
+	Redaction string
+	// OmitNamespaces lists namespace URIs whose elements and attributes
+	// are removed, such as those of editor metadata.
+	OmitNamespaces []string
+	// CollapseAttrs lists attributes whose values have runs of whitespace
+	// collapsed to a single space and leading and trailing whitespace
+	// removed. A name with an empty Space matches attributes in any
+	// namespace.
+	CollapseAttrs []xml.Name
+	// Schema, if set, describes the elements and attributes of documents,
+	// as read by ParseSchema or ParseDTD.
+	// Whitespace is removed between the children of elements with
+	// element-only content, missing attributes with default values are
+	// added, and whitespace in values is handled as their types declare.
+	Schema *Schema
+	// QNameAttrs lists attributes whose values are qualified names, such
+	// as xsi:type. Their values are rewritten to the form {uri}local, so
+	// that they compare equal regardless of the namespace prefix used. A
+	// name with an empty Space matches attributes in any namespace.
+	QNameAttrs []xml.Name
+	// ResolveURIAttrs lists attributes whose values are URI references,
+	// such as href and src. Relative references are resolved against the
+	// base URI in scope, as set by xml:base, and xml:base attributes are
+	// removed, so that documents that only differ in how they factor out
+	// base URIs compare equal. A name with an empty Space matches attributes
+	// in any namespace.
+	ResolveURIAttrs []xml.Name
+	// NormalizeLang instructs to rewrite xml:lang values to the case
+	// conventions of BCP 47, such as en-US and zh-Hant-TW.
+	NormalizeLang bool
+	// IgnoreLangSubtags instructs to reduce xml:lang values to their
+	// primary language subtag, so that en and en-US compare equal. It
+	// implies NormalizeLang.
+	IgnoreLangSubtags bool
+	// Rename maps element and attribute names to the names they are
+	// replaced by, for example to compare documents of two versions of a
+	// vocabulary. A name with an empty Space matches names in any
+	// namespace that no other key matches, and mapping it to a name with
+	// an empty Space keeps their namespace. Other fields refer to names
+	// before renaming.
+	Rename map[xml.Name]xml.Name
+	// CaseInsensitiveNames instructs to lowercase the local names of
+	// elements and attributes.
+	CaseInsensitiveNames bool
+	// NormalizeBooleans instructs to rewrite text and attribute values that
+	// are lexical representations of XSD booleans (1, true, 0, false) to
+	// their canonical form true or false.
+	NormalizeBooleans bool
+	// DecimalCommaPaths lists paths, as described for ExpectText, of text
+	// and attribute values that are numbers written with a decimal comma
+	// and optional thousands separators, such as 1.234,5 or 1 234,5. They
+	// are rewritten with a decimal point and without separators, as 1234.5,
+	// to compare fixtures of locale-sensitive systems. Other values are
+	// kept. The paths match normalized names.
+	DecimalCommaPaths []string
+	// Base64Paths lists paths, as described for ExpectText, of text and
+	// attribute values that hold base64-encoded binary content, such as
+	// attachments. They are decoded and encoded again without line breaks,
+	// so that they compare equal if their bytes are equal regardless of
+	// how they are wrapped, or replaced by the SHA-256 digest of their
+	// bytes, as sha256:hex, if Base64Digest is set. Values that are empty
+	// or not valid padded base64 are kept. The paths match normalized names.
+	Base64Paths  []string
+	Base64Digest bool
+	// TextTransform, if not nil, rewrites character data. The path holds
+	// the names of the enclosing elements, and must not be retained.
+	TextTransform func(path []xml.Name, s string) string
+	// AttrTransform, if not nil, rewrites the attributes of element elem.
+	AttrTransform func(elem xml.Name, a xml.Attr) xml.Attr
+	// NumberTolerance, if not nil, instructs EqualXML to compare text and
+	// attribute values that both parse as numbers by their numeric value
+	// within the given tolerance.
+	NumberTolerance *Tolerance
+	// NumericAttrs lists attributes whose values contain several numbers,
+	// such as SVG path data. EqualXML compares their numbers by numeric
+	// value within NumberTolerance, if set, and the text between the
+	// numbers ignoring whitespace and commas. It matches normalized names.
+	// A name with an empty Space matches attributes in any namespace.
+	NumericAttrs []xml.Name
+	// CompareTimestamps instructs EqualXML to compare text and attribute
+	// values that both parse as RFC 3339 or ISO 8601 timestamps as
+	// instants in time, equal if they are at most TimestampTolerance
+	// apart.
+	CompareTimestamps  bool
+	TimestampTolerance time.Duration
+	// LessAttr, if set, replaces the order of attributes by name, for
+	// example to put id first as some golden formats require. Attributes
+	// that it orders neither way are sorted by name.
+	LessAttr func(a, b xml.Attr) bool
+	// KeepAttrOrder instructs to keep attributes in their order in the
+	// input, so that documents whose attributes differ in order compare
+	// unequal. It takes precedence over LessAttr.
+	KeepAttrOrder bool
+	// SortElements instructs to sort sibling elements by their fully
+	// qualified name, and elements of the same name by their normalized
+	// content. Character data and comments between elements keep their
+	// position.
+	SortElements bool
+	// UnorderedUnder lists elements whose child elements are sorted by
+	// name and content, so that their order does not matter. OrderedUnder
+	// lists elements whose child elements keep their order even if
+	// SortElements is set. They match normalized names. A name with an
+	// empty Space matches elements in any namespace.
+	UnorderedUnder []xml.Name
+	OrderedUnder   []xml.Name
+	// MixedContent configures how character data in elements with child
+	// elements is compared.
+	MixedContent MixedContent
+	// MatchChildren configures elements whose children are matched by a
+	// key rather than by their position.
+	MatchChildren []MatchChildrenBy
+	// Color configures whether DiffText colors its output.
+	Color ColorMode
+	// Granularity configures how Diff and DiffText report differences.
+	Granularity Granularity
+	// MaxDifferences, if positive, makes Diff and DiffText stop after
+	// reporting as many differences.
+	MaxDifferences int
+	// Cache, if not nil, caches the canonical hashes of the documents
+	// compared by EqualXML.
+	Cache Cache
+	// Format configures the output of Normalize.
+	Format Format
+	// MaxOutputBytes, if positive, limits the output of Normalize. Once
+	// the limit is reached, normalization stops without an error and the
+	// output ends with a truncation marker, so that messages that embed
+	// normalized documents stay readable. Hash and EqualXML ignore it.
+	MaxOutputBytes int
+	// JSON configures the projection of ToJSON.
+	JSON JSONConvention
+	// XMLDeclaration instructs Normalize to start its output with the
+	// canonical XML declaration <?xml version="1.0" encoding="UTF-8"?>,
+	// which keeps the standalone parameter of the input declaration, if
+	// any. EqualXML ignores XML declarations regardless.
+	XMLDeclaration bool
+	// Decoder, if not nil, returns the reader of the tokens of input
+	// documents in place of encoding/xml, for example a stricter or faster
+	// parser. Its tokens may carry either namespace prefixes or namespace
+	// URIs in their names, as for NormalizeTokens, and it is responsible for
+	// transcoding. Syntax errors that it returns as an *xml.SyntaxError are
+	// reported by a *SyntaxError.
+	Decoder func(r io.Reader) xml.TokenReader
+	// Decompress instructs Normalize and EqualXML to decompress input that
+	// is compressed with gzip, or with a format registered with
+	// RegisterDecompressor, as recognized by its leading magic bytes. Other
+	// input is read as is.
+	Decompress bool
+	// Precheck instructs EqualXML to first compare the number of elements,
+	// the root element name and a digest of all element names of both
+	// documents, and to report false without normalizing if they differ.
+	// The check is skipped if elements are removed or filtered.
+	Precheck bool
+	// CheckIdempotent instructs Normalize to normalize its own output again
+	// and to fail with an *IdempotenceError if the second pass yields a
+	// different output. Nothing is written then. It is meant for debugging
+	// rules, transforms and filters.
+	CheckIdempotent bool
+	// Trace, if not nil, receives a line for each token read during
+	// normalization, with the path of its enclosing elements and whether
+	// it was kept, dropped or rewritten. Adjacent character data is traced
+	// as a single token.
+	Trace io.Writer
+	// OnToken, if not nil, is called by Normalize and NormalizeTokens for
+	// each normalized token before it is written. The depth counts the
+	// elements enclosing t, so an element and its end have the same depth.
+	OnToken func(depth int, t xml.Token)
+	// OnProgress, if not nil, is called by Normalize and NormalizeTokens
+	// after every ProgressInterval tokens written, 10000 if zero, and once
+	// at the end, so that long runs can report progress. An error it
+	// returns stops normalization and is returned, for example to time out.
+	OnProgress       func(p Progress) error
+	ProgressInterval int
+	// Parallelism, if greater than one, is the number of documents that
+	// EqualXML and AllEqualXML normalize, and of pairs that RunCorpus
+	// compares, concurrently. The documents are then read from separate
+	// goroutines. It has no effect while tracing.
+	Parallelism int
+	// Expect constrains the format of text and attribute values. Normalize
+	// and EqualXML fail with an *ExpectError for the first value that does
+	// not match.
+	Expect []ExpectText
+	// Filters are applied in order to each token after the built-in
+	// normalization rules.
+	Filters []TokenFilter
 }
 
+// TokenFilter drops or rewrites a token during normalization. The path
+// holds the names of the elements enclosing t, and must not be retained.
+// A filter returns the token to continue with, or false to drop t.
+//
+// Filters are called for start elements, character data and comments. The
+// token returned must have the same type as t. Dropping a start element
+// drops the whole element including its content. End elements always
+// match their (possibly rewritten) start element.
+type TokenFilter func(path []xml.Name, t xml.Token) (xml.Token, bool)
+
 // Normalize writes the normalized XML content of r to w. It applies the
 // following rules
 //
-//     * Rename namespace prefixes according to an internal heuristic.
-//     * Remove unnecessary namespace declarations.
-//     * Sort attributes in XML start elements in lexical order of their
-//       fully qualified name.
-//     * Remove XML directives and processing instructions.
-//     * Remove CDATA between XML tags that only contains whitespace, if
-//       instructed to do so.
-//     * Remove comments, if instructed to do so.
+//   - Decode UTF-16 input and remove a byte order mark.
+//   - Rename namespace prefixes according to an internal heuristic.
+//   - Remove unnecessary namespace declarations.
+//   - Sort attributes in XML start elements in lexical order of their
+//     fully qualified name, or by LessAttr if set, unless instructed to
+//     keep their order.
+//   - Escape character data and attribute values as in Canonical XML.
+//   - Remove XML directives and processing instructions, but start with a
+//     canonical XML declaration, if instructed to do so.
+//   - Reject a second root element and character data outside of the
+//     root element, unless instructed to accept several documents or a
+//     fragment (since RulesV2).
+//   - Remove whitespace outside of the root element, unless normalizing a
+//     fragment (since RulesV2).
+//   - Remove CDATA between XML tags that only contains whitespace, if
+//     instructed to do so.
+//   - Remove comments, if instructed to do so.
+//   - Remove character data and comments and empty attribute values, if
+//     instructed to compare structure only.
+//   - Remove ignorable whitespace, add default attributes and collapse
+//     whitespace in values according to the Schema, if any.
+//   - Remove ignored elements and attributes, those in omitted namespaces
+//     and the tags of unwrapped elements, if any, and mark the ignored
+//     elements with placeholders, if instructed to do so.
+//   - Redact attribute values and element content, if any.
+//   - Collapse whitespace in attribute values, if instructed to do so.
+//   - Resolve the prefixes of QName attribute values, if any.
+//   - Resolve URI attribute values against xml:base and remove xml:base,
+//     if instructed to do so.
+//   - Canonicalize xml:lang values, if instructed to do so.
+//   - Rename elements and attributes, if any.
+//   - Lowercase element and attribute names, if instructed to do so.
+//   - Canonicalize boolean values, if instructed to do so.
+//   - Rewrite numbers with decimal commas at the configured paths, if any.
+//   - Re-encode or digest base64 content at the configured paths, if any.
+//   - Apply the text and attribute transforms, if any.
+//   - Apply the token filters, if any.
+//   - Collapse or remove character data in mixed content, if instructed
+//     to do so.
+//   - Sort sibling elements except the children of ordered elements, or
+//     the children of unordered elements, if instructed to do so.
+//   - Sort children that are matched by a key by their key.
+//   - Fail on values that do not match their ExpectText, if any.
 //
 // Note that the normalized XML content might differ from canonicalized XML
 // as defined by W3C.
 func (n *Normalizer) Normalize(w io.Writer, r io.Reader) error {
-	d := xml.NewDecoder(r)
-	e := xml.NewEncoder(w)
+	r, err := n.decompress(r)
+	if err != nil {
+		return err
+	}
+	d, release := n.newDecoder(r)
+	defer release()
+	return n.NormalizeTokens(w, d)
+}
+
+// NormalizeTokens writes the normalized XML content of the tokens read from
+// tr to w. It applies the same rules as Normalize. The tokens of tr may
+// carry either namespace prefixes or namespace URIs in their names.
+//
+// Malformed input fails with a *SyntaxError, failures to write to w with a
+// *WriteError, and output that changes when normalized again with an
+// *IdempotenceError if CheckIdempotent is set.
+func (n *Normalizer) NormalizeTokens(w io.Writer, tr xml.TokenReader) error {
+	return n.normalize(w, tr, nil)
+}
+
+// normalize implements NormalizeTokens, assigning prefixes from pt if it
+// is not nil.
+func (n *Normalizer) normalize(w io.Writer, tr xml.TokenReader, pt *prefixTable) error {
+	var tw *truncatingWriter
+	if n.MaxOutputBytes > 0 {
+		tw = &truncatingWriter{w: w, left: n.MaxOutputBytes}
+		w = tw
+	}
+	var err error
+	if n.CheckIdempotent {
+		err = n.normalizeChecked(w, tr, pt)
+	} else {
+		err = n.normalizeTokens(w, tr, pt)
+	}
+	if tw != nil && tw.truncated {
+		return tw.err
+	}
+	return err
+}
+
+func (n *Normalizer) normalizeTokens(w io.Writer, tr xml.TokenReader, pt *prefixTable) error {
+	tn := n.getTokenNormalizer(tr)
+	defer putTokenNormalizer(tn)
+	ew := &errWriter{w: w}
+	bw, release := newWriter(ew)
+	defer release()
+	tw := newTokenWriter(bw, n.Format)
+	tw.prefixes = pt
+	out := newOutput(tn, tw, ew)
 	for {
-		t, err := d.Token()
+		err := out.next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			if t == nil && err == io.EOF {
-				break
-			}
 			return err
 		}
-		switch val := t.(type) {
-		case xml.Directive, xml.ProcInst:
-			continue
-		case xml.Comment:
-			if n.OmitComments {
-				continue
-			}
-		case xml.CharData:
-			if n.OmitWhitespace && len(bytes.TrimSpace(val)) == 0 {
-				continue
-			}
-		case xml.StartElement:
-			start, _ := xml.CopyToken(val).(xml.StartElement)
-			attr := start.Attr[:0]
-			for _, a := range start.Attr {
-				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
-					continue
-				}
-				attr = append(attr, a)
-			}
-			sort.Sort(byName(attr))
-			start.Attr = attr
-			t = start
+	}
+	return out.close()
+}
+
+// output writes the normalized tokens of a TokenNormalizer one by one.
+type output struct {
+	n        *Normalizer
+	tn       *TokenNormalizer
+	tw       *tokenWriter
+	ew       *errWriter
+	depth    int
+	declared bool
+	// tokens counts the tokens written.
+	tokens int64
+}
+
+func newOutput(tn *TokenNormalizer, tw *tokenWriter, ew *errWriter) *output {
+	return &output{n: tn.n, tn: tn, tw: tw, ew: ew, declared: !tn.n.XMLDeclaration}
+}
+
+// next writes the next normalized token. It returns io.EOF at the end of
+// the input.
+func (o *output) next() error {
+	t, err := o.tn.Token()
+	if err != nil {
+		if t == nil && err == io.EOF {
+			return io.EOF
 		}
-		err = e.EncodeToken(t)
+		return err
+	}
+	if !o.declared {
+		// The input declaration, if any, has been read by now.
+		o.declared = true
+		if err := o.tw.writeToken(o.tn.declaration()); err != nil {
+			return o.ew.wrap(err)
+		}
+	}
+	if _, ok := t.(xml.EndElement); ok {
+		o.depth--
+	}
+	if o.n.OnToken != nil {
+		o.n.OnToken(o.depth, t)
+	}
+	if _, ok := t.(xml.StartElement); ok {
+		o.depth++
+	}
+	if err := o.tw.writeToken(t); err != nil {
+		return o.ew.wrap(err)
+	}
+	o.tokens++
+	if o.n.OnProgress != nil && o.tokens%o.n.progressInterval() == 0 {
+		return o.n.OnProgress(o.progress())
+	}
+	return nil
+}
+
+// close completes the output and reports the final progress.
+func (o *output) close() error {
+	if err := o.tw.close(); err != nil {
+		return o.ew.wrap(err)
+	}
+	if o.n.OnProgress != nil {
+		return o.n.OnProgress(o.progress())
+	}
+	return nil
+}
+
+func (o *output) progress() Progress {
+	return Progress{
+		BytesRead:    o.tn.d.InputOffset(),
+		BytesWritten: o.ew.n + int64(o.tw.w.Buffered()),
+		Tokens:       o.tokens,
+	}
+}
+
+// NormalizePair writes the normalized XML content of a to wa and of b to
+// wb, as Normalize does. Namespace prefixes are assigned from a table
+// shared by both documents, so that a namespace gets the same prefix in
+// both outputs even if it first appears at different points, and textual
+// diffs of the outputs show differences in content only.
+func (n *Normalizer) NormalizePair(wa, wb io.Writer, a, b io.Reader) error {
+	pt := newPrefixTable()
+	for _, d := range []struct {
+		w io.Writer
+		r io.Reader
+	}{{wa, a}, {wb, b}} {
+		r, err := n.decompress(d.r)
+		if err != nil {
+			return err
+		}
+		dec, release := n.newDecoder(r)
+		err = n.normalize(d.w, dec, pt)
+		release()
 		if err != nil {
 			return err
 		}
 	}
-	return e.Flush()
+	return nil
+}
+
+// NormalizeToEncoder encodes the normalized XML content of r with e, and
+// flushes e. Unlike Normalize, it leaves namespace prefixes, indentation
+// and escaping to e, so the output may vary with the Go version.
+func (n *Normalizer) NormalizeToEncoder(e *xml.Encoder, r io.Reader) error {
+	r, err := n.decompress(r)
+	if err != nil {
+		return err
+	}
+	d, release := n.newDecoder(r)
+	defer release()
+	tn := n.getTokenNormalizer(d)
+	defer putTokenNormalizer(tn)
+	for {
+		t, err := tn.Token()
+		if err == io.EOF {
+			return e.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.EncodeToken(t); err != nil {
+			return err
+		}
+	}
 }
 
 // EqualXML tests for equality of the normalized XML contents of a and b.
+// Text and attribute values are compared literally, unless the Normalizer
+// is configured to compare them approximately. If Parallelism is greater
+// than one, a and b are normalized concurrently.
 func (n *Normalizer) EqualXML(a, b io.Reader) (bool, error) {
-	var buf bytes.Buffer
-	if err := n.Normalize(&buf, a); err != nil {
+	if n.Precheck && n.prechecks() {
+		return n.equalPrechecked(a, b)
+	}
+	return n.equal(a, b)
+}
+
+func (n *Normalizer) equal(a, b io.Reader) (bool, error) {
+	if n.Cache != nil {
+		return n.equalCached(a, b)
+	}
+	return n.equalUncached(a, b)
+}
+
+func (n *Normalizer) equalUncached(a, b io.Reader) (bool, error) {
+	docs, err := n.readAllTokens([]io.Reader{a, b})
+	if err != nil {
 		return false, err
 	}
-	normA := buf.String()
-	buf.Reset()
-	if err := n.Normalize(&buf, b); err != nil {
+	return n.equalTokens(docs[0], docs[1]), nil
+}
+
+// EqualValueXML tests for equality of the normalized XML encoding of v and
+// the normalized XML content of want. The value v is marshaled with
+// xml.Marshal.
+func (n *Normalizer) EqualValueXML(v interface{}, want io.Reader) (bool, error) {
+	b, err := xml.Marshal(v)
+	if err != nil {
 		return false, err
 	}
-	normB := buf.String()
-	return normA == normB, nil
+	return n.EqualXML(bytes.NewReader(b), want)
 }
 
-type byName []xml.Attr
+// AllEqualXML tests whether the normalized XML contents of all readers are
+// mutually equal. Each input is read and normalized once, up to
+// Parallelism of them at once. AllEqualXML reports true for fewer than two
+// readers.
+func (n *Normalizer) AllEqualXML(readers ...io.Reader) (bool, error) {
+	docs, err := n.readAllTokens(readers)
+	if err != nil {
+		return false, err
+	}
+	// Approximate comparison is not transitive, so compare all pairs.
+	for i := range docs {
+		for j := i + 1; j < len(docs); j++ {
+			if !n.equalTokens(docs[i], docs[j]) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
 
-func (a byName) Len() int      { return len(a) }
-func (a byName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
-func (a byName) Less(i, j int) bool {
-	if a[i].Name.Space != a[j].Name.Space {
-		return a[i].Name.Space < a[j].Name.Space
+// AllEqualXMLFiles is like AllEqualXML but reads the contents of the
+// named files.
+func (n *Normalizer) AllEqualXMLFiles(names ...string) (bool, error) {
+	readers := make([]io.Reader, len(names))
+	for i, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return false, err
+		}
+		defer f.Close()
+		readers[i] = f
 	}
-	return a[i].Name.Local < a[j].Name.Local
+	return n.AllEqualXML(readers...)
 }
@@ -6,9 +6,16 @@ package xmltest
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNormalize(t *testing.T) {
@@ -91,6 +98,225 @@ func TestNormalize(t *testing.T) {
 		n:       Normalizer{OmitWhitespace: true},
 		in:      `<root>  <foo>  </foo> a  </root>`,
 		wantXML: `<root><foo></foo> a  </root>`,
+	}, {
+		desc: "drop elements with a filter",
+		n: Normalizer{Filters: []TokenFilter{
+			func(path []xml.Name, t xml.Token) (xml.Token, bool) {
+				start, ok := t.(xml.StartElement)
+				return t, !ok || start.Name.Local != "secret"
+			},
+		}},
+		in:      `<root><secret><foo/>bar</secret><baz/></root>`,
+		wantXML: `<root><baz></baz></root>`,
+	}, {
+		desc: "rewrite tokens with filters",
+		n: Normalizer{Filters: []TokenFilter{
+			func(path []xml.Name, t xml.Token) (xml.Token, bool) {
+				if start, ok := t.(xml.StartElement); ok && start.Name.Local == "id" {
+					start.Name.Local = "key"
+					return start, true
+				}
+				return t, true
+			},
+			func(path []xml.Name, t xml.Token) (xml.Token, bool) {
+				if _, ok := t.(xml.CharData); ok && len(path) > 0 && path[len(path)-1].Local == "key" {
+					return xml.CharData("X"), true
+				}
+				return t, true
+			},
+		}},
+		in:      `<root><id>42</id></root>`,
+		wantXML: `<root><key>X</key></root>`,
+	}, {
+		desc: "transform text and attributes",
+		n: Normalizer{
+			TextTransform: func(path []xml.Name, s string) string {
+				return strings.ToLower(s)
+			},
+			AttrTransform: func(elem xml.Name, a xml.Attr) xml.Attr {
+				if elem.Local == "root" {
+					a.Value = strings.ToUpper(a.Value)
+				}
+				return a
+			},
+		},
+		in:      `<root a="Foo"><foo b="Bar">BaZ</foo></root>`,
+		wantXML: `<root a="FOO"><foo b="Bar">baz</foo></root>`,
+	}, {
+		desc:    "keep boolean literals by default",
+		in:      `<root a="1"><b>0</b></root>`,
+		wantXML: `<root a="1"><b>0</b></root>`,
+	}, {
+		desc:    "canonicalize boolean literals if requested",
+		n:       Normalizer{NormalizeBooleans: true},
+		in:      `<root a="1" b="false" c="yes"><b> 0 </b><c>True</c></root>`,
+		wantXML: `<root a="true" b="false" c="yes"><b>false</b><c>True</c></root>`,
+	}, {
+		desc:    "lowercase names if requested",
+		n:       Normalizer{CaseInsensitiveNames: true},
+		in:      `<Root A="Foo"><FOO/></Root>`,
+		wantXML: `<root a="Foo"><foo></foo></root>`,
+	}, {
+		desc:    "keep element order by default",
+		in:      `<root><b/><a/></root>`,
+		wantXML: `<root><b></b><a></a></root>`,
+	}, {
+		desc: "sort elements if requested",
+		n:    Normalizer{SortElements: true},
+		in: `<root>` +
+			`<b><y/><x/></b>` +
+			`text` +
+			`<a n="2"/><a n="1"/>` +
+			`</root>`,
+		wantXML: `<root>` +
+			`<a n="1"></a>` +
+			`text` +
+			`<a n="2"></a><b><x></x><y></y></b>` +
+			`</root>`,
+	}, {
+		desc: "concatenated documents if requested",
+		n:    Normalizer{AllowMultipleRoots: true, SortElements: true},
+		in: `<?xml version="1.0"?><b><y/><x/></b>` + "\n" +
+			`<?xml version="1.0"?><a/>`,
+		wantXML: "<b><x></x><y></y></b>\n<a></a>",
+	}, {
+		desc:    "concatenated documents by default",
+		in:      `<?xml version="1.0"?><b/>` + "\n" + `<?xml version="1.0"?><a/>`,
+		wantXML: "<b></b>\n<a></a>",
+	}, {
+		desc:    "concatenated documents if requested in rules v2",
+		n:       Normalizer{Rules: RulesV2, AllowMultipleRoots: true},
+		in:      `<b/>` + "\n" + `<a/>`,
+		wantXML: "<b></b><a></a>",
+	}, {
+		desc:    "remove whitespace outside of root element in latest rules",
+		n:       Normalizer{Rules: RulesLatest},
+		in:      "\n<a/>\n",
+		wantXML: "<a></a>",
+	}, {
+		desc:    "remove whitespace outside of root element in rules v2",
+		n:       Normalizer{Rules: RulesV2},
+		in:      "\n<a/>\n",
+		wantXML: "<a></a>",
+	}, {
+		desc:    "keep whitespace outside of root element in rules v1",
+		n:       Normalizer{Rules: RulesV1},
+		in:      "<a/>\n",
+		wantXML: "<a></a>\n",
+	}, {
+		desc:    "accept several root elements in rules v1",
+		n:       Normalizer{Rules: RulesV1},
+		in:      `<a/><b/>`,
+		wantXML: `<a></a><b></b>`,
+	}, {
+		desc:    "keep text outside of root element in rules v1",
+		n:       Normalizer{Rules: RulesV1},
+		in:      `<a/>text`,
+		wantXML: `<a></a>text`,
+	}, {
+		desc:    "fragment if requested",
+		n:       Normalizer{Fragment: true},
+		in:      `text<b y="2" x="1"/> <a/>`,
+		wantXML: `text<b x="1" y="2"></b> <a></a>`,
+	}, {
+		desc:    "unwrap elements and ignore attributes if requested",
+		n:       Normalizer{Unwrap: []xml.Name{{Local: "wrap"}}, IgnoreAttrs: []xml.Name{{Local: "id"}}},
+		in:      `<wrap id="1"><a id="2" b="3"/>x<wrap>y</wrap></wrap>`,
+		wantXML: `<a b="3"></a>xy`,
+	}, {
+		desc:    "unwrapped elements need not be closed",
+		n:       Normalizer{Unwrap: []xml.Name{{Local: "wrap"}}},
+		in:      `<wrap><a/>`,
+		wantXML: `<a></a>`,
+	}, {
+		desc:    "ignore elements if requested",
+		n:       Normalizer{IgnoreElements: []xml.Name{{Local: "x"}}},
+		in:      `<root>a<x>b<y/></x>c</root>`,
+		wantXML: `<root>ac</root>`,
+	}, {
+		desc:    "rewrite decimal commas at configured paths",
+		n:       Normalizer{DecimalCommaPaths: []string{"price", "/r/@total"}},
+		in:      `<r total="1.234,50" n="1,5"><price>12,5</price><price> 1 000 </price><note>1,5</note></r>`,
+		wantXML: `<r n="1,5" total="1234.50"><price>12.5</price><price>1000</price><note>1,5</note></r>`,
+	}, {
+		desc:    "re-encode base64 content at configured paths",
+		n:       Normalizer{Base64Paths: []string{"data", "@sig"}},
+		in:      "<r sig='aGk'><data>aGVs\n  bG8=</data><note>aGk</note></r>",
+		wantXML: `<r sig="aGk"><data>aGVsbG8=</data><note>aGk</note></r>`,
+	}, {
+		desc:    "mark ignored elements if requested",
+		n:       Normalizer{IgnoreElements: []xml.Name{{Local: "x"}}, MarkIgnored: true},
+		in:      `<root>a<x>b<x/></x>c<p:x xmlns:p="urn:p"/></root>`,
+		wantXML: `<root>a<_xmltest:ignored xmlns:_xmltest="https://github.com/rsto/xmltest" name="x"></_xmltest:ignored>c<_xmltest:ignored xmlns:_xmltest="https://github.com/rsto/xmltest" name="{urn:p}x"></_xmltest:ignored></root>`,
+	}, {
+		desc:    "resolve QName attributes if requested",
+		n:       Normalizer{QNameAttrs: []xml.Name{{Local: "type"}}},
+		in:      `<root xmlns:p="urn:p" type="p:t"><a type="u"/><b type="q:v"/></root>`,
+		wantXML: `<root type="{urn:p}t"><a type="u"></a><b type="q:v"></b></root>`,
+	}, {
+		desc:    "sort children of unordered elements if requested",
+		n:       Normalizer{UnorderedUnder: []xml.Name{{Local: "set"}}},
+		in:      `<root><b/><a/><set><b><y/><x/></b><a/></set></root>`,
+		wantXML: `<root><b></b><a></a><set><a></a><b><y></y><x></x></b></set></root>`,
+	}, {
+		desc:    "sort attributes by LessAttr, then by name",
+		n:       Normalizer{LessAttr: idFirst},
+		in:      `<root z="1" b="2" id="x" xmlns:p="urn:p" p:id="y"/>`,
+		wantXML: `<root xmlns:_="urn:p" id="x" _:id="y" b="2" z="1"></root>`,
+	}, {
+		desc:    "keep the order of attributes if requested",
+		n:       Normalizer{KeepAttrOrder: true, LessAttr: idFirst},
+		in:      `<root z="1" b="2" id="x"/>`,
+		wantXML: `<root z="1" b="2" id="x"></root>`,
+	}, {
+		desc: "rename elements and attributes",
+		n: *New(
+			Rename("{urn:v1}customer", "{urn:v2}client"),
+			Rename("name", "fullName"),
+			IgnoreAttr("name"),
+		),
+		in:      `<customer xmlns="urn:v1" xmlns:p="urn:p" p:name="x"><name>A</name><customer/></customer>`,
+		wantXML: `<_:client xmlns:_="urn:v2"><__1:fullName xmlns:__1="urn:v1">A</__1:fullName><_:client></_:client></_:client>`,
+	}, {
+		desc:    "compare structure only if requested",
+		n:       Normalizer{StructureOnly: true},
+		in:      `<r a="1"><!-- c --><x b="2">text<y/> more</x></r>`,
+		wantXML: `<r a=""><x b=""><y></y></x></r>`,
+	}, {
+		desc:    "collapse mixed content if requested",
+		n:       Normalizer{MixedContent: MixedContentCollapse},
+		in:      `<p>Hello <b>big</b> world<!-- c --><i/>!<q>x</q></p>`,
+		wantXML: `<p>Hello  world!<b>big</b><!-- c --><i></i><q>x</q></p>`,
+	}, {
+		desc:    "ignore mixed content if requested",
+		n:       Normalizer{MixedContent: MixedContentIgnore, SortElements: true},
+		in:      `<p>Hello <b>big</b> world<a/></p>`,
+		wantXML: `<p><a></a><b>big</b></p>`,
+	}, {
+		desc:    "keep the order of children of ordered elements if requested",
+		n:       Normalizer{SortElements: true, OrderedUnder: []xml.Name{{Local: "seq"}}},
+		in:      `<root><seq><b/><a><y/><x/></a></seq><c/><b/></root>`,
+		wantXML: `<root><b></b><c></c><seq><b></b><a><x></x><y></y></a></seq></root>`,
+	}, {
+		desc:    "omit namespaces and collapse attributes if requested",
+		n:       Normalizer{OmitNamespaces: []string{"urn:x"}, CollapseAttrs: []xml.Name{{Local: "d"}}},
+		in:      `<root xmlns:x="urn:x" x:a="1" d=" M 1  2&#10;Z "><x:meta/></root>`,
+		wantXML: `<root d="M 1 2 Z"></root>`,
+	}, {
+		desc:    "bad: unclosed element",
+		n:       Normalizer{Unwrap: []xml.Name{{Local: "wrap"}}},
+		in:      `<wrap><a>`,
+		wantErr: errors.New("some error"),
+	}, {
+		desc:    "bad: character data outside of root element in rules v2",
+		n:       Normalizer{Rules: RulesV2},
+		in:      `<a/>text`,
+		wantErr: errors.New("some error"),
+	}, {
+		desc:    "bad: multiple root elements in rules v2",
+		n:       Normalizer{Rules: RulesV2},
+		in:      `<a/><b/>`,
+		wantErr: errors.New("some error"),
 	}, {
 		desc:    "bad: make decoder fail with a syntax error",
 		in:      "<root></foo>",
@@ -150,6 +376,17 @@ func TestEqualXML(t *testing.T) {
 		a:         `<s:root xmlns:s="space"/>`,
 		b:         `<root xmlns="space"/>`,
 		wantEqual: true,
+	}, {
+		desc:      "structure only",
+		a:         `<root id="1"><v>a</v></root>`,
+		b:         `<root id="2"><v>b</v></root>`,
+		n:         Normalizer{StructureOnly: true},
+		wantEqual: true,
+	}, {
+		desc: "structure only with different attributes",
+		a:    `<root id="1"/>`,
+		b:    `<root key="1"/>`,
+		n:    Normalizer{StructureOnly: true},
 	}, {
 		desc:      "prefixed namespaces",
 		a:         `<s:root xmlns:s="space"/>`,
@@ -170,6 +407,90 @@ func TestEqualXML(t *testing.T) {
 		a:         `<root>  </root>`,
 		b:         `<root/>`,
 		wantEqual: true,
+	}, {
+		desc: "numbers compare as text by default",
+		a:    `<x>1.0</x>`,
+		b:    `<x>1.00</x>`,
+	}, {
+		desc:      "numbers compare by value with a tolerance",
+		n:         Normalizer{NumberTolerance: &Tolerance{}},
+		a:         `<x y="2e1">1.0</x>`,
+		b:         `<x y="20">1.00</x>`,
+		wantEqual: true,
+	}, {
+		desc:      "numbers within absolute tolerance",
+		n:         Normalizer{NumberTolerance: &Tolerance{Abs: 0.01}},
+		a:         `<path x="10.001" y="-3"/>`,
+		b:         `<path x="10" y="-3.005"/>`,
+		wantEqual: true,
+	}, {
+		desc: "numbers outside absolute tolerance",
+		n:    Normalizer{NumberTolerance: &Tolerance{Abs: 0.01}},
+		a:    `<path x="10.1"/>`,
+		b:    `<path x="10"/>`,
+	}, {
+		desc:      "numbers within relative tolerance",
+		n:         Normalizer{NumberTolerance: &Tolerance{Rel: 1e-6}},
+		a:         `<x>1000000</x>`,
+		b:         `<x>1000000.5</x>`,
+		wantEqual: true,
+	}, {
+		desc: "timestamps compare as text by default",
+		a:    `<t>2024-01-01T00:00:00Z</t>`,
+		b:    `<t>2024-01-01T01:00:00+01:00</t>`,
+	}, {
+		desc:      "timestamps compare as instants",
+		n:         Normalizer{CompareTimestamps: true},
+		a:         `<t at="2024-01-01">2024-01-01T00:00:00Z</t>`,
+		b:         `<t at="2024-01-01T00:00:00.000Z">2024-01-01T01:00:00+01:00</t>`,
+		wantEqual: true,
+	}, {
+		desc: "different instants",
+		n:    Normalizer{CompareTimestamps: true},
+		a:    `<t>2024-01-01T00:00:00Z</t>`,
+		b:    `<t>2024-01-01T00:00:01Z</t>`,
+	}, {
+		desc:      "instants within tolerance",
+		n:         Normalizer{CompareTimestamps: true, TimestampTolerance: time.Second},
+		a:         `<t>2024-01-01T00:00:00Z</t>`,
+		b:         `<t>20240101T000000.5Z</t>`,
+		wantEqual: true,
+	}, {
+		desc: "names are case-sensitive by default",
+		a:    `<Root A="x"/>`,
+		b:    `<root a="x"/>`,
+	}, {
+		desc:      "case-insensitive names",
+		n:         Normalizer{CaseInsensitiveNames: true},
+		a:         `<Root A="x"><ITEM/></Root>`,
+		b:         `<root a="x"><item></item></root>`,
+		wantEqual: true,
+	}, {
+		desc: "element order is significant by default",
+		a:    `<root><a/><b/></root>`,
+		b:    `<root><b/><a/></root>`,
+	}, {
+		desc:      "sorted elements",
+		n:         Normalizer{SortElements: true},
+		a:         `<root><a><x n="1"/><x n="2"/></a><b/></root>`,
+		b:         `<root><b/><a><x n="2"/><x n="1"/></a></root>`,
+		wantEqual: true,
+	}, {
+		desc: "non-numeric values compare as text",
+		n:    Normalizer{NumberTolerance: &Tolerance{Abs: 1}},
+		a:    `<x>NaN</x>`,
+		b:    `<x>nan</x>`,
+	}, {
+		desc:      "numeric attributes",
+		n:         Normalizer{NumericAttrs: []xml.Name{{Local: "points"}}},
+		a:         `<x points="1,2 3,4.0"/>`,
+		b:         `<x points="1 2, 3 4"/>`,
+		wantEqual: true,
+	}, {
+		desc: "numeric attributes with different numbers",
+		n:    Normalizer{NumericAttrs: []xml.Name{{Local: "points"}}},
+		a:    `<x points="1,2 3,4"/>`,
+		b:    `<x points="1,2 3"/>`,
 	}}
 
 	for _, tc := range testCases {
@@ -189,3 +510,229 @@ func TestEqualXML(t *testing.T) {
 		}
 	}
 }
+
+func TestEqualValueXML(t *testing.T) {
+	type item struct {
+		XMLName struct{} `xml:"item"`
+		ID      string   `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+	testCases := []struct {
+		desc      string
+		v         interface{}
+		want      string
+		wantEqual bool
+		wantErr   error
+	}{{
+		desc:      "equal",
+		v:         item{ID: "7", Name: "foo"},
+		want:      `<item id="7"><name>foo</name></item>`,
+		wantEqual: true,
+	}, {
+		desc: "different attribute",
+		v:    item{ID: "8", Name: "foo"},
+		want: `<item id="7"><name>foo</name></item>`,
+	}, {
+		desc:    "bad: value can't be marshaled",
+		v:       make(chan int),
+		want:    `<item/>`,
+		wantErr: errors.New("some error"),
+	}}
+
+	for _, tc := range testCases {
+		var n Normalizer
+		got, err := n.EqualValueXML(tc.v, strings.NewReader(tc.want))
+		if tc.wantErr != nil {
+			if err == nil {
+				t.Errorf("%s: got nil error, want %v", tc.desc, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got != tc.wantEqual {
+			t.Errorf("%s:\ngot  %v\nwant %v", tc.desc, got, tc.wantEqual)
+		}
+	}
+}
+
+func TestAllEqualXML(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		n         Normalizer
+		docs      []string
+		wantEqual bool
+	}{{
+		desc:      "no documents",
+		wantEqual: true,
+	}, {
+		desc:      "all equal",
+		docs:      []string{`<a x="1" y="2"/>`, `<a y="2" x="1"/>`, `<a y="2" x="1"></a>`},
+		wantEqual: true,
+	}, {
+		desc: "last differs",
+		docs: []string{`<a/>`, `<a/>`, `<b/>`},
+	}, {
+		desc: "tolerance is not transitive",
+		n:    Normalizer{NumberTolerance: &Tolerance{Abs: 1}},
+		docs: []string{`<a>1</a>`, `<a>2</a>`, `<a>3</a>`},
+	}}
+
+	for _, tc := range testCases {
+		var rs []io.Reader
+		for _, d := range tc.docs {
+			rs = append(rs, strings.NewReader(d))
+		}
+		got, err := tc.n.AllEqualXML(rs...)
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got != tc.wantEqual {
+			t.Errorf("%s: got %v, want %v", tc.desc, got, tc.wantEqual)
+		}
+	}
+}
+
+func TestAllEqualXMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i, d := range []string{`<a x="1" y="2"/>`, `<a y="2" x="1"/>`} {
+		name := filepath.Join(dir, fmt.Sprintf("%d.xml", i))
+		if err := os.WriteFile(name, []byte(d), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	var n Normalizer
+	if got, err := n.AllEqualXMLFiles(names...); err != nil || !got {
+		t.Errorf("got %v, %v, want true, nil", got, err)
+	}
+	if _, err := n.AllEqualXMLFiles(filepath.Join(dir, "missing.xml")); err == nil {
+		t.Errorf("missing file: got nil error, want some")
+	}
+}
+
+func TestOnToken(t *testing.T) {
+	var got []string
+	n := Normalizer{OnToken: func(depth int, t xml.Token) {
+		got = append(got, fmt.Sprintf("%d %s", depth, formatToken(t)))
+	}}
+	if err := n.Normalize(io.Discard, strings.NewReader(`<root><a>x</a><b/></root>`)); err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	want := []string{
+		"0 <root>",
+		"1 <a>",
+		`2 text "x"`,
+		"1 </a>",
+		"1 <b>",
+		"1 </b>",
+		"0 </root>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %q\nwant %q", got, want)
+	}
+}
+
+type sliceTokenReader []xml.Token
+
+func (r *sliceTokenReader) Token() (xml.Token, error) {
+	if len(*r) == 0 {
+		return nil, io.EOF
+	}
+	t := (*r)[0]
+	*r = (*r)[1:]
+	return t, nil
+}
+
+func TestNormalizeTokens(t *testing.T) {
+	root := xml.Name{Local: "root"}
+	testCases := []struct {
+		desc    string
+		n       Normalizer
+		in      []xml.Token
+		wantXML string
+		wantErr error
+	}{{
+		desc: "synthesized tokens",
+		in: []xml.Token{
+			xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0"`)},
+			xml.StartElement{Name: root, Attr: []xml.Attr{
+				{Name: xml.Name{Local: "b"}, Value: "2"},
+				{Name: xml.Name{Local: "a"}, Value: "1"},
+			}},
+			xml.Comment(" a comment "),
+			xml.CharData("  "),
+			xml.EndElement{Name: root},
+		},
+		n:       Normalizer{OmitComments: true, OmitWhitespace: true},
+		wantXML: `<root a="1" b="2"></root>`,
+	}, {
+		desc: "bad: unbalanced end element",
+		in: []xml.Token{
+			xml.StartElement{Name: root},
+			xml.EndElement{Name: xml.Name{Local: "foo"}},
+		},
+		wantErr: errors.New("some error"),
+	}}
+
+	for _, tc := range testCases {
+		var b bytes.Buffer
+		tr := sliceTokenReader(tc.in)
+		err := tc.n.NormalizeTokens(&b, &tr)
+		if tc.wantErr != nil {
+			if err == nil {
+				t.Errorf("%s: got nil error, want %v", tc.desc, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err %v, want nil", tc.desc, err)
+			continue
+		}
+		if got, want := b.String(), tc.wantXML; got != want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tc.desc, got, tc.wantXML)
+		}
+	}
+}
+
+// idFirst orders attributes named id first.
+func idFirst(a, b xml.Attr) bool { return a.Name.Local == "id" && b.Name.Local != "id" }
+
+// upperDecoder reads tokens with encoding/xml and uppercases character
+// data, standing in for an alternative parser.
+type upperDecoder struct {
+	d *xml.Decoder
+}
+
+func (u upperDecoder) Token() (xml.Token, error) {
+	t, err := u.d.RawToken()
+	if cd, ok := t.(xml.CharData); ok {
+		t = xml.CharData(bytes.ToUpper(cd))
+	}
+	return t, err
+}
+
+func TestDecoder(t *testing.T) {
+	n := New(WithDecoder(func(r io.Reader) xml.TokenReader {
+		return upperDecoder{xml.NewDecoder(r)}
+	}))
+	var b bytes.Buffer
+	if err := n.Normalize(&b, strings.NewReader(`<p:a xmlns:p="urn:p">x</p:a>`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), `<_:a xmlns:_="urn:p">X</_:a>`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	equal, err := n.EqualXML(strings.NewReader(`<a xmlns="urn:p">x</a>`), strings.NewReader(`<q:a xmlns:q="urn:p">X</q:a>`))
+	if err != nil || !equal {
+		t.Errorf("EqualXML: got %v, %v, want true, nil", equal, err)
+	}
+	var serr *SyntaxError
+	if err := n.Normalize(io.Discard, strings.NewReader(`<a></b>`)); !errors.As(err, &serr) {
+		t.Errorf("got error %v, want *SyntaxError", err)
+	}
+}
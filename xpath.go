@@ -0,0 +1,252 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// xpathTarget is a node selected by an XPath expression. If attr is not
+// negative, the target is the attribute of the element parent.children[i]
+// at index attr. Otherwise it is the node parent.children[i].
+type xpathTarget struct {
+	parent *node
+	i      int
+	attr   int
+}
+
+func (t xpathTarget) node() *node {
+	return t.parent.children[t.i]
+}
+
+// selectXPath evaluates the absolute XPath location path sel on the
+// document with the top-level nodes doc.children, resolving prefixes with
+// ns. It supports the subset of XPath used by RFC 5261 patches: child steps
+// with name tests, *, text() and comment(), position predicates and
+// attribute value predicates, and a final attribute step. The expression
+// must select exactly one node.
+func selectXPath(doc *node, sel string, ns map[string]string) (xpathTarget, error) {
+	if !strings.HasPrefix(sel, "/") || strings.HasPrefix(sel, "//") {
+		return xpathTarget{}, fmt.Errorf("xmltest: unsupported XPath selector %q", sel)
+	}
+	steps, err := splitXPath(sel[1:])
+	if err != nil {
+		return xpathTarget{}, err
+	}
+	var (
+		parent = doc
+		target = xpathTarget{attr: -1}
+	)
+	for i, step := range steps {
+		if parent == nil {
+			return xpathTarget{}, fmt.Errorf("xmltest: XPath selector %q steps into a non-element node", sel)
+		}
+		test, preds := splitPredicates(step)
+		if strings.HasPrefix(test, "@") {
+			if i != len(steps)-1 || len(preds) > 0 || target.parent == nil {
+				return xpathTarget{}, fmt.Errorf("xmltest: unsupported XPath selector %q", sel)
+			}
+			name, err := resolveQName(test[1:], ns, false)
+			if err != nil {
+				return xpathTarget{}, err
+			}
+			start := parent.tok.(xml.StartElement)
+			for j, a := range start.Attr {
+				if a.Name == name {
+					return xpathTarget{parent: target.parent, i: target.i, attr: j}, nil
+				}
+			}
+			return xpathTarget{}, fmt.Errorf("xmltest: XPath selector %q matches no attribute", sel)
+		}
+		var matches []int
+		for j, c := range parent.children {
+			ok, err := matchNodeTest(c, test, ns)
+			if err != nil {
+				return xpathTarget{}, err
+			}
+			if ok {
+				matches = append(matches, j)
+			}
+		}
+		for _, p := range preds {
+			if matches, err = applyPredicate(parent, matches, p, ns); err != nil {
+				return xpathTarget{}, err
+			}
+		}
+		if len(matches) != 1 {
+			return xpathTarget{}, fmt.Errorf("xmltest: XPath selector %q matches %d nodes at step %q, want 1", sel, len(matches), step)
+		}
+		target = xpathTarget{parent: parent, i: matches[0], attr: -1}
+		parent = nil
+		if c := target.node(); c.isElement() {
+			parent = c
+		}
+	}
+	if target.parent == nil {
+		return xpathTarget{}, fmt.Errorf("xmltest: XPath selector %q selects no node", sel)
+	}
+	return target, nil
+}
+
+// splitXPath splits a relative location path into its steps.
+func splitXPath(path string) ([]string, error) {
+	var (
+		steps []string
+		depth int
+		quote rune
+		start int
+	)
+	for i, r := range path {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == '/' && depth == 0:
+			steps = append(steps, path[start:i])
+			start = i + 1
+		}
+	}
+	steps = append(steps, path[start:])
+	for _, s := range steps {
+		if s == "" {
+			return nil, fmt.Errorf("xmltest: unsupported XPath selector %q", "/"+path)
+		}
+	}
+	return steps, nil
+}
+
+// splitPredicates splits step into its node test and predicates.
+func splitPredicates(step string) (test string, preds []string) {
+	i := strings.IndexByte(step, '[')
+	if i < 0 {
+		return step, nil
+	}
+	test, rest := step[:i], step[i:]
+	for len(rest) > 0 && rest[0] == '[' {
+		var quote byte
+		j := 1
+		for ; j < len(rest); j++ {
+			c := rest[j]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if c == '\'' || c == '"' {
+				quote = c
+			} else if c == ']' {
+				break
+			}
+		}
+		preds = append(preds, strings.TrimSpace(rest[1:j]))
+		if j < len(rest) {
+			j++
+		}
+		rest = rest[j:]
+	}
+	return test, preds
+}
+
+// matchNodeTest reports whether nd matches the XPath node test.
+func matchNodeTest(nd *node, test string, ns map[string]string) (bool, error) {
+	switch test {
+	case "node()":
+		return true, nil
+	case "text()":
+		_, ok := nd.tok.(xml.CharData)
+		return ok, nil
+	case "comment()":
+		_, ok := nd.tok.(xml.Comment)
+		return ok, nil
+	}
+	if !nd.isElement() {
+		return false, nil
+	}
+	if test == "*" {
+		return true, nil
+	}
+	if strings.HasSuffix(test, ":*") {
+		uri, ok := ns[strings.TrimSuffix(test, ":*")]
+		if !ok {
+			return false, fmt.Errorf("xmltest: undeclared prefix in XPath node test %q", test)
+		}
+		return nd.name().Space == uri, nil
+	}
+	name, err := resolveQName(test, ns, false)
+	if err != nil {
+		return false, err
+	}
+	return nd.name() == name, nil
+}
+
+// applyPredicate filters the children of parent at the indexes matches
+// with the XPath predicate p.
+func applyPredicate(parent *node, matches []int, p string, ns map[string]string) ([]int, error) {
+	if pos, err := strconv.Atoi(p); err == nil {
+		if pos < 1 || pos > len(matches) {
+			return nil, nil
+		}
+		return matches[pos-1 : pos], nil
+	}
+	eq := strings.IndexByte(p, '=')
+	if !strings.HasPrefix(p, "@") || eq < 0 {
+		return nil, fmt.Errorf("xmltest: unsupported XPath predicate [%s]", p)
+	}
+	name, err := resolveQName(strings.TrimSpace(p[1:eq]), ns, false)
+	if err != nil {
+		return nil, err
+	}
+	lit := strings.TrimSpace(p[eq+1:])
+	if len(lit) < 2 || lit[0] != lit[len(lit)-1] || (lit[0] != '\'' && lit[0] != '"') {
+		return nil, fmt.Errorf("xmltest: unsupported XPath predicate [%s]", p)
+	}
+	value := lit[1 : len(lit)-1]
+	var filtered []int
+	for _, i := range matches {
+		start, ok := parent.children[i].tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, a := range start.Attr {
+			if a.Name == name && a.Value == value {
+				filtered = append(filtered, i)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// resolveQName resolves the qualified name qn with the namespace bindings
+// ns. Unprefixed names are in the default namespace if useDefault is true,
+// and in no namespace otherwise.
+func resolveQName(qn string, ns map[string]string, useDefault bool) (xml.Name, error) {
+	prefix, local := "", qn
+	if i := strings.IndexByte(qn, ':'); i >= 0 {
+		prefix, local = qn[:i], qn[i+1:]
+	}
+	switch {
+	case prefix == "xml":
+		return xml.Name{Space: xmlURL, Local: local}, nil
+	case prefix == "" && !useDefault:
+		return xml.Name{Local: local}, nil
+	}
+	uri, ok := ns[prefix]
+	if !ok && prefix != "" {
+		return xml.Name{}, fmt.Errorf("xmltest: undeclared namespace prefix %q", prefix)
+	}
+	return xml.Name{Space: uri, Local: local}, nil
+}
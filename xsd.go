@@ -0,0 +1,328 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+const xsdURL = "http://www.w3.org/2001/XMLSchema"
+
+// xsdNode is an element of an XML Schema document.
+type xsdNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []*xsdNode `xml:",any"`
+}
+
+// attr returns the value of the unqualified attribute local of nd.
+func (nd *xsdNode) attr(local string) string {
+	v, _ := nd.lookupAttr(local)
+	return v
+}
+
+// lookupAttr is like attr, but also reports whether nd has the attribute.
+func (nd *xsdNode) lookupAttr(local string) (string, bool) {
+	for _, a := range nd.Attrs {
+		if a.Name == (xml.Name{Local: local}) {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParseSchema reads the XML Schema document r. Elements with element-only
+// content have whitespace between their children removed, attributes with
+// default or fixed values are added where missing, and values of types
+// other than string have their whitespace collapsed or replaced as the
+// types declare. Included and imported schema documents are not read, and
+// type, element, group and attribute references match by local name.
+func ParseSchema(r io.Reader) (*Schema, error) {
+	var root xsdNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	if root.XMLName != (xml.Name{Space: xsdURL, Local: "schema"}) {
+		return nil, errors.New("xmltest: not an XML Schema document")
+	}
+	p := &xsdParser{
+		globals:  map[[2]string]*xsdNode{},
+		elements: map[*xsdNode]*elementDecl{},
+		types:    map[*xsdNode]*contentType{},
+		spaces:   map[*xsdNode]whiteSpace{},
+	}
+	for _, c := range root.Children {
+		if name := c.attr("name"); name != "" && c.XMLName.Space == xsdURL {
+			p.globals[[2]string{c.XMLName.Local, name}] = c
+		}
+	}
+	s := &Schema{elements: map[string]*elementDecl{}}
+	for _, c := range root.Children {
+		if c.XMLName == (xml.Name{Space: xsdURL, Local: "element"}) {
+			s.elements[c.attr("name")] = p.element(c)
+		}
+	}
+	return s, nil
+}
+
+// xsdParser resolves the declarations of an XML Schema. Resolved
+// declarations are recorded before their content, so that recursive
+// definitions terminate.
+type xsdParser struct {
+	// globals holds the top-level components by kind and name.
+	globals  map[[2]string]*xsdNode
+	elements map[*xsdNode]*elementDecl
+	types    map[*xsdNode]*contentType
+	spaces   map[*xsdNode]whiteSpace
+}
+
+// global returns the top-level component of the kind with the name ref.
+func (p *xsdParser) global(kind, ref string) *xsdNode {
+	return p.globals[[2]string{kind, localName(ref)}]
+}
+
+// element returns the declaration of the element nd.
+func (p *xsdParser) element(nd *xsdNode) *elementDecl {
+	if ref := nd.attr("ref"); ref != "" {
+		if g := p.global("element", ref); g != nil {
+			return p.element(g)
+		}
+		return &elementDecl{}
+	}
+	if d, ok := p.elements[nd]; ok {
+		return d
+	}
+	d := &elementDecl{}
+	p.elements[nd] = d
+	if typ := nd.attr("type"); typ != "" {
+		d.typ = p.namedType(typ)
+		return d
+	}
+	for _, c := range p.xsdChildren(nd) {
+		switch c.XMLName.Local {
+		case "complexType":
+			d.typ = p.complexType(c)
+		case "simpleType":
+			d.typ = &contentType{space: p.simpleSpace(c)}
+		}
+	}
+	return d
+}
+
+// namedType returns the type named name, or nil for xs:anyType.
+func (p *xsdParser) namedType(name string) *contentType {
+	if g := p.global("complexType", name); g != nil {
+		return p.complexType(g)
+	}
+	if localName(name) == "anyType" {
+		return nil
+	}
+	return &contentType{space: p.typeSpace(name)}
+}
+
+// complexType returns the type defined by the complexType nd.
+func (p *xsdParser) complexType(nd *xsdNode) *contentType {
+	if ct, ok := p.types[nd]; ok {
+		return ct
+	}
+	ct := &contentType{
+		elementOnly: nd.attr("mixed") != "true",
+		attrs:       map[string]attrDecl{},
+		children:    map[string]*elementDecl{},
+	}
+	p.types[nd] = ct
+	p.content(ct, nd)
+	return ct
+}
+
+// content adds the particles and attributes of the content of nd to ct.
+func (p *xsdParser) content(ct *contentType, nd *xsdNode) {
+	for _, c := range p.xsdChildren(nd) {
+		switch c.XMLName.Local {
+		case "sequence", "choice", "all", "group":
+			p.particles(ct, c)
+		case "attribute":
+			p.attribute(ct, c)
+		case "attributeGroup":
+			p.attributeGroup(ct, c)
+		case "simpleContent":
+			ct.elementOnly = false
+			for _, d := range p.xsdChildren(c) {
+				if base := d.attr("base"); base != "" {
+					if g := p.global("complexType", base); g != nil {
+						bt := p.complexType(g)
+						ct.space = bt.space
+						p.inherit(ct, bt)
+					} else {
+						ct.space = p.typeSpace(base)
+					}
+				}
+				if ws, ok := p.facet(d); ok {
+					ct.space = ws
+				}
+				p.content(ct, d)
+			}
+		case "complexContent":
+			if c.attr("mixed") == "true" {
+				ct.elementOnly = false
+			}
+			for _, d := range p.xsdChildren(c) {
+				if g := p.global("complexType", d.attr("base")); g != nil && d.XMLName.Local == "extension" {
+					base := p.complexType(g)
+					if !base.elementOnly {
+						ct.elementOnly = false
+					}
+					p.inherit(ct, base)
+				}
+				p.content(ct, d)
+			}
+		}
+	}
+}
+
+// inherit adds the attributes and child elements of base to ct.
+func (p *xsdParser) inherit(ct, base *contentType) {
+	for name, a := range base.attrs {
+		ct.attrs[name] = a
+	}
+	for name, d := range base.children {
+		ct.children[name] = d
+	}
+}
+
+// particles adds the elements declared by the model group nd to ct.
+func (p *xsdParser) particles(ct *contentType, nd *xsdNode) {
+	if ref := nd.attr("ref"); ref != "" && nd.XMLName.Local == "group" {
+		if g := p.global("group", ref); g != nil && g != nd {
+			p.particles(ct, g)
+		}
+		return
+	}
+	for _, c := range p.xsdChildren(nd) {
+		switch c.XMLName.Local {
+		case "element":
+			name := c.attr("name")
+			if name == "" {
+				name = localName(c.attr("ref"))
+			}
+			ct.children[name] = p.element(c)
+		case "sequence", "choice", "all", "group":
+			p.particles(ct, c)
+		}
+	}
+}
+
+// attribute adds the attribute declared by nd to ct.
+func (p *xsdParser) attribute(ct *contentType, nd *xsdNode) {
+	decl := nd
+	if ref := nd.attr("ref"); ref != "" {
+		if decl = p.global("attribute", ref); decl == nil {
+			return
+		}
+	}
+	var a attrDecl
+	if typ := decl.attr("type"); typ != "" {
+		a.space = p.typeSpace(typ)
+	}
+	for _, c := range p.xsdChildren(decl) {
+		if c.XMLName.Local == "simpleType" {
+			a.space = p.simpleSpace(c)
+		}
+	}
+	// A reference may override the default of the global declaration.
+	for _, n := range []*xsdNode{nd, decl} {
+		for _, key := range []string{"default", "fixed"} {
+			if v, ok := n.lookupAttr(key); ok && !a.hasDefault {
+				a.def, a.hasDefault = v, true
+			}
+		}
+	}
+	ct.attrs[decl.attr("name")] = a
+}
+
+// attributeGroup adds the attributes of the attribute group nd to ct.
+func (p *xsdParser) attributeGroup(ct *contentType, nd *xsdNode) {
+	if ref := nd.attr("ref"); ref != "" {
+		if g := p.global("attributeGroup", ref); g != nil && g != nd {
+			p.attributeGroup(ct, g)
+		}
+		return
+	}
+	p.content(ct, nd)
+}
+
+// typeSpace returns the whitespace handling of the simple type name.
+func (p *xsdParser) typeSpace(name string) whiteSpace {
+	if g := p.global("simpleType", name); g != nil {
+		return p.simpleSpace(g)
+	}
+	switch localName(name) {
+	case "string", "anySimpleType", "anyType":
+		return spacePreserve
+	case "normalizedString":
+		return spaceReplace
+	}
+	return spaceCollapse
+}
+
+// simpleSpace returns the whitespace handling of the simpleType nd.
+func (p *xsdParser) simpleSpace(nd *xsdNode) whiteSpace {
+	if ws, ok := p.spaces[nd]; ok {
+		return ws
+	}
+	p.spaces[nd] = spacePreserve
+	ws := spacePreserve
+	for _, c := range p.xsdChildren(nd) {
+		switch c.XMLName.Local {
+		case "list", "union":
+			ws = spaceCollapse
+		case "restriction":
+			if base := c.attr("base"); base != "" {
+				ws = p.typeSpace(base)
+			}
+			for _, d := range p.xsdChildren(c) {
+				if d.XMLName.Local == "simpleType" {
+					ws = p.simpleSpace(d)
+				}
+			}
+			if f, ok := p.facet(c); ok {
+				ws = f
+			}
+		}
+	}
+	p.spaces[nd] = ws
+	return ws
+}
+
+// facet returns the whiteSpace facet of the restriction nd, if any.
+func (p *xsdParser) facet(nd *xsdNode) (whiteSpace, bool) {
+	for _, c := range p.xsdChildren(nd) {
+		if c.XMLName.Local == "whiteSpace" {
+			switch c.attr("value") {
+			case "preserve":
+				return spacePreserve, true
+			case "replace":
+				return spaceReplace, true
+			case "collapse":
+				return spaceCollapse, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// xsdChildren returns the children of nd in the XML Schema namespace,
+// skipping annotations.
+func (p *xsdParser) xsdChildren(nd *xsdNode) []*xsdNode {
+	var cs []*xsdNode
+	for _, c := range nd.Children {
+		if c.XMLName.Space == xsdURL && c.XMLName.Local != "annotation" {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
@@ -0,0 +1,101 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+const testXSD = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:annotation><xs:documentation>Orders</xs:documentation></xs:annotation>
+  <xs:element name="order" type="Order"/>
+  <xs:element name="note" type="xs:string"/>
+  <xs:complexType name="Base">
+    <xs:sequence><xs:element ref="note" minOccurs="0"/></xs:sequence>
+    <xs:attribute name="version" type="xs:token" default="1"/>
+  </xs:complexType>
+  <xs:complexType name="Order">
+    <xs:complexContent>
+      <xs:extension base="Base">
+        <xs:sequence>
+          <xs:element name="id" type="Code"/>
+          <xs:element name="para" type="Para"/>
+          <xs:element name="price">
+            <xs:complexType>
+              <xs:simpleContent>
+                <xs:extension base="xs:decimal">
+                  <xs:attributeGroup ref="money"/>
+                </xs:extension>
+              </xs:simpleContent>
+            </xs:complexType>
+          </xs:element>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="Para" mixed="true">
+    <xs:sequence><xs:element name="b" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:whiteSpace value="collapse"/></xs:restriction>
+  </xs:simpleType>
+  <xs:attributeGroup name="money">
+    <xs:attribute name="currency" type="xs:normalizedString" fixed="EUR"/>
+    <xs:attribute name="label" type="xs:string"/>
+  </xs:attributeGroup>
+</xs:schema>`
+
+func TestSchema(t *testing.T) {
+	s, err := ParseSchema(strings.NewReader(testXSD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "defaults are added",
+		in:   `<order><price>1</price></order>`,
+		want: `<order version="1"><price currency="EUR">1</price></order>`,
+	}, {
+		desc: "whitespace in element-only content is removed",
+		in:   "<order version=\" 2 \">\n  <note> a  b </note>\n  <id>\n x  y </id>\n</order>",
+		want: `<order version="2"><note> a  b </note><id>x y</id></order>`,
+	}, {
+		desc: "whitespace in mixed content is kept",
+		in:   "<order><para> <b> x </b> y</para></order>",
+		want: `<order version="1"><para> <b> x </b> y</para></order>`,
+	}, {
+		desc: "attribute types apply",
+		in:   "<order><price label=\" a\tb \" currency=\"E\tUR\"> 3.0\n</price></order>",
+		want: `<order version="1"><price currency="E UR" label=" a&#x9;b ">3.0</price></order>`,
+	}, {
+		desc: "undeclared elements are kept as is",
+		in:   "<other> <x a=\" b \"/> </other>",
+		want: `<other> <x a=" b "></x> </other>`,
+	}}
+
+	n := New(WithSchema(s))
+	for _, tc := range testCases {
+		var b strings.Builder
+		if err := n.Normalize(&b, strings.NewReader(tc.in)); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	for _, in := range []string{`<xs:schema`, `<schema/>`} {
+		if _, err := ParseSchema(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: got nil error", in)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FromYAML reads a tree description in a subset of YAML from r and writes
+// the corresponding XML document to w, as normalized by n. The description
+// is a mapping with a single key, the name of the root element. The value
+// of an element is either a scalar, its character data, or a mapping with
+// the keys
+//
+//   - "@name" for attributes, whose values are scalars,
+//   - "#text" and "#comment" for character data and a comment, and
+//   - element names for child elements, in order, whose value is the
+//     value of the element, or a sequence of values for repeated elements.
+//
+// Names are in Clark notation. The subset comprises block mappings and
+// sequences, plain scalars on a single line, and single- and double-quoted
+// scalars; an empty value or ~ is an empty scalar. For example
+//
+//	feed:
+//	  "@version": "2"
+//	  title: News
+//	  entry:
+//	    - "@id": "1"
+//	      "#text": First
+//	    - "@id": "2"
+//
+// describes <feed version="2"><title>News</title><entry id="1">First</entry><entry id="2"></entry></feed>.
+func (n *Normalizer) FromYAML(r io.Reader, w io.Writer) error {
+	doc, err := parseYAML(r)
+	if err != nil {
+		return err
+	}
+	if doc.kind != yamlMapping || len(doc.keys) != 1 {
+		return errors.New("xmltest: yaml: document must be a mapping with a single key")
+	}
+	toks, err := appendYAMLElement(nil, doc.keys[0], doc.values[0])
+	if err != nil {
+		return err
+	}
+	return n.NormalizeTokens(w, Replay(toks))
+}
+
+// ToYAML writes the normalized XML content of r to w as a tree description
+// that FromYAML reads. Character data that only contains whitespace is
+// omitted from elements with child elements. It fails for documents that
+// the description cannot represent, that is with several roots, several
+// runs of character data or comments in an element, or repeated child
+// elements that are not adjacent.
+func (n *Normalizer) ToYAML(r io.Reader, w io.Writer) error {
+	toks, err := n.readTokens(r)
+	if err != nil {
+		return err
+	}
+	var roots []*node
+	for _, nd := range buildTree(toks) {
+		if nd.isElement() {
+			roots = append(roots, nd)
+		}
+	}
+	if len(roots) != 1 {
+		return fmt.Errorf("xmltest: yaml: got %d root elements, want 1", len(roots))
+	}
+	bw := bufio.NewWriter(w)
+	start := roots[0].tok.(xml.StartElement)
+	bw.WriteString(yamlQuote(clarkName(start.Name)) + ":")
+	if err := writeYAMLValue(bw, roots[0], 2); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// yamlKind is the kind of a yamlNode.
+type yamlKind int
+
+const (
+	yamlScalar yamlKind = iota
+	yamlMapping
+	yamlSequence
+)
+
+// yamlNode is a node of a YAML document.
+type yamlNode struct {
+	kind   yamlKind
+	scalar string
+	// keys and values are the entries of a mapping, in order.
+	keys   []string
+	values []*yamlNode
+	items  []*yamlNode
+}
+
+// yamlLine is a line of a YAML document without its indentation.
+type yamlLine struct {
+	num, indent int
+	text        string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// parseYAML parses the YAML document r.
+func parseYAML(r io.Reader) (*yamlNode, error) {
+	var p yamlParser
+	s := bufio.NewScanner(r)
+	for num := 1; s.Scan(); num++ {
+		line := strings.TrimRight(s.Text(), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text[0] == '#' || num == 1 && text == "---" {
+			continue
+		}
+		if text[0] == '\t' {
+			return nil, yamlError(num, "tab in indentation")
+		}
+		p.lines = append(p.lines, yamlLine{num, len(line) - len(text), text})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(p.lines) == 0 {
+		return nil, errors.New("xmltest: yaml: empty document")
+	}
+	doc, err := p.node()
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		return nil, yamlError(p.lines[p.i].num, "unexpected indentation")
+	}
+	return doc, nil
+}
+
+func yamlError(num int, msg string) error {
+	return fmt.Errorf("xmltest: yaml: line %d: %s", num, msg)
+}
+
+// node parses the node that starts at the current line.
+func (p *yamlParser) node() (*yamlNode, error) {
+	l := p.lines[p.i]
+	if isYAMLItem(l.text) {
+		return p.sequence(l.indent)
+	}
+	if _, _, ok, err := splitYAMLKey(l.text); err != nil {
+		return nil, yamlError(l.num, err.Error())
+	} else if ok {
+		return p.mapping(l.indent)
+	}
+	p.i++
+	s, err := parseYAMLScalar(l.text)
+	if err != nil {
+		return nil, yamlError(l.num, err.Error())
+	}
+	return &yamlNode{scalar: s}, nil
+}
+
+// child parses the node that starts at the current line if it is indented
+// deeper than indent, and returns an empty scalar otherwise.
+func (p *yamlParser) child(indent int) (*yamlNode, error) {
+	if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+		return p.node()
+	}
+	return &yamlNode{}, nil
+}
+
+func (p *yamlParser) sequence(indent int) (*yamlNode, error) {
+	nd := &yamlNode{kind: yamlSequence}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLItem(p.lines[p.i].text) {
+		l := &p.lines[p.i]
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var (
+			item *yamlNode
+			err  error
+		)
+		if rest == "" {
+			p.i++
+			item, err = p.child(indent)
+		} else {
+			// Parse the rest of the line as if it started a line.
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			item, err = p.node()
+		}
+		if err != nil {
+			return nil, err
+		}
+		nd.items = append(nd.items, item)
+	}
+	return nd, nil
+}
+
+func (p *yamlParser) mapping(indent int) (*yamlNode, error) {
+	nd := &yamlNode{kind: yamlMapping}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent {
+		l := p.lines[p.i]
+		key, rest, ok, err := splitYAMLKey(l.text)
+		if err != nil {
+			return nil, yamlError(l.num, err.Error())
+		}
+		if !ok {
+			return nil, yamlError(l.num, "expected mapping key")
+		}
+		for _, k := range nd.keys {
+			if k == key {
+				return nil, yamlError(l.num, "repeated key "+key)
+			}
+		}
+		p.i++
+		var v *yamlNode
+		switch {
+		case rest != "":
+			s, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, yamlError(l.num, err.Error())
+			}
+			v = &yamlNode{scalar: s}
+		case p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLItem(p.lines[p.i].text):
+			v, err = p.sequence(indent)
+		default:
+			v, err = p.child(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		nd.keys = append(nd.keys, key)
+		nd.values = append(nd.values, v)
+	}
+	return nd, nil
+}
+
+// isYAMLItem reports whether the line text starts a sequence item.
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits the line text into the key and the rest of a
+// mapping entry, and reports whether text is a mapping entry.
+func splitYAMLKey(text string) (key, rest string, ok bool, err error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedEnd(text)
+		if end < 0 {
+			return "", "", false, errors.New("unterminated quoted scalar")
+		}
+		after := strings.TrimLeft(text[end:], " ")
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false, nil
+		}
+		key, err = unquoteYAML(text[:end])
+		return key, strings.TrimSpace(after[1:]), err == nil, err
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false, nil
+		}
+		i = len(text) - 1
+	}
+	if j := strings.Index(text, " #"); j >= 0 && j < i {
+		return "", "", false, nil
+	}
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true, nil
+}
+
+// quotedEnd returns the index after the quoted scalar at the start of s,
+// or -1 if it is unterminated.
+func quotedEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func unquoteYAML(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	u, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted scalar %s", s)
+	}
+	return u, nil
+}
+
+// parseYAMLScalar parses the scalar s, which may be followed by a
+// comment.
+func parseYAMLScalar(s string) (string, error) {
+	if s[0] == '"' || s[0] == '\'' {
+		end := quotedEnd(s)
+		if end < 0 {
+			return "", errors.New("unterminated quoted scalar")
+		}
+		if rest := strings.TrimLeft(s[end:], " "); rest != "" && rest[0] != '#' {
+			return "", errors.New("text after quoted scalar")
+		}
+		return unquoteYAML(s[:end])
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	switch s {
+	case "~", "null":
+		return "", nil
+	}
+	if strings.ContainsAny(s[:1], "[]{}&*!|>%@`") {
+		return "", fmt.Errorf("unsupported scalar %s", s)
+	}
+	return s, nil
+}
+
+// appendYAMLElement appends the tokens of the element name with the value
+// v to toks.
+func appendYAMLElement(toks []xml.Token, name string, v *yamlNode) ([]xml.Token, error) {
+	start := xml.StartElement{Name: parseName(name)}
+	if start.Name.Local == "" {
+		return nil, errors.New("xmltest: yaml: empty element name")
+	}
+	switch v.kind {
+	case yamlScalar:
+		toks = append(toks, start)
+		if v.scalar != "" {
+			toks = append(toks, xml.CharData(v.scalar))
+		}
+		return append(toks, start.End()), nil
+	case yamlSequence:
+		return nil, fmt.Errorf("xmltest: yaml: %s: sequence is not an element value", name)
+	}
+	for i, k := range v.keys {
+		if strings.HasPrefix(k, "@") {
+			if v.values[i].kind != yamlScalar {
+				return nil, fmt.Errorf("xmltest: yaml: %s: attribute %s is not a scalar", name, k)
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: parseName(k[1:]), Value: v.values[i].scalar})
+		}
+	}
+	toks = append(toks, start)
+	for i, k := range v.keys {
+		val := v.values[i]
+		var err error
+		switch {
+		case strings.HasPrefix(k, "@"):
+		case k == "#text" || k == "#comment":
+			if val.kind != yamlScalar {
+				return nil, fmt.Errorf("xmltest: yaml: %s: %s is not a scalar", name, k)
+			}
+			if k == "#text" {
+				toks = append(toks, xml.CharData(val.scalar))
+			} else {
+				toks = append(toks, xml.Comment(val.scalar))
+			}
+		case val.kind == yamlSequence:
+			for _, item := range val.items {
+				if toks, err = appendYAMLElement(toks, k, item); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if toks, err = appendYAMLElement(toks, k, val); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return append(toks, start.End()), nil
+}
+
+// writeYAMLValue writes the value of the element nd, whose key has been
+// written, with entries indented by indent.
+func writeYAMLValue(w *bufio.Writer, nd *node, indent int) error {
+	start := nd.tok.(xml.StartElement)
+	var text strings.Builder
+	simple := len(start.Attr) == 0
+	for _, c := range nd.children {
+		switch t := c.tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		default:
+			simple = false
+		}
+	}
+	if simple {
+		if text.Len() > 0 {
+			w.WriteString(" " + yamlQuote(text.String()))
+		}
+		w.WriteByte('\n')
+		return nil
+	}
+	w.WriteByte('\n')
+	return writeYAMLEntries(w, nd, indent, strings.Repeat(" ", indent))
+}
+
+// writeYAMLEntries writes the mapping of the element nd. The first entry
+// is prefixed by first, the others by indent spaces.
+func writeYAMLEntries(w *bufio.Writer, nd *node, indent int, first string) error {
+	start := nd.tok.(xml.StartElement)
+	prefix := first
+	entry := func(key string) {
+		w.WriteString(prefix + yamlQuote(key) + ":")
+		prefix = strings.Repeat(" ", indent)
+	}
+	for _, a := range start.Attr {
+		entry("@" + clarkName(a.Name))
+		w.WriteString(" " + yamlQuote(a.Value) + "\n")
+	}
+	children := nd.children
+	if hasElementChild(nd) {
+		children = nil
+		for _, c := range nd.children {
+			if !isWhitespaceNode(c) {
+				children = append(children, c)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for i := 0; i < len(children); i++ {
+		c := children[i]
+		var key, value string
+		switch t := c.tok.(type) {
+		case xml.CharData:
+			key, value = "#text", string(t)
+		case xml.Comment:
+			key, value = "#comment", string(t)
+		case xml.StartElement:
+			key = clarkName(t.Name)
+		}
+		if seen[key] {
+			return fmt.Errorf("xmltest: yaml: cannot represent repeated %s in %s", key, clarkName(start.Name))
+		}
+		seen[key] = true
+		entry(key)
+		if !c.isElement() {
+			w.WriteString(" " + yamlQuote(value) + "\n")
+			continue
+		}
+		// Collect the run of elements with the same name.
+		j := i + 1
+		for j < len(children) && children[j].isElement() && clarkName(children[j].name()) == key {
+			j++
+		}
+		if j == i+1 {
+			if err := writeYAMLValue(w, c, indent+2); err != nil {
+				return err
+			}
+			continue
+		}
+		w.WriteByte('\n')
+		pad := strings.Repeat(" ", indent+2)
+		for _, item := range children[i:j] {
+			if err := writeYAMLItem(w, item, indent+2, pad); err != nil {
+				return err
+			}
+		}
+		i = j - 1
+	}
+	return nil
+}
+
+// writeYAMLItem writes the element nd as a sequence item at indent.
+func writeYAMLItem(w *bufio.Writer, nd *node, indent int, pad string) error {
+	start := nd.tok.(xml.StartElement)
+	simple := len(start.Attr) == 0
+	for _, c := range nd.children {
+		if _, ok := c.tok.(xml.CharData); !ok {
+			simple = false
+		}
+	}
+	if simple {
+		w.WriteString(pad + "-")
+		return writeYAMLValue(w, nd, indent+2)
+	}
+	return writeYAMLEntries(w, nd, indent+2, pad+"- ")
+}
+
+// yamlQuote returns s as a plain scalar if FromYAML reads it back
+// unchanged, and as a double-quoted scalar otherwise.
+func yamlQuote(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || s == "~" || s == "null" || strings.HasSuffix(s, ":") || strings.Contains(s, ": ") {
+		return strconv.Quote(s)
+	}
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '/' {
+			continue
+		}
+		if i > 0 && strings.ContainsRune(" -:,()+=", r) {
+			continue
+		}
+		return strconv.Quote(s)
+	}
+	return s
+}
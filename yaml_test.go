@@ -0,0 +1,163 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromYAML(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "scalar root",
+		in:   "root: x\n",
+		want: `<root>x</root>`,
+	}, {
+		desc: "empty root",
+		in:   "---\nroot:\n",
+		want: `<root></root>`,
+	}, {
+		desc: "example",
+		in: `# A feed.
+feed:
+  "@version": "2"
+  title: News  # the title
+  entry:
+    - "@id": "1"
+      "#text": First
+    - "@id": '2'
+`,
+		want: `<feed version="2"><title>News</title><entry id="1">First</entry><entry id="2"></entry></feed>`,
+	}, {
+		desc: "sequence at key indentation",
+		in:   "a:\n  b:\n  - x\n  -\n  - ~\n  c: y\n",
+		want: `<a><b>x</b><b></b><b></b><c>y</c></a>`,
+	}, {
+		desc: "nested items",
+		in:   "a:\n  b:\n    - c:\n        d: x\n      e: y\n    - z\n",
+		want: `<a><b><c><d>x</d></c><e>y</e></b><b>z</b></a>`,
+	}, {
+		desc: "namespaces, text and comments",
+		in:   "\"{urn:x}a\":\n  \"@{urn:y}b\": \"1\"\n  \"#comment\": \" c \"\n  \"#text\": \"x\\n<y>\"\n",
+		want: "<a xmlns=\"urn:x\" xmlns:y=\"urn:y\" y:b=\"1\"><!-- c -->x\n&lt;y&gt;</a>",
+	}}
+	for _, tc := range testCases {
+		var got strings.Builder
+		if err := new(Normalizer).FromYAML(strings.NewReader(tc.in), &got); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		if ok, err := EqualXML(strings.NewReader(got.String()), strings.NewReader(tc.want)); !ok || err != nil {
+			t.Errorf("%s: got %s, want %s (%v)", tc.desc, got.String(), tc.want, err)
+		}
+	}
+}
+
+func TestFromYAMLError(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+	}{
+		{"empty", "# nothing\n"},
+		{"scalar document", "x\n"},
+		{"two roots", "a: x\nb: y\n"},
+		{"repeated key", "a:\n  b: x\n  b: y\n"},
+		{"bad indentation", "a:\n    b: x\n  c: y\n"},
+		{"tab", "a:\n\tb: x\n"},
+		{"unterminated quote", "a: \"x\n"},
+		{"flow sequence", "a: [x, y]\n"},
+		{"attribute mapping", "a:\n  \"@b\":\n    c: x\n"},
+		{"sequence root", "a:\n- x\n- y\n"},
+	}
+	for _, tc := range testCases {
+		if err := new(Normalizer).FromYAML(strings.NewReader(tc.in), new(strings.Builder)); err == nil {
+			t.Errorf("%s: got nil error, want error", tc.desc)
+		}
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+		want string
+	}{{
+		desc: "scalar",
+		in:   `<root>x</root>`,
+		want: "root: x\n",
+	}, {
+		desc: "empty",
+		in:   `<root/>`,
+		want: "root:\n",
+	}, {
+		desc: "feed",
+		n:    Normalizer{OmitWhitespace: true},
+		in: `<feed version="2">
+  <title>News: today</title>
+  <entry id="1">First</entry>
+  <entry id="2"/>
+  <entry><a>x</a></entry>
+</feed>`,
+		want: `feed:
+  "@version": 2
+  title: "News: today"
+  entry:
+    - "@id": 1
+      "#text": First
+    - "@id": 2
+    - a: x
+`,
+	}, {
+		desc: "namespaces and comments",
+		in:   `<a xmlns="urn:x"><!-- c --><b> </b></a>`,
+		want: "\"{urn:x}a\":\n  \"#comment\": \" c \"\n  \"{urn:x}b\": \" \"\n",
+	}, {
+		desc: "repeated scalars",
+		n:    Normalizer{OmitWhitespace: true},
+		in:   "<a>\n<b>1</b>\n<b/>\n</a>",
+		want: "a:\n  b:\n    - 1\n    -\n",
+	}}
+	for _, tc := range testCases {
+		var got strings.Builder
+		if err := tc.n.ToYAML(strings.NewReader(tc.in), &got); err != nil {
+			t.Errorf("%s: %v", tc.desc, err)
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tc.desc, got.String(), tc.want)
+		}
+		var back strings.Builder
+		if err := tc.n.FromYAML(strings.NewReader(got.String()), &back); err != nil {
+			t.Errorf("%s: FromYAML: %v", tc.desc, err)
+			continue
+		}
+		if ok, err := tc.n.EqualXML(strings.NewReader(back.String()), strings.NewReader(tc.in)); !ok || err != nil {
+			t.Errorf("%s: round trip: got %s, want %s (%v)", tc.desc, back.String(), tc.in, err)
+		}
+	}
+}
+
+func TestToYAMLError(t *testing.T) {
+	testCases := []struct {
+		desc string
+		n    Normalizer
+		in   string
+	}{
+		{desc: "several roots", n: Normalizer{AllowMultipleRoots: true}, in: `<a/><b/>`},
+		{desc: "interleaved elements", in: `<a><b/><c/><b/></a>`},
+		{desc: "several texts", in: `<a>x<b/>y</a>`},
+		{desc: "malformed", in: `<a>`},
+	}
+	for _, tc := range testCases {
+		if err := tc.n.ToYAML(strings.NewReader(tc.in), new(strings.Builder)); err == nil {
+			t.Errorf("%s: got nil error, want error", tc.desc)
+		}
+	}
+}